@@ -0,0 +1,176 @@
+package api
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type CertificateHandler struct {
+	DefaultPath string
+	Logger      *logrus.Logger
+}
+
+// certificateResponse hides the passphrase, which is only accepted on write.
+type certificateResponse struct {
+	models.Certificate
+	Passphrase    string `json:"passphrase,omitempty"`
+	HasPassphrase bool   `json:"hasPassphrase"`
+}
+
+func newCertificateResponse(certificate models.Certificate) certificateResponse {
+	return certificateResponse{Certificate: certificate, HasPassphrase: certificate.Passphrase != ""}
+}
+
+func (h *CertificateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getCertificates(w)
+	case http.MethodPost:
+		h.createCertificate(w, r)
+	case http.MethodPut:
+		h.updateCertificate(w, r)
+	case http.MethodDelete:
+		h.deleteCertificate(w, r)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+func (h *CertificateHandler) getCertificates(w http.ResponseWriter) {
+	certificates, err := models.ListCertificates(h.DefaultPath)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to read certificates", err, h.Logger)
+		return
+	}
+
+	responses := make([]certificateResponse, 0, len(certificates))
+	for _, certificate := range certificates {
+		responses = append(responses, newCertificateResponse(certificate))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(responses); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode certificates", err, h.Logger)
+	}
+}
+
+func (h *CertificateHandler) createCertificate(w http.ResponseWriter, r *http.Request) {
+	var certificate models.Certificate
+	if err := json.NewDecoder(r.Body).Decode(&certificate); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse certificate", err, h.Logger)
+		return
+	}
+
+	if !h.checkCertificate(w, &certificate) {
+		return
+	}
+
+	certificate.ID = uuid.New().String()
+	if err := certificate.Save(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save certificate", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(newCertificateResponse(certificate)); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode certificate", err, h.Logger)
+	}
+}
+
+func (h *CertificateHandler) updateCertificate(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Certificate ID is required", nil, h.Logger)
+		return
+	}
+
+	var updatedCertificate models.Certificate
+	if err := json.NewDecoder(r.Body).Decode(&updatedCertificate); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse updated certificate", err, h.Logger)
+		return
+	}
+
+	existingCertificate, err := models.LoadCertificate(h.DefaultPath, id)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Certificate not found", err, h.Logger)
+		return
+	}
+	// Clients never get the passphrase back, keep it unless the bundle changed or a new one is sent
+	if updatedCertificate.Passphrase == "" && updatedCertificate.PFXPath == existingCertificate.PFXPath {
+		updatedCertificate.Passphrase = existingCertificate.Passphrase
+	}
+
+	if !h.checkCertificate(w, &updatedCertificate) {
+		return
+	}
+
+	updatedCertificate.ID = id
+	if err := updatedCertificate.Save(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save updated certificate", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(newCertificateResponse(updatedCertificate)); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode certificate", err, h.Logger)
+	}
+}
+
+func (h *CertificateHandler) deleteCertificate(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Certificate ID is required", nil, h.Logger)
+		return
+	}
+
+	err := os.Remove(filepath.Join(h.DefaultPath, id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			apperrors.RespondWithError(w, http.StatusNotFound, "Certificate not found", err, h.Logger)
+		} else {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to delete certificate", err, h.Logger)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("Certificate deleted successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
+
+// checkCertificate validates the entry and makes sure the referenced files can actually be loaded.
+func (h *CertificateHandler) checkCertificate(w http.ResponseWriter, certificate *models.Certificate) bool {
+	if err := certificate.Validate(); err != nil {
+		var appErr apperrors.AppError
+		if errors.As(err, &appErr) {
+			apperrors.RespondWithError(w, appErr.Code, appErr.Message, appErr.Err, h.Logger)
+		} else {
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid certificate", err, h.Logger)
+		}
+		return false
+	}
+
+	var err error
+	if certificate.Kind == models.CertificateCA {
+		err = certificate.AppendTo(x509.NewCertPool())
+	} else {
+		_, err = certificate.LoadKeyPair()
+	}
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to load certificate files", err, h.Logger)
+		return false
+	}
+
+	return true
+}
@@ -2,10 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/FedeBP/pumoide/backend/apperrors"
 	"github.com/FedeBP/pumoide/backend/models"
@@ -16,15 +20,30 @@ import (
 
 const (
 	ActionExport           = "export"
+	ActionExportOpenAPI    = "exportOpenAPI"
+	ActionExportHAR        = "exportHAR"
+	ActionExportHTTP       = "exportHTTP"
 	ActionImport           = "import"
+	ActionImportOpenAPI    = "importOpenAPI"
+	ActionImportHAR        = "importHAR"
+	ActionImportHTTP       = "importHTTP"
 	ActionAddRequest       = "addRequest"
 	ActionUpdateCollection = "updateCollection"
 	ActionDeleteCollection = "deleteCollection"
 	ActionDeleteRequest    = "deleteRequest"
+	ActionAddFolder        = "addFolder"
+	ActionUpdateFolder     = "updateFolder"
+	ActionMoveItem         = "moveItem"
+	ActionReorderItems     = "reorderItems"
+	ActionDeleteFolder     = "deleteFolder"
 )
 
 type CollectionHandler struct {
 	DefaultPath string
+	// EnvironmentsPath is where imports save the environments holding the collection variables
+	EnvironmentsPath string
+	// HistoryPath is where exports look for the recorded responses of the requests
+	HistoryPath string
 	Logger      *logrus.Logger
 }
 
@@ -32,15 +51,46 @@ func (h *CollectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	action := r.URL.Query().Get("action")
 	switch r.Method {
 	case http.MethodGet:
-		if action == ActionExport {
+		switch action {
+		case ActionExport:
 			h.exportCollection(w, r)
-		} else {
+		case ActionExportOpenAPI:
+			h.exportOpenAPIDocument(w, r)
+		case ActionExportHAR:
+			h.exportHAR(w, r)
+		case ActionExportHTTP:
+			h.exportHTTPFile(w, r)
+		default:
 			h.getCollections(w, r)
 		}
 	case http.MethodPost:
-		if action == ActionImport {
-			h.importCollection(w, r)
-		} else {
+		switch action {
+		case ActionImport:
+			// The format is detected from the content unless given
+			format := models.ImportFormat(r.URL.Query().Get("format"))
+			if format != "" && !format.IsValid() {
+				apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid import format", nil, h.Logger)
+				return
+			}
+			h.importCollection(w, r, func(data []byte) (*models.ImportResult, error) {
+				return models.ImportCollection(data, format)
+			})
+		case ActionImportOpenAPI:
+			h.importCollection(w, r, models.ImportOpenAPIDocument)
+		case ActionImportHAR:
+			filter := models.HARFilter{
+				Domains:      listParameter(r, "domain"),
+				ContentTypes: listParameter(r, "contentType"),
+			}
+			h.importCollection(w, r, func(data []byte) (*models.ImportResult, error) {
+				return models.ImportHAR(data, filter)
+			})
+		case ActionImportHTTP:
+			name := r.URL.Query().Get("name")
+			h.importCollection(w, r, func(data []byte) (*models.ImportResult, error) {
+				return models.ImportHTTPFile(data, name)
+			})
+		default:
 			h.createCollection(w, r)
 		}
 	case http.MethodPut:
@@ -49,6 +99,14 @@ func (h *CollectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.addRequestToCollection(w, r)
 		case ActionUpdateCollection:
 			h.updateCollection(w, r)
+		case ActionAddFolder:
+			h.addFolder(w, r)
+		case ActionUpdateFolder:
+			h.updateFolder(w, r)
+		case ActionMoveItem:
+			h.moveItem(w, r)
+		case ActionReorderItems:
+			h.reorderItems(w, r)
 		default:
 			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid action", nil, h.Logger)
 		}
@@ -58,6 +116,9 @@ func (h *CollectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			h.deleteCollection(w, r)
 		case ActionDeleteRequest:
 			h.deleteRequestFromCollection(w, r)
+		case ActionDeleteFolder:
+			h.deleteFolder(w, r)
+		default:
 			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid action", nil, h.Logger)
 		}
 	default:
@@ -66,9 +127,14 @@ func (h *CollectionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CollectionHandler) getCollectionPath(r *http.Request) string {
+	return collectionPath(r, h.DefaultPath)
+}
+
+// collectionPath returns the collections directory given by the path parameter, or the default one.
+func collectionPath(r *http.Request, defaultPath string) string {
 	path := r.URL.Query().Get("path")
 	if path == "" {
-		return h.DefaultPath
+		return defaultPath
 	}
 	return path
 }
@@ -114,7 +180,7 @@ func (h *CollectionHandler) exportCollection(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	exportedCollection := collection.ToExportedCollection()
+	exportedCollection := collection.ToPostmanCollection()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", collection.Name))
@@ -123,6 +189,163 @@ func (h *CollectionHandler) exportCollection(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+func (h *CollectionHandler) exportOpenAPIDocument(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("id")
+	if collectionID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID is required", nil, h.Logger)
+		return
+	}
+
+	collection, err := models.LoadCollection(h.getCollectionPath(r), collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	var env *models.Environment
+	if envID := r.URL.Query().Get("env"); envID != "" {
+		env, err = models.LoadEnvironment(h.EnvironmentsPath, envID)
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load environment", err, h.Logger)
+			return
+		}
+	}
+
+	responses, err := h.recordedResponses(collection)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to read history", err, h.Logger)
+		return
+	}
+
+	document := collection.ToOpenAPIDocument(env, responses)
+
+	if r.URL.Query().Get("format") == "yaml" {
+		data, err := document.ToYAML()
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode exported document", err, h.Logger)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.yaml", collection.Name))
+		if _, err := w.Write(data); err != nil {
+			h.Logger.Printf("Failed to write exported document: %v", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", collection.Name))
+	if err := json.NewEncoder(w).Encode(document); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode exported document", err, h.Logger)
+	}
+}
+
+// exportHAR archives the last execution of each request of the collection.
+func (h *CollectionHandler) exportHAR(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("id")
+	if collectionID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID is required", nil, h.Logger)
+		return
+	}
+
+	collection, err := models.LoadCollection(h.getCollectionPath(r), collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	var entries []models.HistoryEntry
+	if h.HistoryPath != "" {
+		history, _, err := models.ListHistory(h.HistoryPath, models.HistoryFilter{RequestIDs: collection.RequestIDs()})
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to read history", err, h.Logger)
+			return
+		}
+
+		latest := make(map[string]models.HistoryEntry)
+		for _, entry := range history {
+			if _, ok := latest[entry.Request.ID]; !ok {
+				latest[entry.Request.ID] = entry
+			}
+		}
+		for _, req := range collection.Requests {
+			if entry, ok := latest[req.ID]; ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.har", collection.Name))
+	if err := json.NewEncoder(w).Encode(models.NewHAR(entries)); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode archive", err, h.Logger)
+	}
+}
+
+// exportHTTPFile writes the collection as a .http file, declaring the variables of the environment
+// selected by the env parameter.
+func (h *CollectionHandler) exportHTTPFile(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("id")
+	if collectionID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID is required", nil, h.Logger)
+		return
+	}
+
+	collection, err := models.LoadCollection(h.getCollectionPath(r), collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	var env *models.Environment
+	if envID := r.URL.Query().Get("env"); envID != "" {
+		env, err = models.LoadEnvironment(h.EnvironmentsPath, envID)
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load environment", err, h.Logger)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.http", collection.Name))
+	if _, err := io.WriteString(w, collection.ToHTTPFile(env)); err != nil {
+		h.Logger.Printf("Failed to write exported file: %v", err)
+	}
+}
+
+// recordedResponses returns the responses of the collection requests found in the history,
+// newest first.
+func (h *CollectionHandler) recordedResponses(collection *models.Collection) (map[string][]models.ExecutionResponse, error) {
+	responses := make(map[string][]models.ExecutionResponse)
+	if h.HistoryPath == "" {
+		return responses, nil
+	}
+
+	entries, _, err := models.ListHistory(h.HistoryPath, models.HistoryFilter{RequestIDs: collection.RequestIDs()})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Response != nil {
+			responses[entry.Request.ID] = append(responses[entry.Request.ID], *entry.Response)
+		}
+	}
+	return responses, nil
+}
+
+// listParameter reads a query parameter given several times or as a comma separated list.
+func listParameter(r *http.Request, name string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
 // POST methods
 
 func (h *CollectionHandler) createCollection(w http.ResponseWriter, r *http.Request) {
@@ -136,7 +359,8 @@ func (h *CollectionHandler) createCollection(w http.ResponseWriter, r *http.Requ
 		if collection.Requests[i].ID == "" {
 			collection.Requests[i].ID = uuid.New().String()
 		}
-		if !collection.Requests[i].Method.IsValid() {
+		// The method is only meaningful for HTTP requests
+		if collection.Requests[i].Type.IsHTTP() && !collection.Requests[i].Method.IsValid() {
 			message := fmt.Sprintf("Invalid HTTP method in request '%s': %s", collection.Requests[i].Name, collection.Requests[i].Method)
 			apperrors.RespondWithError(w, http.StatusBadRequest, message, nil, h.Logger)
 			return
@@ -163,32 +387,49 @@ func (h *CollectionHandler) createCollection(w http.ResponseWriter, r *http.Requ
 	}
 }
 
-func (h *CollectionHandler) importCollection(w http.ResponseWriter, r *http.Request) {
-	var importedCollection models.ImportedCollection
-	if err := json.NewDecoder(r.Body).Decode(&importedCollection); err != nil {
-		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse imported collection", err, h.Logger)
+func (h *CollectionHandler) importCollection(w http.ResponseWriter, r *http.Request, importer func([]byte) (*models.ImportResult, error)) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to read imported collection", err, h.Logger)
 		return
 	}
 
-	newCollection, _ := models.NewCollectionFromImported(importedCollection)
-
-	for _, req := range newCollection.Requests {
-		if !req.Method.IsValid() {
-			var message = fmt.Sprintf("Invalid HTTP method in request '%s': %s", req.Name, req.Method)
-			apperrors.RespondWithError(w, http.StatusBadRequest, message, nil, h.Logger)
-			return
-		}
+	result, err := importer(data)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to import collection", err, h.Logger)
+		return
 	}
 
 	collectionPath := h.getCollectionPath(r)
-	if err := newCollection.Save(collectionPath); err != nil {
+	if err := result.Collection.Save(collectionPath); err != nil {
 		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save imported collection", err, h.Logger)
 		return
 	}
 
+	// Nothing of the import is left behind when its variables can't be saved
+	if h.EnvironmentsPath != "" {
+		for i, environment := range result.Environments {
+			if err := environment.Save(h.EnvironmentsPath); err != nil {
+				for _, saved := range result.Environments[:i] {
+					_ = os.Remove(filepath.Join(h.EnvironmentsPath, saved.ID+".json"))
+				}
+				_ = os.Remove(filepath.Join(collectionPath, result.ID+".json"))
+				apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save imported variables", err, h.Logger)
+				return
+			}
+		}
+	}
+
+	// Imports answered with the saved collection before they had a report, which is only included
+	// when asked for so existing clients get the same response
+	var response interface{} = result.Collection
+	if r.URL.Query().Get("report") == "true" {
+		response = result
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(newCollection); err != nil {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode imported collection", err, h.Logger)
 	}
 }
@@ -209,7 +450,7 @@ func (h *CollectionHandler) updateCollection(w http.ResponseWriter, r *http.Requ
 	}
 
 	for _, req := range updatedCollection.Requests {
-		if !req.Method.IsValid() {
+		if req.Type.IsHTTP() && !req.Method.IsValid() {
 			var message = fmt.Sprintf("Invalid HTTP method in request '%s': %s", req.Name, req.Method)
 			apperrors.RespondWithError(w, http.StatusBadRequest, message, nil, h.Logger)
 			return
@@ -227,6 +468,10 @@ func (h *CollectionHandler) updateCollection(w http.ResponseWriter, r *http.Requ
 	existingCollection.Name = updatedCollection.Name
 	existingCollection.Description = updatedCollection.Description
 	existingCollection.Requests = updatedCollection.Requests
+	// The tree is kept when only the requests are sent
+	if updatedCollection.Items != nil {
+		existingCollection.Items = updatedCollection.Items
+	}
 
 	if err := existingCollection.Save(collectionPath); err != nil {
 		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save updated collection", err, h.Logger)
@@ -253,7 +498,7 @@ func (h *CollectionHandler) addRequestToCollection(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if !request.Method.IsValid() {
+	if request.Type.IsHTTP() && !request.Method.IsValid() {
 		var message = fmt.Sprintf("Invalid HTTP method in request '%s': %s", request.Name, request.Method)
 		apperrors.RespondWithError(w, http.StatusBadRequest, message, nil, h.Logger)
 		return
@@ -274,6 +519,12 @@ func (h *CollectionHandler) addRequestToCollection(w http.ResponseWriter, r *htt
 		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to add request", err, h.Logger)
 		return
 	}
+	if folderID := r.URL.Query().Get("folderId"); folderID != "" {
+		if err := collection.MoveItem(request.ID, folderID, -1); err != nil {
+			h.respondWithFolderError(w, err)
+			return
+		}
+	}
 	if err := collection.Save(collectionPath); err != nil {
 		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save collection", err, h.Logger)
 		return
@@ -286,6 +537,181 @@ func (h *CollectionHandler) addRequestToCollection(w http.ResponseWriter, r *htt
 	}
 }
 
+// addFolder creates an empty folder inside the folder given by parentId, or at the root.
+func (h *CollectionHandler) addFolder(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("id")
+	if collectionID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID is required", nil, h.Logger)
+		return
+	}
+
+	var folder models.Folder
+	if err := json.NewDecoder(r.Body).Decode(&folder); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse folder", err, h.Logger)
+		return
+	}
+	folder.ID = uuid.New().String()
+
+	collectionPath := h.getCollectionPath(r)
+
+	collection, err := models.LoadCollection(collectionPath, collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	if err := collection.AddFolder(&folder, r.URL.Query().Get("parentId")); err != nil {
+		h.respondWithFolderError(w, err)
+		return
+	}
+	if err := collection.Save(collectionPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save collection", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(folder); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode added folder", err, h.Logger)
+	}
+}
+
+// updateFolder renames a folder and sets its description, its items are left as they are.
+func (h *CollectionHandler) updateFolder(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("id")
+	folderID := r.URL.Query().Get("folderId")
+	if collectionID == "" || folderID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID and folder ID are required", nil, h.Logger)
+		return
+	}
+
+	var updatedFolder models.Folder
+	if err := json.NewDecoder(r.Body).Decode(&updatedFolder); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse folder", err, h.Logger)
+		return
+	}
+	if updatedFolder.Name == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Folder name cannot be empty", nil, h.Logger)
+		return
+	}
+
+	collectionPath := h.getCollectionPath(r)
+
+	collection, err := models.LoadCollection(collectionPath, collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	folder, ok := collection.FindFolder(folderID)
+	if !ok {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Folder not found in collection", nil, h.Logger)
+		return
+	}
+	folder.Name = updatedFolder.Name
+	folder.Description = updatedFolder.Description
+
+	if err := collection.Save(collectionPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save collection", err, h.Logger)
+		return
+	}
+	folder, _ = collection.FindFolder(folderID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(folder); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode updated folder", err, h.Logger)
+	}
+}
+
+// moveItem moves a folder or a request, given by itemId, into the folder given by parentId or to
+// the root. The optional position is its index among its new siblings, it goes last otherwise.
+func (h *CollectionHandler) moveItem(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("id")
+	itemID := r.URL.Query().Get("itemId")
+	if collectionID == "" || itemID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID and item ID are required", nil, h.Logger)
+		return
+	}
+
+	position := -1
+	if value := r.URL.Query().Get("position"); value != "" {
+		var err error
+		if position, err = strconv.Atoi(value); err != nil || position < 0 {
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid position", err, h.Logger)
+			return
+		}
+	}
+
+	collectionPath := h.getCollectionPath(r)
+
+	collection, err := models.LoadCollection(collectionPath, collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	if err := collection.MoveItem(itemID, r.URL.Query().Get("parentId"), position); err != nil {
+		h.respondWithFolderError(w, err)
+		return
+	}
+	h.saveTree(w, collection, collectionPath)
+}
+
+// reorderItems sorts the items of the folder given by parentId, or of the root, as the list of
+// IDs sent in the body.
+func (h *CollectionHandler) reorderItems(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("id")
+	if collectionID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID is required", nil, h.Logger)
+		return
+	}
+
+	var order []string
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse item order", err, h.Logger)
+		return
+	}
+
+	collectionPath := h.getCollectionPath(r)
+
+	collection, err := models.LoadCollection(collectionPath, collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	if err := collection.ReorderItems(r.URL.Query().Get("parentId"), order); err != nil {
+		h.respondWithFolderError(w, err)
+		return
+	}
+	h.saveTree(w, collection, collectionPath)
+}
+
+// saveTree saves a collection whose tree changed and answers with it.
+func (h *CollectionHandler) saveTree(w http.ResponseWriter, collection *models.Collection, collectionPath string) {
+	if err := collection.Save(collectionPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save collection", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(collection); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode updated collection", err, h.Logger)
+	}
+}
+
+// respondWithFolderError answers with the status of the error of a folder operation.
+func (h *CollectionHandler) respondWithFolderError(w http.ResponseWriter, err error) {
+	var appErr apperrors.AppError
+	if errors.As(err, &appErr) {
+		apperrors.RespondWithError(w, appErr.Code, appErr.Message, appErr.Err, h.Logger)
+		return
+	}
+	apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to update folders", err, h.Logger)
+}
+
 // DELETE methods
 
 func (h *CollectionHandler) deleteCollection(w http.ResponseWriter, r *http.Request) {
@@ -345,3 +771,36 @@ func (h *CollectionHandler) deleteRequestFromCollection(w http.ResponseWriter, r
 		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
 	}
 }
+
+// deleteFolder removes a folder along with its subfolders and requests.
+func (h *CollectionHandler) deleteFolder(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("collectionId")
+	folderID := r.URL.Query().Get("folderId")
+	if collectionID == "" || folderID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID and folder ID are required", nil, h.Logger)
+		return
+	}
+
+	collectionPath := h.getCollectionPath(r)
+
+	collection, err := models.LoadCollection(collectionPath, collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	if !collection.RemoveFolder(folderID) {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Folder not found in collection", nil, h.Logger)
+		return
+	}
+
+	if err := collection.Save(collectionPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save collection", err, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("Folder deleted successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
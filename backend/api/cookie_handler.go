@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+type CookieHandler struct {
+	DefaultPath string
+	Logger      *logrus.Logger
+}
+
+func (h *CookieHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getCookies(w, r)
+	case http.MethodPut:
+		h.putCookie(w, r)
+	case http.MethodDelete:
+		h.deleteCookies(w, r)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+func (h *CookieHandler) getJarID(r *http.Request) string {
+	id := r.URL.Query().Get("env")
+	if id == "" {
+		return models.DefaultCookieJarID
+	}
+	return id
+}
+
+func (h *CookieHandler) getCookies(w http.ResponseWriter, r *http.Request) {
+	cookieJarMu.Lock()
+	jar, err := models.LoadCookieJar(h.DefaultPath, h.getJarID(r))
+	cookieJarMu.Unlock()
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load cookie jar", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(jar.List(r.URL.Query().Get("domain"))); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode cookies", err, h.Logger)
+	}
+}
+
+func (h *CookieHandler) putCookie(w http.ResponseWriter, r *http.Request) {
+	var cookie models.Cookie
+	if err := json.NewDecoder(r.Body).Decode(&cookie); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse cookie", err, h.Logger)
+		return
+	}
+
+	if cookie.Name == "" || cookie.Domain == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Cookie name and domain are required", nil, h.Logger)
+		return
+	}
+
+	cookieJarMu.Lock()
+	defer cookieJarMu.Unlock()
+
+	jar, err := models.LoadCookieJar(h.DefaultPath, h.getJarID(r))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load cookie jar", err, h.Logger)
+		return
+	}
+
+	jar.Put(cookie)
+	if err := jar.Save(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save cookie jar", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(jar.List("")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode cookies", err, h.Logger)
+	}
+}
+
+// deleteCookies clears the whole jar, or only the cookies of a domain (and name) when given.
+func (h *CookieHandler) deleteCookies(w http.ResponseWriter, r *http.Request) {
+	domain := r.URL.Query().Get("domain")
+	name := r.URL.Query().Get("name")
+
+	if name != "" && domain == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Cookie domain is required", nil, h.Logger)
+		return
+	}
+
+	cookieJarMu.Lock()
+	defer cookieJarMu.Unlock()
+
+	jar, err := models.LoadCookieJar(h.DefaultPath, h.getJarID(r))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load cookie jar", err, h.Logger)
+		return
+	}
+
+	if domain == "" {
+		jar.Clear()
+	} else if jar.RemoveDomain(domain, name) == 0 {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Cookie not found", nil, h.Logger)
+		return
+	}
+
+	if err := jar.Save(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save cookie jar", err, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("Cookies deleted successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+// cookieJarMu serializes every read-modify-write of the jar files.
+var cookieJarMu sync.Mutex
+
+type cookieUpdate struct {
+	url     *url.URL
+	cookies []*http.Cookie
+}
+
+// sessionJar serves cookies from the jar loaded before the request and records what the
+// responses set, so the changes can be merged into the jar file once the request is done.
+type sessionJar struct {
+	jar     *models.CookieJar
+	mu      sync.Mutex
+	updates []cookieUpdate
+}
+
+func (s *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	s.jar.SetCookies(u, cookies)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, cookieUpdate{url: u, cookies: cookies})
+}
+
+func (s *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return s.jar.Cookies(u)
+}
+
+func cookieJarID(env *models.Environment) string {
+	if env == nil || env.ID == "" {
+		return models.DefaultCookieJarID
+	}
+	return env.ID
+}
+
+func (h *RequestHandler) openCookieJar(env *models.Environment) (*sessionJar, error) {
+	if h.CookiesPath == "" {
+		return nil, nil
+	}
+
+	cookieJarMu.Lock()
+	defer cookieJarMu.Unlock()
+
+	jar, err := models.LoadCookieJar(h.CookiesPath, cookieJarID(env))
+	if err != nil {
+		return nil, err
+	}
+	return &sessionJar{jar: jar}, nil
+}
+
+func (h *RequestHandler) saveCookieJar(session *sessionJar) error {
+	session.mu.Lock()
+	updates := session.updates
+	session.mu.Unlock()
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	cookieJarMu.Lock()
+	defer cookieJarMu.Unlock()
+
+	// Reload so cookies stored by concurrent requests or edited by hand are kept
+	jar, err := models.LoadCookieJar(h.CookiesPath, session.jar.ID)
+	if err != nil {
+		return err
+	}
+	for _, update := range updates {
+		jar.SetCookies(update.url, update.cookies)
+	}
+	return jar.Save(h.CookiesPath)
+}
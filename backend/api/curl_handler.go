@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// CurlHandler converts curl commands into requests and saved requests into curl commands.
+type CurlHandler struct {
+	CollectionsPath  string
+	EnvironmentsPath string
+	Logger           *logrus.Logger
+}
+
+func (h *CurlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.exportRequest(w, r)
+	case http.MethodPost:
+		h.importCommand(w, r)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+// exportRequest renders a saved request as a curl command, resolved with the environment selected
+// by the env parameter.
+func (h *CurlHandler) exportRequest(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("collectionId")
+	requestID := r.URL.Query().Get("requestId")
+	if collectionID == "" || requestID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID and request ID are required", nil, h.Logger)
+		return
+	}
+
+	collection, err := models.LoadCollection(collectionPath(r, h.CollectionsPath), collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	req, ok := collection.FindRequest(requestID)
+	if !ok {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Request not found in collection", nil, h.Logger)
+		return
+	}
+	if !req.Type.IsHTTP() {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Only HTTP requests can be exported as curl commands", nil, h.Logger)
+		return
+	}
+
+	var env *models.Environment
+	if envID := r.URL.Query().Get("env"); envID != "" {
+		env, err = models.LoadEnvironment(h.EnvironmentsPath, envID)
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load environment", err, h.Logger)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := io.WriteString(w, req.ToCurlCommand(env)+"\n"); err != nil {
+		h.Logger.Printf("Failed to write curl command: %v", err)
+	}
+}
+
+// importCommand parses the curl command in the body. The request isn't saved, it can be added to
+// a collection afterwards.
+func (h *CurlHandler) importCommand(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to read command", err, h.Logger)
+		return
+	}
+
+	result, err := models.ParseCurlCommand(string(data))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to import command", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode imported request", err, h.Logger)
+	}
+}
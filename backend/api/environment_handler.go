@@ -64,6 +64,13 @@ func (h *EnvironmentHandler) createEnvironment(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if environment.Proxy != nil {
+		if err := environment.Proxy.Validate(); err != nil {
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid proxy settings", err, h.Logger)
+			return
+		}
+	}
+
 	environment.ID = uuid.New().String()
 	err = environment.Save(h.DefaultPath)
 	if err != nil {
@@ -94,6 +101,13 @@ func (h *EnvironmentHandler) updateEnvironment(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if updatedEnvironment.Proxy != nil {
+		if err := updatedEnvironment.Proxy.Validate(); err != nil {
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid proxy settings", err, h.Logger)
+			return
+		}
+	}
+
 	existingEnvironment, err := models.LoadEnvironment(h.DefaultPath, id)
 	if err != nil {
 		apperrors.RespondWithError(w, http.StatusNotFound, "Environment not found", err, h.Logger)
@@ -102,6 +116,7 @@ func (h *EnvironmentHandler) updateEnvironment(w http.ResponseWriter, r *http.Re
 
 	existingEnvironment.Name = updatedEnvironment.Name
 	existingEnvironment.Variables = updatedEnvironment.Variables
+	existingEnvironment.Proxy = updatedEnvironment.Proxy
 
 	err = existingEnvironment.Save(h.DefaultPath)
 	if err != nil {
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+const (
+	defaultEventSourceRetry = 3 * time.Second
+	// maxEventSourceRetries is the number of consecutive failed reconnections before giving up
+	maxEventSourceRetries = 5
+	maxEventSourceLine    = 16 << 20
+)
+
+const (
+	EventSourceClosed  = "closed"
+	EventSourceStopped = "stopped"
+)
+
+var errEventSourceClosed = errors.New("server closed the event stream")
+
+type eventSourceReconnect struct {
+	Attempt     int    `json:"attempt"`
+	RetryMs     int64  `json:"retryMs"`
+	LastEventID string `json:"lastEventId,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type eventSourceDone struct {
+	Reason      string `json:"reason"`
+	LastEventID string `json:"lastEventId,omitempty"`
+	Events      int    `json:"events"`
+}
+
+// eventSourceDecoder parses a text/event-stream body incrementally, as described in the HTML
+// specification: events are dispatched on blank lines and an unterminated event at EOF is dropped.
+type eventSourceDecoder struct {
+	scanner     *bufio.Scanner
+	lastEventID string
+	retry       time.Duration
+}
+
+func newEventSourceDecoder(r io.Reader, lastEventID string) *eventSourceDecoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxEventSourceLine)
+	scanner.Split(scanEventSourceLines)
+	return &eventSourceDecoder{scanner: scanner, lastEventID: lastEventID}
+}
+
+// scanEventSourceLines splits lines ending in CRLF, LF or a lone CR.
+func scanEventSourceLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// Wait to know whether the CR is followed by a LF
+		return 0, nil, nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Next returns the next event, or io.EOF once the stream ends.
+func (d *eventSourceDecoder) Next() (*models.ServerSentEvent, error) {
+	var data strings.Builder
+	var eventType string
+	var retry int
+	hasData := false
+
+	for d.scanner.Scan() {
+		line := d.scanner.Text()
+
+		if line == "" {
+			if !hasData {
+				eventType = ""
+				continue
+			}
+			if eventType == "" {
+				eventType = "message"
+			}
+			return &models.ServerSentEvent{
+				ID:        d.lastEventID,
+				Event:     eventType,
+				Data:      strings.TrimSuffix(data.String(), "\n"),
+				Retry:     retry,
+				Timestamp: time.Now(),
+			}, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				d.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				retry = ms
+				d.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// serveEventSource connects to a text/event-stream endpoint and forwards the parsed events as
+// server-sent events: "started" with the execution ID, "open" for each connection, "event" for each
+// event, "reconnecting" when the stream drops, then "done" or "error". Dropped streams are resumed
+// with the Last-Event-ID header. The stream stops when the execution is cancelled, the UI goes away
+// or the server answers 204.
+func (h *RequestHandler) serveEventSource(w http.ResponseWriter, r *http.Request, req models.Request, env *models.Environment, opts ExecutionOptions) {
+	ctx, executionID, done, err := h.Executions.Start(r.Context(), opts.ExecutionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusConflict, "Execution ID already in use", err, h.Logger)
+		return
+	}
+	defer done()
+	ctx = withStreaming(ctx)
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Streaming is not supported", err, h.Logger)
+		return
+	}
+
+	if err := stream.send("started", struct {
+		ExecutionID string `json:"executionId"`
+	}{executionID}); err != nil {
+		return
+	}
+
+	if req.Method == "" {
+		req.Method = models.MethodGet
+	}
+
+	result := eventSourceDone{LastEventID: r.URL.Query().Get("lastEventId")}
+	retry := defaultEventSourceRetry
+	failures := 0
+
+	for {
+		resp, err := h.openEventSource(ctx, stream, executionID, req, env, result.LastEventID)
+		if resp != nil {
+			failures = 0
+			decoder := newEventSourceDecoder(resp.Body, result.LastEventID)
+			err = h.forwardEvents(stream, decoder, &result)
+			if decoder.retry > 0 {
+				retry = decoder.retry
+			}
+			if closeErr := resp.Body.Close(); closeErr != nil {
+				h.Logger.WithError(closeErr).Error("Error closing the body")
+			}
+		}
+
+		if ctx.Err() != nil {
+			result.Reason = EventSourceStopped
+			break
+		}
+
+		if errors.Is(err, errEventSourceClosed) {
+			result.Reason = EventSourceClosed
+			break
+		}
+		if err != nil {
+			var appErr apperrors.AppError
+			if errors.As(err, &appErr) && appErr.Code != http.StatusInternalServerError {
+				// The server answered but not with an event stream, retrying won't help
+				h.sendStreamError(stream, err)
+				return
+			}
+			failures++
+			if failures > maxEventSourceRetries {
+				h.sendStreamError(stream, err)
+				return
+			}
+		}
+
+		reconnect := eventSourceReconnect{Attempt: failures + 1, RetryMs: retry.Milliseconds(), LastEventID: result.LastEventID}
+		if err != nil {
+			reconnect.Error = err.Error()
+		}
+		if err := stream.send("reconnecting", reconnect); err != nil {
+			return
+		}
+
+		select {
+		case <-time.After(retry):
+			continue
+		case <-ctx.Done():
+		}
+		result.Reason = EventSourceStopped
+		break
+	}
+
+	if err := stream.send("done", result); err != nil {
+		h.Logger.WithError(err).Error("Failed to send stream completion")
+	}
+}
+
+// openEventSource connects to the endpoint, returning the response once the server has accepted the stream.
+func (h *RequestHandler) openEventSource(ctx context.Context, stream *eventStream, executionID string, req models.Request, env *models.Environment, lastEventID string) (*http.Response, error) {
+	headers := append([]models.Header{
+		{Key: "Accept", Value: "text/event-stream"},
+		{Key: "Cache-Control", Value: "no-cache"},
+	}, req.Headers...)
+	if lastEventID != "" {
+		headers = append(headers, models.Header{Key: "Last-Event-ID", Value: lastEventID})
+	}
+	req.Headers = headers
+
+	resp, trace, err := h.ExecuteRequest(ctx, req, env)
+	if err != nil {
+		return nil, err
+	}
+
+	response := newExecutionResponse(executionID, resp, trace)
+	response.Timings = trace.Timings(time.Now())
+	if err := stream.send("open", response); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNoContent {
+		_ = resp.Body.Close()
+		return nil, errEventSourceClosed
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || mediaType != "text/event-stream" {
+		_ = resp.Body.Close()
+		return nil, apperrors.NewAppError(http.StatusBadGateway, "Server did not return an event stream",
+			fmt.Errorf("status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type")))
+	}
+
+	return resp, nil
+}
+
+func (h *RequestHandler) forwardEvents(stream *eventStream, decoder *eventSourceDecoder, result *eventSourceDone) error {
+	defer func() {
+		result.LastEventID = decoder.lastEventID
+	}()
+
+	for {
+		event, err := decoder.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Events++
+		if err := stream.send("event", event); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+)
+
+// eventStream writes server-sent events to the UI, flushing after each one.
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newEventStream(w http.ResponseWriter) (*eventStream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("response writer does not support flushing")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &eventStream{w: w, flusher: flusher}, nil
+}
+
+func (s *eventStream) send(event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// sendError reports an error as an "error" event, with the same shape as apperrors.RespondWithError.
+func (s *eventStream) sendError(err error) error {
+	appErr := apperrors.NewAppError(http.StatusInternalServerError, "Failed to execute request", err)
+	errors.As(err, &appErr)
+
+	return s.send("error", struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Error   string `json:"error,omitempty"`
+	}{
+		Code:    appErr.Code,
+		Message: appErr.Message,
+		Error:   appErr.Error(),
+	})
+}
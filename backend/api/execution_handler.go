@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/sirupsen/logrus"
+)
+
+type ExecutionHandler struct {
+	Registry *ExecutionRegistry
+	Logger   *logrus.Logger
+}
+
+func (h *ExecutionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getExecutions(w)
+	case http.MethodDelete:
+		h.cancelExecution(w, r)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+func (h *ExecutionHandler) getExecutions(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.Registry.List()); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode executions", err, h.Logger)
+	}
+}
+
+func (h *ExecutionHandler) cancelExecution(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Execution ID is required", nil, h.Logger)
+		return
+	}
+
+	if !h.Registry.Cancel(id) {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Execution not found", nil, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("Execution cancelled successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
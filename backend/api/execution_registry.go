@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// StatusClientClosedRequest is reported when an execution is cancelled before it completes.
+const StatusClientClosedRequest = 499
+
+// ExecutionRegistry keeps track of the in-flight executions so they can be cancelled by ID.
+type ExecutionRegistry struct {
+	mu         sync.Mutex
+	executions map[string]context.CancelFunc
+}
+
+func NewExecutionRegistry() *ExecutionRegistry {
+	return &ExecutionRegistry{executions: make(map[string]context.CancelFunc)}
+}
+
+// Start registers a cancellable execution derived from parent, generating an ID when none is given.
+// The returned function must be called once the execution is over.
+func (r *ExecutionRegistry) Start(parent context.Context, id string) (context.Context, string, func(), error) {
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	if r == nil {
+		return ctx, id, cancel, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.executions[id]; exists {
+		cancel()
+		return nil, "", nil, fmt.Errorf("execution %s is already running", id)
+	}
+	r.executions[id] = cancel
+
+	done := func() {
+		r.mu.Lock()
+		delete(r.executions, id)
+		r.mu.Unlock()
+		cancel()
+	}
+	return ctx, id, done, nil
+}
+
+func (r *ExecutionRegistry) Cancel(id string) bool {
+	r.mu.Lock()
+	cancel, ok := r.executions[id]
+	r.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (r *ExecutionRegistry) List() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(r.executions))
+	for id := range r.executions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ActionIntrospect = "introspect"
+	ActionValidate   = "validate"
+)
+
+// GraphQLHandler fetches and caches GraphQL schemas. Schemas are keyed by the endpoint URL
+// with the variables of the selected environment applied.
+type GraphQLHandler struct {
+	DefaultPath string
+	Executor    *RequestHandler
+	Logger      *logrus.Logger
+}
+
+func (h *GraphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := r.URL.Query().Get("action")
+	switch r.Method {
+	case http.MethodGet:
+		h.getSchema(w, r)
+	case http.MethodPost:
+		switch action {
+		case ActionIntrospect:
+			h.introspectSchema(w, r)
+		case ActionValidate:
+			h.validateQuery(w, r)
+		default:
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid action", nil, h.Logger)
+		}
+	case http.MethodDelete:
+		h.deleteSchema(w, r)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+func (h *GraphQLHandler) loadEnvironment(r *http.Request) (*models.Environment, error) {
+	envID := r.URL.Query().Get("env")
+	if envID == "" {
+		return nil, nil
+	}
+	return models.LoadEnvironment(h.Executor.EnvironmentPath, envID)
+}
+
+// GET methods
+
+func (h *GraphQLHandler) getSchema(w http.ResponseWriter, r *http.Request) {
+	rawURL := r.URL.Query().Get("url")
+	if rawURL == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Schema URL is required", nil, h.Logger)
+		return
+	}
+
+	env, err := h.loadEnvironment(r)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load environment", err, h.Logger)
+		return
+	}
+
+	schema, err := models.LoadGraphQLSchema(h.DefaultPath, h.Executor.substituteVariables(rawURL, env))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Schema not found", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(schema); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode schema", err, h.Logger)
+	}
+}
+
+// POST methods
+
+func (h *GraphQLHandler) introspectSchema(w http.ResponseWriter, r *http.Request) {
+	var req models.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err, h.Logger)
+		return
+	}
+
+	env, err := h.loadEnvironment(r)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load environment", err, h.Logger)
+		return
+	}
+
+	// Headers, auth and settings are kept so protected endpoints can be introspected
+	if req.Method == "" {
+		req.Method = models.MethodPost
+	}
+	req.BodyConfig = &models.RequestBody{
+		Mode:    models.BodyGraphQL,
+		GraphQL: &models.GraphQLBody{Query: models.IntrospectionQuery, OperationName: "IntrospectionQuery"},
+	}
+
+	resp, _, err := h.Executor.ExecuteRequest(r.Context(), req, env)
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			h.Logger.WithError(err).Error("Error closing the body")
+		}
+	}(resp.Body)
+
+	var result struct {
+		Data   json.RawMessage   `json:"data"`
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadGateway, "Invalid introspection response", err, h.Logger)
+		return
+	}
+	if len(result.Data) == 0 || string(result.Data) == "null" {
+		err := fmt.Errorf("status %d, %d errors", resp.StatusCode, len(result.Errors))
+		apperrors.RespondWithError(w, http.StatusBadGateway, "Introspection returned no schema", err, h.Logger)
+		return
+	}
+
+	schema := models.NewGraphQLSchema(h.Executor.substituteVariables(req.URL, env), result.Data)
+	// Make sure the schema can be rebuilt before caching it
+	if _, err := schema.ValidateQuery("{ __typename }"); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadGateway, "Invalid introspection response", err, h.Logger)
+		return
+	}
+
+	if err := schema.Save(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save schema", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(schema); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode schema", err, h.Logger)
+	}
+}
+
+func (h *GraphQLHandler) validateQuery(w http.ResponseWriter, r *http.Request) {
+	var req models.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err, h.Logger)
+		return
+	}
+
+	if req.BodyConfig == nil || req.BodyConfig.Mode != models.BodyGraphQL || req.BodyConfig.GraphQL == nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Request has no GraphQL body", nil, h.Logger)
+		return
+	}
+
+	env, err := h.loadEnvironment(r)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load environment", err, h.Logger)
+		return
+	}
+
+	schema, err := models.LoadGraphQLSchema(h.DefaultPath, h.Executor.substituteVariables(req.URL, env))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Schema not found, introspect the endpoint first", err, h.Logger)
+		return
+	}
+
+	result, err := schema.ValidateQuery(h.Executor.substituteVariables(req.BodyConfig.GraphQL.Query, env))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load schema", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode validation result", err, h.Logger)
+	}
+}
+
+// DELETE methods
+
+func (h *GraphQLHandler) deleteSchema(w http.ResponseWriter, r *http.Request) {
+	rawURL := r.URL.Query().Get("url")
+	if rawURL == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Schema URL is required", nil, h.Logger)
+		return
+	}
+
+	env, err := h.loadEnvironment(r)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load environment", err, h.Logger)
+		return
+	}
+
+	err = models.DeleteGraphQLSchema(h.DefaultPath, h.Executor.substituteVariables(rawURL, env))
+	if err != nil {
+		if os.IsNotExist(err) {
+			apperrors.RespondWithError(w, http.StatusNotFound, "Schema not found", err, h.Logger)
+		} else {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to delete schema", err, h.Logger)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("Schema deleted successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
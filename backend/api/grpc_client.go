@@ -0,0 +1,603 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/linker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Messages are shown with their unset fields, so request templates list every field
+var grpcJSON = protojson.MarshalOptions{EmitUnpopulated: true}
+
+// grpcDescriptorSource resolves services either from proto files or through server reflection.
+type grpcDescriptorSource interface {
+	ListServices() ([]string, error)
+	FindService(name string) (protoreflect.ServiceDescriptor, error)
+}
+
+type protoFileSource struct {
+	files linker.Files
+}
+
+func (s *protoFileSource) ListServices() ([]string, error) {
+	var names []string
+	for _, file := range s.files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			names = append(names, string(services.Get(i).FullName()))
+		}
+	}
+	return names, nil
+}
+
+func (s *protoFileSource) FindService(name string) (protoreflect.ServiceDescriptor, error) {
+	descriptor, err := s.files.AsResolver().FindDescriptorByName(protoreflect.FullName(name))
+	if service, ok := descriptor.(protoreflect.ServiceDescriptor); err == nil && ok {
+		return service, nil
+	}
+	return nil, fmt.Errorf("service %s not found in proto files", name)
+}
+
+// Both versions of the reflection service exchange the same messages, v1alpha is tried when a
+// server doesn't implement v1.
+var reflectionMethods = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// reflectionSource resolves services through server reflection, keeping the files received in a
+// registry so each one is only requested once.
+type reflectionSource struct {
+	ctx    context.Context
+	conn   *grpc.ClientConn
+	stream grpc.ClientStream
+	cancel context.CancelFunc
+	files  *protoregistry.Files
+}
+
+func newReflectionSource(ctx context.Context, conn *grpc.ClientConn) *reflectionSource {
+	return &reflectionSource{ctx: ctx, conn: conn, files: new(protoregistry.Files)}
+}
+
+func (s *reflectionSource) ListServices() ([]string, error) {
+	response, err := s.call(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The reflection service itself is of no interest to the user
+	var names []string
+	for _, service := range response.GetListServicesResponse().GetService() {
+		if !strings.HasPrefix(service.GetName(), "grpc.reflection.") {
+			names = append(names, service.GetName())
+		}
+	}
+	return names, nil
+}
+
+func (s *reflectionSource) FindService(name string) (protoreflect.ServiceDescriptor, error) {
+	if _, err := s.files.FindDescriptorByName(protoreflect.FullName(name)); err != nil {
+		response, err := s.call(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: name},
+		})
+		if err != nil {
+			return nil, err
+		}
+		protos, err := decodeFileDescriptors(response, nil)
+		if err != nil {
+			return nil, err
+		}
+		for fileName := range protos {
+			if err := s.register(fileName, protos); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	descriptor, err := s.files.FindDescriptorByName(protoreflect.FullName(name))
+	if service, ok := descriptor.(protoreflect.ServiceDescriptor); err == nil && ok {
+		return service, nil
+	}
+	return nil, fmt.Errorf("service %s not found on the server", name)
+}
+
+// register adds a file to the registry after its dependencies, requesting those the server didn't
+// send along. Well-known types are taken from the ones compiled in when the server doesn't send them.
+func (s *reflectionSource) register(name string, protos map[string]*descriptorpb.FileDescriptorProto) error {
+	if _, err := s.files.FindFileByPath(name); err == nil {
+		return nil
+	}
+
+	file, ok := protos[name]
+	if !ok {
+		if known, err := protoregistry.GlobalFiles.FindFileByPath(name); err == nil {
+			return s.files.RegisterFile(known)
+		}
+		response, err := s.call(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+		})
+		if err != nil {
+			return err
+		}
+		if protos, err = decodeFileDescriptors(response, protos); err != nil {
+			return err
+		}
+		if file, ok = protos[name]; !ok {
+			return fmt.Errorf("file %s was not sent by the server", name)
+		}
+	}
+
+	for _, dependency := range file.GetDependency() {
+		if err := s.register(dependency, protos); err != nil {
+			return err
+		}
+	}
+	descriptor, err := protodesc.NewFile(file, s.files)
+	if err != nil {
+		return err
+	}
+	return s.files.RegisterFile(descriptor)
+}
+
+func decodeFileDescriptors(response *reflectionpb.ServerReflectionResponse, protos map[string]*descriptorpb.FileDescriptorProto) (map[string]*descriptorpb.FileDescriptorProto, error) {
+	if protos == nil {
+		protos = make(map[string]*descriptorpb.FileDescriptorProto)
+	}
+	for _, data := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := new(descriptorpb.FileDescriptorProto)
+		if err := proto.Unmarshal(data, file); err != nil {
+			return nil, err
+		}
+		protos[file.GetName()] = file
+	}
+	return protos, nil
+}
+
+// call sends a request on the reflection stream, which is opened on the first call.
+func (s *reflectionSource) call(request *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	if s.stream != nil {
+		return exchangeReflection(s.stream, request)
+	}
+
+	var err error
+	for _, method := range reflectionMethods {
+		ctx, cancel := context.WithCancel(s.ctx)
+		var stream grpc.ClientStream
+		stream, err = s.conn.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, method)
+		if err == nil {
+			var response *reflectionpb.ServerReflectionResponse
+			if response, err = exchangeReflection(stream, request); err == nil {
+				s.stream, s.cancel = stream, cancel
+				return response, nil
+			}
+		}
+		cancel()
+		if status.Code(err) != codes.Unimplemented {
+			break
+		}
+	}
+	return nil, err
+}
+
+func exchangeReflection(stream grpc.ClientStream, request *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	// A failed send returns io.EOF, the status of the stream is read by RecvMsg
+	if err := stream.SendMsg(request); err != nil && err != io.EOF {
+		return nil, err
+	}
+	response := new(reflectionpb.ServerReflectionResponse)
+	if err := stream.RecvMsg(response); err != nil {
+		return nil, err
+	}
+	if failure := response.GetErrorResponse(); failure != nil {
+		return nil, status.Error(codes.Code(failure.GetErrorCode()), failure.GetErrorMessage())
+	}
+	return response, nil
+}
+
+func (s *reflectionSource) Close() {
+	if s.stream != nil {
+		_ = s.stream.CloseSend()
+		s.cancel()
+	}
+}
+
+// grpcConnection is an open connection to a gRPC server along with the metadata sent on every call.
+type grpcConnection struct {
+	conn     *grpc.ClientConn
+	metadata metadata.MD
+	source   grpcDescriptorSource
+	reflect  *reflectionSource
+}
+
+func (c *grpcConnection) Close() error {
+	if c.reflect != nil {
+		c.reflect.Close()
+	}
+	return c.conn.Close()
+}
+
+func (c *grpcConnection) outgoingContext(ctx context.Context) context.Context {
+	return metadata.NewOutgoingContext(ctx, c.metadata)
+}
+
+// dialGRPC connects to the target of a gRPC request and loads its descriptor source.
+func (h *RequestHandler) dialGRPC(ctx context.Context, req models.Request, env *models.Environment) (*grpcConnection, error) {
+	if req.GRPC == nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "gRPC requests require a gRPC configuration", nil)
+	}
+	if err := req.GRPC.Validate(); err != nil {
+		return nil, err
+	}
+
+	target, creds, err := h.grpcTarget(req, env)
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := h.grpcMetadata(req, env)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	proxy, err := h.proxyFor(env)
+	if err != nil {
+		return nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to configure client", err)
+	}
+	proxyURL, err := proxy.ProxyURL(&url.URL{Host: target})
+	if err != nil {
+		return nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to configure client", err)
+	}
+	if proxyURL != nil {
+		// The proxy resolves the target host, so it is passed to the dialer as is
+		target = "passthrough:///" + target
+		options = append(options, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dialProxy(ctx, proxyURL, address)
+		}))
+	}
+
+	conn, err := grpc.NewClient(target, options...)
+	if err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid gRPC target", err)
+	}
+	c := &grpcConnection{conn: conn, metadata: md}
+
+	switch req.GRPC.Source {
+	case models.GRPCSourceProto:
+		files, err := h.parseProtoFiles(ctx, req.GRPC, env)
+		if err != nil {
+			_ = conn.Close()
+			return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to parse proto files", err)
+		}
+		c.source = &protoFileSource{files: files}
+	default:
+		c.reflect = newReflectionSource(c.outgoingContext(ctx), conn)
+		c.source = c.reflect
+	}
+
+	return c, nil
+}
+
+// grpcTarget strips the grpc:// or grpcs:// scheme from the request URL, which decides whether
+// TLS is used. Targets without a scheme use TLS unless the request is marked as plaintext.
+func (h *RequestHandler) grpcTarget(req models.Request, env *models.Environment) (string, credentials.TransportCredentials, error) {
+	target := strings.TrimSuffix(h.substituteVariables(req.URL, env), "/")
+	plaintext := req.GRPC.Plaintext
+
+	if strings.HasPrefix(target, "grpc://") {
+		target, plaintext = strings.TrimPrefix(target, "grpc://"), true
+	} else if strings.HasPrefix(target, "grpcs://") {
+		target, plaintext = strings.TrimPrefix(target, "grpcs://"), false
+	}
+
+	if target == "" {
+		return "", nil, apperrors.NewAppError(http.StatusBadRequest, "gRPC target cannot be empty", nil)
+	}
+
+	if plaintext {
+		return target, insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := h.tlsConfigFor(&url.URL{Scheme: "https", Host: target})
+	if err != nil {
+		return "", nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to configure client", err)
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	if req.Settings != nil && req.Settings.SkipTLSVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return target, credentials.NewTLS(tlsConfig), nil
+}
+
+// grpcMetadata turns the request headers and authentication into call metadata. Calls have no
+// HTTP request to sign, so the authentication types that need one are refused.
+func (h *RequestHandler) grpcMetadata(req models.Request, env *models.Environment) (metadata.MD, error) {
+	md := metadata.MD{}
+	for _, header := range req.Headers {
+		md.Set(strings.ToLower(header.Key), h.substituteVariables(header.Value, env))
+	}
+
+	auth := req.Auth
+	if auth == nil {
+		return md, nil
+	}
+	switch auth.Type {
+	case models.AuthNone:
+	case models.AuthBasic:
+		userinfo := h.substituteVariables(auth.Params["username"], env) + ":" + h.substituteVariables(auth.Params["password"], env)
+		md.Set("authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(userinfo)))
+	case models.AuthBearer:
+		md.Set("authorization", "Bearer "+h.substituteVariables(auth.Params["token"], env))
+	case models.AuthOAuth2:
+		md.Set("authorization", "Bearer "+h.substituteVariables(auth.Params["access_token"], env))
+	case models.AuthAPIKey:
+		if auth.Params["in"] == "query" {
+			return nil, apperrors.NewAppError(http.StatusBadRequest, "gRPC calls have no query, send the API key in a header", nil)
+		}
+		if auth.Params["in"] == "header" {
+			md.Set(strings.ToLower(h.substituteVariables(auth.Params["key"], env)), h.substituteVariables(auth.Params["value"], env))
+		}
+	case models.AuthAWSSigV4, models.AuthDigest:
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Authentication type not supported for gRPC calls", fmt.Errorf("%s", auth.Type))
+	default:
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Unknown authentication type", fmt.Errorf("%s", auth.Type))
+	}
+	return md, nil
+}
+
+// parseProtoFiles parses the proto files of a request. Files under one of the import paths are
+// named relative to it so their imports resolve, other files are looked up in their own directory.
+func (h *RequestHandler) parseProtoFiles(ctx context.Context, config *models.GRPCRequest, env *models.Environment) (linker.Files, error) {
+	var importPaths []string
+	for _, importPath := range config.ImportPaths {
+		importPaths = append(importPaths, h.substituteVariables(importPath, env))
+	}
+
+	names := make([]string, 0, len(config.ProtoFiles))
+	for _, file := range config.ProtoFiles {
+		file = h.substituteVariables(file, env)
+		name := ""
+		for _, importPath := range importPaths {
+			if rel, err := filepath.Rel(importPath, file); err == nil && !strings.HasPrefix(rel, "..") {
+				name = filepath.ToSlash(rel)
+				break
+			}
+		}
+		if name == "" {
+			importPaths = append(importPaths, filepath.Dir(file))
+			name = filepath.Base(file)
+		}
+		names = append(names, name)
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	return compiler.Compile(ctx, names...)
+}
+
+// ListGRPCServices lists the services of a gRPC request target with their methods.
+func (h *RequestHandler) ListGRPCServices(ctx context.Context, req models.Request, env *models.Environment) ([]models.GRPCService, error) {
+	c, err := h.dialGRPC(ctx, req, env)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	names, err := c.source.ListServices()
+	if err != nil {
+		return nil, grpcSourceError(err)
+	}
+	sort.Strings(names)
+
+	services := make([]models.GRPCService, 0, len(names))
+	for _, name := range names {
+		service, err := c.source.FindService(name)
+		if err != nil {
+			return nil, grpcSourceError(err)
+		}
+
+		descriptors := service.Methods()
+		methods := make([]models.GRPCMethod, 0, descriptors.Len())
+		for i := 0; i < descriptors.Len(); i++ {
+			method := descriptors.Get(i)
+			template, err := grpcJSON.Marshal(dynamicpb.NewMessage(method.Input()))
+			if err != nil {
+				return nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to build request template", err)
+			}
+			methods = append(methods, models.GRPCMethod{
+				Name:            string(method.Name()),
+				FullName:        string(method.FullName()),
+				ClientStreaming: method.IsStreamingClient(),
+				ServerStreaming: method.IsStreamingServer(),
+				InputType:       string(method.Input().FullName()),
+				OutputType:      string(method.Output().FullName()),
+				RequestTemplate: template,
+			})
+		}
+		services = append(services, models.GRPCService{Name: name, Methods: methods})
+	}
+
+	return services, nil
+}
+
+// InvokeGRPC calls a unary or server-streaming method. Each response message is passed to
+// onMessage as JSON. A call that fails on the server is not an error: its status is reported
+// in the response along with the headers and trailers.
+func (h *RequestHandler) InvokeGRPC(ctx context.Context, executionID string, req models.Request, env *models.Environment, onMessage func(json.RawMessage) error) (*models.GRPCResponse, error) {
+	c, err := h.dialGRPC(ctx, req, env)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if req.GRPC.Service == "" || req.GRPC.Method == "" {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "gRPC service and method are required", nil)
+	}
+
+	service, err := c.source.FindService(req.GRPC.Service)
+	if err != nil {
+		return nil, grpcSourceError(err)
+	}
+	method := service.Methods().ByName(protoreflect.Name(req.GRPC.Method))
+	if method == nil {
+		return nil, apperrors.NewAppError(http.StatusNotFound, "gRPC method not found", fmt.Errorf("%s/%s", req.GRPC.Service, req.GRPC.Method))
+	}
+	if method.IsStreamingClient() {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Only unary and server-streaming methods are supported", fmt.Errorf("%s", method.FullName()))
+	}
+
+	request := dynamicpb.NewMessage(method.Input())
+	if message := strings.TrimSpace(h.substituteVariables(req.GRPC.Message, env)); message != "" {
+		if err := protojson.Unmarshal([]byte(message), request); err != nil {
+			return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid request message", err)
+		}
+	}
+
+	callCtx := c.outgoingContext(ctx)
+	if !method.IsStreamingServer() {
+		timeout := h.Client.Timeout
+		if req.Settings != nil && req.Settings.TimeoutMs > 0 {
+			timeout = time.Duration(req.Settings.TimeoutMs) * time.Millisecond
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(callCtx, timeout)
+			defer cancel()
+		}
+	}
+
+	response := &models.GRPCResponse{ExecutionID: executionID}
+	fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+	start := time.Now()
+
+	var header, trailer metadata.MD
+	if method.IsStreamingServer() {
+		err = h.receiveGRPCStream(callCtx, c.conn, fullMethod, method, request, &header, &trailer, onMessage)
+	} else {
+		reply := dynamicpb.NewMessage(method.Output())
+		err = c.conn.Invoke(callCtx, fullMethod, request, reply, grpc.Header(&header), grpc.Trailer(&trailer))
+		if err == nil {
+			err = forwardGRPCMessage(reply, onMessage)
+		}
+	}
+
+	var sendErr *grpcForwardError
+	if errors.As(err, &sendErr) {
+		return nil, executionError(ctx, "Failed to forward response message", sendErr.err)
+	}
+
+	st := status.Convert(err)
+	response.Status = models.GRPCStatus{Code: int(st.Code()), Name: st.Code().String(), Message: st.Message()}
+	response.Headers = grpcHeaders(header)
+	response.Trailers = grpcHeaders(trailer)
+	response.Duration = float64(time.Since(start).Microseconds()) / 1000
+
+	return response, nil
+}
+
+func (h *RequestHandler) receiveGRPCStream(ctx context.Context, conn *grpc.ClientConn, fullMethod string, method protoreflect.MethodDescriptor, request proto.Message, header, trailer *metadata.MD, onMessage func(json.RawMessage) error) error {
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, fullMethod)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		*header, _ = stream.Header()
+		*trailer = stream.Trailer()
+	}()
+
+	// A failed send returns io.EOF, the status of the call is read by RecvMsg
+	if err := stream.SendMsg(request); err != nil && err != io.EOF {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		reply := dynamicpb.NewMessage(method.Output())
+		err := stream.RecvMsg(reply)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := forwardGRPCMessage(reply, onMessage); err != nil {
+			return err
+		}
+	}
+}
+
+// grpcForwardError tells a failure to hand a message over to the UI from a failed call.
+type grpcForwardError struct {
+	err error
+}
+
+func (e *grpcForwardError) Error() string {
+	return e.err.Error()
+}
+
+func forwardGRPCMessage(reply proto.Message, onMessage func(json.RawMessage) error) error {
+	data, err := grpcJSON.Marshal(reply)
+	if err != nil {
+		return &grpcForwardError{err}
+	}
+
+	if err := onMessage(data); err != nil {
+		return &grpcForwardError{err}
+	}
+	return nil
+}
+
+func grpcSourceError(err error) error {
+	if st, ok := status.FromError(err); ok && st.Code() != codes.OK && st.Code() != codes.NotFound {
+		return apperrors.NewAppError(http.StatusBadGateway, "Failed to load gRPC descriptors", err)
+	}
+	return apperrors.NewAppError(http.StatusNotFound, "gRPC service not found", err)
+}
+
+func grpcHeaders(md metadata.MD) []models.Header {
+	header := http.Header{}
+	for key, values := range md {
+		header[key] = values
+	}
+	return models.NewResponseHeaders(header)
+}
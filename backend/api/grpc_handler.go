@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ActionListServices = "services"
+	ActionInvoke       = "invoke"
+)
+
+type GRPCHandler struct {
+	Executor *RequestHandler
+	Logger   *logrus.Logger
+}
+
+func (h *GRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+		return
+	}
+
+	var req models.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err, h.Logger)
+		return
+	}
+
+	var env *models.Environment
+	if envID := r.URL.Query().Get("env"); envID != "" {
+		var err error
+		env, err = models.LoadEnvironment(h.Executor.EnvironmentPath, envID)
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load environment", err, h.Logger)
+			return
+		}
+	}
+
+	switch r.URL.Query().Get("action") {
+	case ActionListServices:
+		h.listServices(w, r, req, env)
+	case ActionInvoke:
+		if r.URL.Query().Get("stream") == "true" {
+			h.streamInvoke(w, r, req, env)
+		} else {
+			h.invoke(w, r, req, env)
+		}
+	default:
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid action", nil, h.Logger)
+	}
+}
+
+func (h *GRPCHandler) listServices(w http.ResponseWriter, r *http.Request, req models.Request, env *models.Environment) {
+	services, err := h.Executor.ListGRPCServices(r.Context(), req, env)
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(services); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode services", err, h.Logger)
+	}
+}
+
+func (h *GRPCHandler) invoke(w http.ResponseWriter, r *http.Request, req models.Request, env *models.Environment) {
+	ctx, executionID, done, err := h.Executor.Executions.Start(r.Context(), r.URL.Query().Get("executionId"))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusConflict, "Execution ID already in use", err, h.Logger)
+		return
+	}
+	defer done()
+
+	messages := []json.RawMessage{}
+	response, err := h.Executor.InvokeGRPC(ctx, executionID, req, env, func(message json.RawMessage) error {
+		messages = append(messages, message)
+		return nil
+	})
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+	response.Messages = messages
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode response", err, h.Logger)
+	}
+}
+
+// streamInvoke forwards the call as server-sent events: "started" with the execution ID, "message"
+// for each response message, then "done" with the status, headers and trailers, or "error".
+func (h *GRPCHandler) streamInvoke(w http.ResponseWriter, r *http.Request, req models.Request, env *models.Environment) {
+	ctx, executionID, done, err := h.Executor.Executions.Start(r.Context(), r.URL.Query().Get("executionId"))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusConflict, "Execution ID already in use", err, h.Logger)
+		return
+	}
+	defer done()
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Streaming is not supported", err, h.Logger)
+		return
+	}
+
+	if err := stream.send("started", struct {
+		ExecutionID string `json:"executionId"`
+	}{executionID}); err != nil {
+		return
+	}
+
+	response, err := h.Executor.InvokeGRPC(ctx, executionID, req, env, func(message json.RawMessage) error {
+		return stream.send("message", message)
+	})
+	if err != nil {
+		h.Executor.sendStreamError(stream, err)
+		return
+	}
+
+	if err := stream.send("done", response); err != nil {
+		h.Logger.WithError(err).Error("Failed to send stream completion")
+	}
+}
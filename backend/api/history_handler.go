@@ -0,0 +1,236 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ActionReplay       = "replay"
+	ActionClearHistory = "clear"
+)
+
+// Page size of the history list when no limit is given
+const defaultHistoryPageSize = 100
+
+type HistoryHandler struct {
+	DefaultPath string
+	Executor    *RequestHandler
+	Logger      *logrus.Logger
+}
+
+func (h *HistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := r.URL.Query().Get("action")
+	switch r.Method {
+	case http.MethodGet:
+		switch {
+		case action == ActionExportHAR:
+			h.exportHAR(w, r)
+		case r.URL.Query().Get("id") != "":
+			h.getHistoryEntry(w, r)
+		default:
+			h.getHistory(w, r)
+		}
+	case http.MethodPost:
+		if action == ActionReplay {
+			h.replayHistoryEntry(w, r)
+		} else {
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid action", nil, h.Logger)
+		}
+	case http.MethodDelete:
+		if action == ActionClearHistory {
+			h.clearHistory(w)
+		} else {
+			h.deleteHistoryEntry(w, r)
+		}
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+// GET methods
+
+func (h *HistoryHandler) getHistory(w http.ResponseWriter, r *http.Request) {
+	filter, err := historyFilter(r)
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryPageSize
+	}
+
+	entries, total, err := models.ListHistory(h.DefaultPath, filter)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to read history", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode history", err, h.Logger)
+	}
+}
+
+// exportHAR archives the history entries matching the filter, oldest first.
+func (h *HistoryHandler) exportHAR(w http.ResponseWriter, r *http.Request) {
+	filter, err := historyFilter(r)
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+
+	entries, _, err := models.ListHistory(h.DefaultPath, filter)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to read history", err, h.Logger)
+		return
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=history.har")
+	if err := json.NewEncoder(w).Encode(models.NewHAR(entries)); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode archive", err, h.Logger)
+	}
+}
+
+func historyFilter(r *http.Request) (models.HistoryFilter, error) {
+	query := r.URL.Query()
+	filter := models.HistoryFilter{
+		Query:  query.Get("q"),
+		Method: query.Get("method"),
+	}
+
+	if status := query.Get("status"); status != "" {
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			return filter, apperrors.NewAppError(http.StatusBadRequest, "Invalid status filter", err)
+		}
+		filter.Status = code
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return filter, apperrors.NewAppError(http.StatusBadRequest, "Invalid limit", err)
+		}
+		filter.Limit = n
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return filter, apperrors.NewAppError(http.StatusBadRequest, "Invalid offset", err)
+		}
+		filter.Offset = n
+	}
+
+	return filter, nil
+}
+
+func (h *HistoryHandler) getHistoryEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := models.LoadHistoryEntry(h.DefaultPath, r.URL.Query().Get("id"))
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "History entry not found", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entry); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode history entry", err, h.Logger)
+	}
+}
+
+// POST methods
+
+func (h *HistoryHandler) replayHistoryEntry(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "History entry ID is required", nil, h.Logger)
+		return
+	}
+
+	entry, err := models.LoadHistoryEntry(h.DefaultPath, id)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "History entry not found", err, h.Logger)
+		return
+	}
+
+	// Replay with the environment snapshot taken at the time, unless another one is selected
+	env := entry.Environment
+	if envID := r.URL.Query().Get("env"); envID != "" {
+		env, err = models.LoadEnvironment(h.Executor.EnvironmentPath, envID)
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load environment", err, h.Logger)
+			return
+		}
+	} else if env != nil && env.Proxy != nil {
+		// Snapshots don't keep proxy credentials, take them from the environment if it still uses that proxy
+		if current, err := models.LoadEnvironment(h.Executor.EnvironmentPath, entry.EnvironmentID); err == nil &&
+			current.Proxy != nil && current.Proxy.URL == env.Proxy.URL {
+			proxy := *env.Proxy
+			proxy.Username, proxy.Password = current.Proxy.Username, current.Proxy.Password
+			env.Proxy = &proxy
+		}
+	}
+
+	response, err := h.Executor.Run(r.Context(), entry.Request, env, ExecutionOptions{
+		ExecutionID: r.URL.Query().Get("executionId"),
+	})
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode response", err, h.Logger)
+	}
+}
+
+// DELETE methods
+
+func (h *HistoryHandler) deleteHistoryEntry(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "History entry ID is required", nil, h.Logger)
+		return
+	}
+
+	err := models.DeleteHistoryEntry(h.DefaultPath, id)
+	if err != nil {
+		if os.IsNotExist(err) {
+			apperrors.RespondWithError(w, http.StatusNotFound, "History entry not found", err, h.Logger)
+		} else {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to delete history entry", err, h.Logger)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("History entry deleted successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
+
+func (h *HistoryHandler) clearHistory(w http.ResponseWriter) {
+	if err := models.ClearHistory(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to clear history", err, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("History cleared successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+type ProxyHandler struct {
+	DefaultPath string
+	Logger      *logrus.Logger
+}
+
+func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getProxySettings(w)
+	case http.MethodPut:
+		h.updateProxySettings(w, r)
+	case http.MethodDelete:
+		h.deleteProxySettings(w)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+func (h *ProxyHandler) getProxySettings(w http.ResponseWriter) {
+	settings, err := models.LoadProxySettings(h.DefaultPath)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load proxy settings", err, h.Logger)
+		return
+	}
+	if settings == nil {
+		settings = &models.ProxySettings{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode proxy settings", err, h.Logger)
+	}
+}
+
+func (h *ProxyHandler) updateProxySettings(w http.ResponseWriter, r *http.Request) {
+	var settings models.ProxySettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to parse proxy settings", err, h.Logger)
+		return
+	}
+
+	if err := settings.Validate(); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid proxy settings", err, h.Logger)
+		return
+	}
+
+	if err := settings.Save(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save proxy settings", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode proxy settings", err, h.Logger)
+	}
+}
+
+func (h *ProxyHandler) deleteProxySettings(w http.ResponseWriter) {
+	if err := models.DeleteProxySettings(h.DefaultPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to delete proxy settings", err, h.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("Proxy settings deleted successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
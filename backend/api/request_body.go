@@ -0,0 +1,324 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+type requestBody struct {
+	reader      io.Reader
+	contentType string
+	// forceContentType is set for multipart bodies, whose boundary must win over any user header
+	forceContentType bool
+	length           int64
+	getBody          func() (io.ReadCloser, error)
+	// params are added to the URL query, used by GraphQL requests sent with GET
+	params url.Values
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// buildBody encodes the body of req, falling back to the raw Body string when no body config is set.
+func (h *RequestHandler) buildBody(req models.Request, env *models.Environment) (*requestBody, error) {
+	config := req.BodyConfig
+	if config == nil {
+		return &requestBody{reader: bytes.NewBufferString(h.substituteVariables(req.Body, env))}, nil
+	}
+
+	switch config.Mode {
+	case models.BodyNone:
+		return &requestBody{reader: http.NoBody}, nil
+
+	case models.BodyRaw:
+		return &requestBody{
+			reader:      bytes.NewBufferString(h.substituteVariables(config.Raw, env)),
+			contentType: defaultString(config.ContentType, "text/plain"),
+		}, nil
+
+	case models.BodyJSON:
+		raw := h.substituteVariables(config.Raw, env)
+		if strings.TrimSpace(raw) != "" && !json.Valid([]byte(raw)) {
+			return nil, fmt.Errorf("body is not valid JSON")
+		}
+		return &requestBody{
+			reader:      bytes.NewBufferString(raw),
+			contentType: defaultString(config.ContentType, "application/json"),
+		}, nil
+
+	case models.BodyURLEncoded:
+		values := url.Values{}
+		for _, field := range config.URLEncoded {
+			if field.Disabled {
+				continue
+			}
+			values.Add(h.substituteVariables(field.Key, env), h.substituteVariables(field.Value, env))
+		}
+		return &requestBody{
+			reader:      strings.NewReader(values.Encode()),
+			contentType: "application/x-www-form-urlencoded",
+		}, nil
+
+	case models.BodyFormData:
+		return h.buildMultipartBody(config.FormData, env)
+
+	case models.BodyBinary:
+		return h.buildFileBody(h.substituteVariables(config.FilePath, env), config.ContentType)
+
+	case models.BodyGraphQL:
+		return h.buildGraphQLBody(req.Method, config.GraphQL, env)
+
+	default:
+		return nil, fmt.Errorf("unsupported body mode: %s", config.Mode)
+	}
+}
+
+// formPart is a field of a multipart body, with its variables already substituted.
+type formPart struct {
+	key         string
+	value       string
+	path        string
+	contentType string
+	size        int64
+}
+
+// buildMultipartBody streams the form through a pipe, so upload files are never loaded in memory.
+// Its length is known beforehand from the size of the files.
+func (h *RequestHandler) buildMultipartBody(fields []models.FormField, env *models.Environment) (*requestBody, error) {
+	var parts []formPart
+	var filesSize int64
+	for _, field := range fields {
+		if field.Disabled {
+			continue
+		}
+
+		part := formPart{key: h.substituteVariables(field.Key, env)}
+		if field.Type != models.FormFieldFile {
+			part.value = h.substituteVariables(field.Value, env)
+			parts = append(parts, part)
+			continue
+		}
+
+		part.path = h.substituteVariables(field.FilePath, env)
+		info, err := os.Stat(part.path)
+		if err != nil {
+			return nil, err
+		}
+		part.contentType = fileContentType(part.path, field.ContentType)
+		part.size = info.Size()
+		filesSize += part.size
+		parts = append(parts, part)
+	}
+
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	counter := &countingWriter{}
+	if err := writeMultipart(counter, boundary, parts, false); err != nil {
+		return nil, err
+	}
+
+	return &requestBody{
+		reader:           newMultipartStream(boundary, parts),
+		contentType:      "multipart/form-data; boundary=" + boundary,
+		forceContentType: true,
+		length:           counter.n + filesSize,
+		getBody: func() (io.ReadCloser, error) {
+			return newMultipartStream(boundary, parts), nil
+		},
+	}, nil
+}
+
+// writeMultipart writes the form, leaving the content of the files out unless withFiles is set.
+func writeMultipart(dst io.Writer, boundary string, parts []formPart, withFiles bool) error {
+	writer := multipart.NewWriter(dst)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		if part.path == "" {
+			if err := writer.WriteField(part.key, part.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(part.key), quoteEscaper.Replace(filepath.Base(part.path))))
+		header.Set("Content-Type", part.contentType)
+
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if withFiles {
+			if err := copyFile(partWriter, part.path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writer.Close()
+}
+
+// multipartStream writes the form into a pipe once it is first read, so nothing is opened when
+// the request is never sent.
+type multipartStream struct {
+	boundary string
+	parts    []formPart
+	once     sync.Once
+	pipe     *io.PipeReader
+}
+
+func newMultipartStream(boundary string, parts []formPart) *multipartStream {
+	return &multipartStream{boundary: boundary, parts: parts}
+}
+
+func (s *multipartStream) start() {
+	reader, writer := io.Pipe()
+	s.pipe = reader
+	go func() {
+		writer.CloseWithError(writeMultipart(writer, s.boundary, s.parts, true))
+	}()
+}
+
+func (s *multipartStream) Read(p []byte) (int, error) {
+	s.once.Do(s.start)
+	return s.pipe.Read(p)
+}
+
+// Close stops the writer if the body is abandoned before the end.
+func (s *multipartStream) Close() error {
+	s.once.Do(func() {})
+	if s.pipe == nil {
+		return nil
+	}
+	return s.pipe.Close()
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+func (h *RequestHandler) buildGraphQLBody(method models.Method, body *models.GraphQLBody, env *models.Environment) (*requestBody, error) {
+	if body == nil {
+		return nil, fmt.Errorf("GraphQL body requires a query")
+	}
+
+	payload := models.GraphQLPayload{
+		Query:         h.substituteVariables(body.Query, env),
+		OperationName: h.substituteVariables(body.OperationName, env),
+	}
+	if variables := strings.TrimSpace(h.substituteVariables(body.Variables, env)); variables != "" {
+		if !json.Valid([]byte(variables)) {
+			return nil, fmt.Errorf("GraphQL variables are not valid JSON")
+		}
+		payload.Variables = json.RawMessage(variables)
+	}
+
+	// GraphQL over GET carries the operation in the query string
+	if method == models.MethodGet {
+		params := url.Values{}
+		params.Set("query", payload.Query)
+		if payload.Variables != nil {
+			params.Set("variables", string(payload.Variables))
+		}
+		if payload.OperationName != "" {
+			params.Set("operationName", payload.OperationName)
+		}
+		return &requestBody{reader: http.NoBody, params: params}, nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &requestBody{reader: bytes.NewReader(data), contentType: "application/json"}, nil
+}
+
+// buildFileBody streams the file instead of loading it in memory, reopening it if the body must be resent.
+func (h *RequestHandler) buildFileBody(path string, contentType string) (*requestBody, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &requestBody{
+		reader:      &lazyFile{path: path},
+		contentType: fileContentType(path, contentType),
+		length:      info.Size(),
+		getBody: func() (io.ReadCloser, error) {
+			return &lazyFile{path: path}, nil
+		},
+	}, nil
+}
+
+// lazyFile opens the file on the first read, so requests failing before they are sent leave no
+// file open. The transport closes it once the body is sent.
+type lazyFile struct {
+	path string
+	file *os.File
+}
+
+func (f *lazyFile) Read(p []byte) (int, error) {
+	if f.file == nil {
+		file, err := os.Open(f.path)
+		if err != nil {
+			return 0, err
+		}
+		f.file = file
+	}
+	return f.file.Read(p)
+}
+
+func (f *lazyFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
+
+func copyFile(dst io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(dst, file)
+	return err
+}
+
+func fileContentType(path string, contentType string) string {
+	if contentType != "" {
+		return contentType
+	}
+	if byExtension := mime.TypeByExtension(filepath.Ext(path)); byExtension != "" {
+		return byExtension
+	}
+	return "application/octet-stream"
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/models"
+	netproxy "golang.org/x/net/proxy"
+)
+
+type streamingKey struct{}
+
+// withStreaming marks executions whose body is streamed. The overall client timeout would cut
+// long downloads short, so those rely on an idle timeout enforced while reading instead.
+func withStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingKey{}, true)
+}
+
+// clientFor derives a client from the shared one, applying the request settings, the
+// certificates registered for the target host and the proxy settings on top of it.
+func (h *RequestHandler) clientFor(ctx context.Context, settings *models.RequestSettings, env *models.Environment, target *url.URL, trace *RequestTrace) (*http.Client, error) {
+	client := *h.Client
+	client.CheckRedirect = redirectPolicy(settings, trace)
+
+	if streaming, _ := ctx.Value(streamingKey{}).(bool); streaming {
+		client.Timeout = 0
+	}
+
+	if settings != nil && settings.TimeoutMs > 0 {
+		client.Timeout = time.Duration(settings.TimeoutMs) * time.Millisecond
+	}
+
+	tlsConfig, err := h.tlsConfigFor(target)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := h.proxyFor(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil || proxy != nil || (settings != nil && (settings.SkipTLSVerify || settings.HTTPVersion != models.HTTPVersionAuto)) {
+		client.Transport = h.newTransport(settings, tlsConfig, proxy)
+	}
+
+	return &client, nil
+}
+
+func (h *RequestHandler) tlsConfigFor(target *url.URL) (*tls.Config, error) {
+	if h.CertificatesPath == "" || target.Scheme != "https" {
+		return nil, nil
+	}
+
+	certificates, err := models.ListCertificates(h.CertificatesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	host := target.Host
+	if target.Port() == "" {
+		host = net.JoinHostPort(target.Hostname(), "443")
+	}
+
+	return models.NewTLSConfig(certificates, host)
+}
+
+// proxyFor returns the proxy settings of the environment, falling back to the global ones.
+func (h *RequestHandler) proxyFor(env *models.Environment) (*models.ProxySettings, error) {
+	if env != nil && env.Proxy != nil {
+		return env.Proxy, nil
+	}
+
+	if h.SettingsPath == "" {
+		return nil, nil
+	}
+
+	return models.LoadProxySettings(h.SettingsPath)
+}
+
+// dialProxy opens a connection to address through an HTTP CONNECT or SOCKS5 proxy, for the clients
+// that don't go through an http.Transport.
+func dialProxy(ctx context.Context, proxyURL *url.URL, address string) (net.Conn, error) {
+	if proxyURL.Scheme == "socks5" {
+		dialer, err := netproxy.FromURL(proxyURL, &net.Dialer{})
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(netproxy.ContextDialer).DialContext(ctx, "tcp", address)
+	}
+
+	proxyAddress := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddress = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	connect := &http.Request{Method: http.MethodConnect, URL: &url.URL{Opaque: address}, Host: address, Header: http.Header{}}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		connect.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := connect.Write(conn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, connect)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, fmt.Errorf("proxy refused to connect to %s: %s", address, resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn reads what was buffered past the proxy response before the rest of the connection.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+func (h *RequestHandler) newTransport(settings *models.RequestSettings, tlsConfig *tls.Config, proxy *models.ProxySettings) *http.Transport {
+	base, ok := h.Client.Transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport := base.Clone()
+	// Per-request transports are thrown away after use, so don't keep idle connections around
+	transport.DisableKeepAlives = true
+
+	if proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy.ProxyURL(req.URL)
+		}
+	}
+
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig.Clone()
+	} else if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+
+	if settings == nil {
+		return transport
+	}
+
+	transport.TLSClientConfig.InsecureSkipVerify = settings.SkipTLSVerify
+
+	switch settings.HTTPVersion {
+	case models.HTTPVersion1:
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		transport.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	case models.HTTPVersion2:
+		transport.ForceAttemptHTTP2 = true
+	}
+
+	return transport
+}
+
+func redirectPolicy(settings *models.RequestSettings, trace *RequestTrace) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if !settings.ShouldFollowRedirects() {
+			return http.ErrUseLastResponse
+		}
+
+		// Like curl --max-redirs, the limit is the number of redirects followed. Past it the
+		// redirect response itself is returned, and the hop is not part of the chain.
+		if len(via) > settings.RedirectLimit() {
+			return http.ErrUseLastResponse
+		}
+
+		redirect := models.Redirect{
+			URL:      via[len(via)-1].URL.String(),
+			Location: req.URL.String(),
+		}
+		if req.Response != nil {
+			redirect.StatusCode = req.Response.StatusCode
+		}
+		trace.addRedirect(redirect)
+		return nil
+	}
+}
@@ -1,18 +1,20 @@
 package api
 
 import (
-	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
-	"strings"
 	"time"
 
 	"github.com/FedeBP/pumoide/backend/apperrors"
 	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/FedeBP/pumoide/backend/utils"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/sirupsen/logrus"
@@ -21,7 +23,21 @@ import (
 type RequestHandler struct {
 	Client          *http.Client
 	EnvironmentPath string
-	Logger          *logrus.Logger
+	HistoryPath     string
+	// HistoryRetention is the number of history entries kept, models.DefaultHistoryRetention when unset
+	HistoryRetention int
+	CertificatesPath string
+	SettingsPath     string
+	CookiesPath      string
+	Executions       *ExecutionRegistry
+	MaxPreviewSize   int64
+	Logger           *logrus.Logger
+}
+
+type ExecutionOptions struct {
+	// ExecutionID lets the caller pick the ID used to cancel the execution, one is generated otherwise
+	ExecutionID string
+	SaveTo      string
 }
 
 func (h *RequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -42,41 +58,26 @@ func (h *RequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	resp, err := h.ExecuteRequest(req, env)
-	if err != nil {
-		if strings.HasPrefix(err.Error(), "Invalid HTTP method:") {
-			apperrors.RespondWithError(w, http.StatusBadRequest, err.Error(), nil, h.Logger)
-		} else {
-			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to execute request", err, h.Logger)
-		}
-		return
+	opts := ExecutionOptions{
+		ExecutionID: r.URL.Query().Get("executionId"),
+		SaveTo:      r.URL.Query().Get("saveTo"),
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			apperrors.RespondWithError(w, http.StatusInternalServerError, "Error closing the body", err, h.Logger)
-			return
-		}
-	}(resp.Body)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to read response body", err, h.Logger)
+	if req.Type == models.RequestSSE {
+		h.serveEventSource(w, r, req, env, opts)
 		return
 	}
 
-	response := struct {
-		StatusCode int               `json:"statusCode"`
-		Headers    map[string]string `json:"headers"`
-		Body       string            `json:"body"`
-	}{
-		StatusCode: resp.StatusCode,
-		Headers:    make(map[string]string),
-		Body:       string(body),
+	if r.URL.Query().Get("stream") == "true" {
+		h.serveStream(w, r, req, env, opts)
+		return
 	}
 
-	for k, v := range resp.Header {
-		response.Headers[k] = v[0]
+	// The request context is cancelled when the UI client disconnects
+	response, err := h.Run(r.Context(), req, env, opts)
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -86,44 +87,200 @@ func (h *RequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *RequestHandler) ExecuteRequest(req models.Request, env *models.Environment) (*http.Response, error) {
+// Run executes the request, reads the whole response and records the call in the history.
+func (h *RequestHandler) Run(ctx context.Context, req models.Request, env *models.Environment, opts ExecutionOptions) (*models.ExecutionResponse, error) {
+	ctx, executionID, done, err := h.Executions.Start(ctx, opts.ExecutionID)
+	if err != nil {
+		return nil, apperrors.NewAppError(http.StatusConflict, "Execution ID already in use", err)
+	}
+	defer done()
+
+	resp, trace, err := h.ExecuteRequest(ctx, req, env)
+	if err != nil {
+		h.recordHistory(req, env, trace, nil, err)
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			h.Logger.WithError(err).Error("Error closing the body")
+		}
+	}(resp.Body)
+
+	response := newExecutionResponse(executionID, resp, trace)
+
+	if opts.SaveTo != "" {
+		size, err := utils.SaveToFile(opts.SaveTo, resp.Body)
+		if err != nil {
+			err = executionError(ctx, "Failed to save response body", err)
+			h.recordHistory(req, env, trace, nil, err)
+			return nil, err
+		}
+		response.SavedTo = opts.SaveTo
+		response.Size = size
+	} else {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			err = executionError(ctx, "Failed to read response body", err)
+			h.recordHistory(req, env, trace, nil, err)
+			return nil, err
+		}
+		response.Body, response.Encoding = models.EncodeBody(resp.Header.Get("Content-Type"), body)
+		response.Size = int64(len(body))
+	}
+	response.Timings = trace.Timings(time.Now())
+
+	h.recordHistory(req, env, trace, response, nil)
+
+	return response, nil
+}
+
+// newExecutionResponse fills in everything known once the response headers are received.
+func newExecutionResponse(executionID string, resp *http.Response, trace *RequestTrace) *models.ExecutionResponse {
+	return &models.ExecutionResponse{
+		ExecutionID: executionID,
+		StatusCode:  resp.StatusCode,
+		Headers:     models.NewResponseHeaders(resp.Header),
+		Cookies:     models.NewResponseCookies(resp.Cookies()),
+		RemoteAddr:  trace.RemoteAddr(),
+		Protocol:    resp.Proto,
+		Redirects:   trace.Redirects(),
+	}
+}
+
+func (h *RequestHandler) ExecuteRequest(ctx context.Context, req models.Request, env *models.Environment) (*http.Response, *RequestTrace, error) {
+	if !req.Type.IsHTTP() {
+		return nil, nil, apperrors.NewAppError(http.StatusBadRequest, "Only HTTP requests can be executed, open a session instead", fmt.Errorf("%s", req.Type))
+	}
+
 	if !req.Method.IsValid() {
-		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid HTTP method", fmt.Errorf("%s", req.Method))
+		return nil, nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid HTTP method", fmt.Errorf("%s", req.Method))
 	}
 
-	parsedURL, err := url.Parse(h.substituteVariables(req.URL, env))
+	body, err := h.buildBody(req, env)
 	if err != nil {
-		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid URL", err)
+		return nil, nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to build request body", err)
 	}
 
-	q := parsedURL.Query()
-	for key, value := range req.QueryParams {
-		q.Add(key, h.substituteVariables(value, env))
+	parsedURL, err := h.resolveURL(req, env, body.params)
+	if err != nil {
+		return nil, nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid URL", err)
 	}
-	parsedURL.RawQuery = q.Encode()
 
-	httpReq, err := http.NewRequest(string(req.Method), parsedURL.String(), bytes.NewBufferString(h.substituteVariables(req.Body, env)))
+	httpReq, err := http.NewRequestWithContext(ctx, string(req.Method), parsedURL.String(), body.reader)
 	if err != nil {
-		return nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to create request", err)
+		return nil, nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to create request", err)
+	}
+	if body.getBody != nil {
+		httpReq.GetBody = body.getBody
+		httpReq.ContentLength = body.length
 	}
 
 	for _, header := range req.Headers {
 		httpReq.Header.Set(header.Key, h.substituteVariables(header.Value, env))
 	}
 
+	if body.contentType != "" && (body.forceContentType || httpReq.Header.Get("Content-Type") == "") {
+		httpReq.Header.Set("Content-Type", body.contentType)
+	}
+
 	if req.Auth != nil {
 		err = h.applyAuthentication(httpReq, req.Auth, env)
 		if err != nil {
-			return nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to apply authentication", err)
+			return nil, nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to apply authentication", err)
+		}
+	}
+
+	trace := NewRequestTrace(httpReq)
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), trace.ClientTrace()))
+
+	client, err := h.clientFor(ctx, req.Settings, env, httpReq.URL, trace)
+	if err != nil {
+		return nil, trace, apperrors.NewAppError(http.StatusInternalServerError, "Failed to configure client", err)
+	}
+
+	jar, err := h.openCookieJar(env)
+	if err != nil {
+		return nil, trace, apperrors.NewAppError(http.StatusInternalServerError, "Failed to load cookie jar", err)
+	}
+	if jar != nil {
+		client.Jar = jar
+	}
+
+	resp, err := client.Do(httpReq)
+
+	if jar != nil {
+		if err := h.saveCookieJar(jar); err != nil {
+			h.Logger.WithError(err).Error("Failed to save cookie jar")
 		}
 	}
 
-	resp, err := h.Client.Do(httpReq)
 	if err != nil {
-		return nil, apperrors.NewAppError(http.StatusInternalServerError, "Failed to execute request", err)
+		return nil, trace, executionError(ctx, "Failed to execute request", err)
+	}
+
+	return resp, trace, nil
+}
+
+// resolveURL applies the environment to the request URL and adds the query parameters to it.
+func (h *RequestHandler) resolveURL(req models.Request, env *models.Environment, params url.Values) (*url.URL, error) {
+	parsedURL, err := url.Parse(h.substituteVariables(req.URL, env))
+	if err != nil {
+		return nil, err
+	}
+
+	q := parsedURL.Query()
+	for key, value := range req.QueryParams {
+		q.Add(key, h.substituteVariables(value, env))
 	}
+	for key, values := range params {
+		q[key] = append(q[key], values...)
+	}
+	parsedURL.RawQuery = q.Encode()
 
-	return resp, nil
+	return parsedURL, nil
+}
+
+func (h *RequestHandler) recordHistory(req models.Request, env *models.Environment, trace *RequestTrace, response *models.ExecutionResponse, execErr error) {
+	if h.HistoryPath == "" {
+		return
+	}
+
+	entry := models.NewHistoryEntry(req, env)
+	if trace != nil {
+		entry.ResolvedRequest = trace.Request()
+	}
+	entry.Response = response
+	if execErr != nil {
+		entry.Error = execErr.Error()
+	}
+
+	if err := entry.Save(h.HistoryPath); err != nil {
+		h.Logger.WithError(err).Error("Failed to record request history")
+		return
+	}
+	if err := models.PruneHistory(h.HistoryPath, h.HistoryRetention); err != nil {
+		h.Logger.WithError(err).Error("Failed to prune request history")
+	}
+}
+
+// executionError reports cancelled executions distinctly from upstream failures.
+func executionError(ctx context.Context, message string, err error) error {
+	if errors.Is(context.Cause(ctx), errIdleTimeout) {
+		return apperrors.NewAppError(http.StatusGatewayTimeout, "Timed out waiting for response data", err)
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return apperrors.NewAppError(StatusClientClosedRequest, "Request cancelled", err)
+	}
+	return apperrors.NewAppError(http.StatusInternalServerError, message, err)
+}
+
+func respondWithExecutionError(w http.ResponseWriter, err error, logger *logrus.Logger) {
+	var appErr apperrors.AppError
+	if errors.As(err, &appErr) {
+		apperrors.RespondWithError(w, appErr.Code, appErr.Message, appErr.Err, logger)
+		return
+	}
+	apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to execute request", err, logger)
 }
 
 func (h *RequestHandler) applyAuthentication(req *http.Request, auth *models.Auth, env *models.Environment) error {
@@ -196,11 +353,5 @@ func (h *RequestHandler) applyAuthentication(req *http.Request, auth *models.Aut
 }
 
 func (h *RequestHandler) substituteVariables(input string, env *models.Environment) string {
-	if env == nil {
-		return input
-	}
-	for key, value := range env.Variables {
-		input = strings.ReplaceAll(input, "{{"+key+"}}", value)
-	}
-	return input
+	return env.Resolve(input)
 }
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/FedeBP/pumoide/backend/utils"
+)
+
+const (
+	DefaultPreviewSize = 1 << 20
+	streamChunkSize    = 32 * 1024
+)
+
+var errIdleTimeout = errors.New("no data received within the client timeout")
+
+type streamChunk struct {
+	Data     string `json:"data,omitempty"`
+	Received int64  `json:"received"`
+	Total    int64  `json:"total"`
+}
+
+// previewBuffer keeps at most limit bytes in memory and drops the rest.
+type previewBuffer struct {
+	data      []byte
+	limit     int64
+	truncated bool
+}
+
+func (b *previewBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - int64(len(b.data))
+	if int64(len(p)) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.data = append(b.data, p[:remaining]...)
+		}
+		return len(p), nil
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+func (h *RequestHandler) previewSize(r *http.Request) int64 {
+	if size, err := strconv.ParseInt(r.URL.Query().Get("previewSize"), 10, 64); err == nil && size >= 0 {
+		return size
+	}
+	if h.MaxPreviewSize > 0 {
+		return h.MaxPreviewSize
+	}
+	return DefaultPreviewSize
+}
+
+// serveStream executes the request and forwards the response as server-sent events: "started" with
+// the execution ID, "metadata" once the headers arrive, "chunk" for each piece of body (only progress
+// when saving to a file), then "done" with a bounded preview of the body, or "error".
+func (h *RequestHandler) serveStream(w http.ResponseWriter, r *http.Request, req models.Request, env *models.Environment, opts ExecutionOptions) {
+	ctx, executionID, done, err := h.Executions.Start(r.Context(), opts.ExecutionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusConflict, "Execution ID already in use", err, h.Logger)
+		return
+	}
+	defer done()
+
+	ctx, cancel := context.WithCancelCause(withStreaming(ctx))
+	defer cancel(nil)
+
+	keepAlive := func() {}
+	if h.Client.Timeout > 0 {
+		watchdog := time.AfterFunc(h.Client.Timeout, func() { cancel(errIdleTimeout) })
+		defer watchdog.Stop()
+		keepAlive = func() { watchdog.Reset(h.Client.Timeout) }
+	}
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Streaming is not supported", err, h.Logger)
+		return
+	}
+
+	if err := stream.send("started", struct {
+		ExecutionID string `json:"executionId"`
+	}{executionID}); err != nil {
+		return
+	}
+
+	resp, trace, err := h.ExecuteRequest(ctx, req, env)
+	if err != nil {
+		h.recordHistory(req, env, trace, nil, err)
+		h.sendStreamError(stream, err)
+		return
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			h.Logger.WithError(err).Error("Error closing the body")
+		}
+	}(resp.Body)
+
+	response := newExecutionResponse(executionID, resp, trace)
+	if err := stream.send("metadata", response); err != nil {
+		return
+	}
+
+	var file *os.File
+	if opts.SaveTo != "" {
+		file, err = utils.CreateFile(opts.SaveTo)
+		if err != nil {
+			err = apperrors.NewAppError(http.StatusInternalServerError, "Failed to save response body", err)
+			h.recordHistory(req, env, trace, nil, err)
+			h.sendStreamError(stream, err)
+			return
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				h.Logger.WithError(err).Error("Failed to close saved response body")
+			}
+		}()
+		response.SavedTo = opts.SaveTo
+	}
+
+	preview := &previewBuffer{limit: h.previewSize(r)}
+	received, err := h.forwardBody(ctx, stream, resp, preview, file, keepAlive)
+	if err != nil {
+		h.recordHistory(req, env, trace, nil, err)
+		h.sendStreamError(stream, err)
+		return
+	}
+
+	response.Body, response.Encoding = models.EncodeBody(resp.Header.Get("Content-Type"), preview.data)
+	response.Truncated = preview.truncated
+	response.Size = received
+	response.Timings = trace.Timings(time.Now())
+
+	h.recordHistory(req, env, trace, response, nil)
+
+	if err := stream.send("done", response); err != nil {
+		h.Logger.WithError(err).Error("Failed to send stream completion")
+	}
+}
+
+// forwardBody copies the body to the stream or to file, calling keepAlive whenever data arrives.
+func (h *RequestHandler) forwardBody(ctx context.Context, stream *eventStream, resp *http.Response, preview *previewBuffer, file *os.File, keepAlive func()) (int64, error) {
+	buf := make([]byte, streamChunkSize)
+	var received int64
+
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			keepAlive()
+			received += int64(n)
+			_, _ = preview.Write(buf[:n])
+
+			chunk := streamChunk{Received: received, Total: resp.ContentLength}
+			if file != nil {
+				if _, err := file.Write(buf[:n]); err != nil {
+					return received, apperrors.NewAppError(http.StatusInternalServerError, "Failed to save response body", err)
+				}
+			} else {
+				chunk.Data = base64.StdEncoding.EncodeToString(buf[:n])
+			}
+
+			if err := stream.send("chunk", chunk); err != nil {
+				// The UI went away, stop downloading
+				return received, executionError(ctx, "Failed to stream response body", err)
+			}
+		}
+
+		if readErr == io.EOF {
+			return received, nil
+		}
+		if readErr != nil {
+			return received, executionError(ctx, "Failed to read response body", readErr)
+		}
+	}
+}
+
+func (h *RequestHandler) sendStreamError(stream *eventStream, err error) {
+	h.Logger.WithError(err).Error("Streamed execution failed")
+	if sendErr := stream.sendError(err); sendErr != nil {
+		h.Logger.WithError(sendErr).Error("Failed to send stream error")
+	}
+}
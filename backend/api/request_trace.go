@@ -0,0 +1,126 @@
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+type RequestTrace struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	firstByte    time.Time
+	remoteAddr   string
+	request      models.ResolvedRequest
+	redirects    []models.Redirect
+}
+
+func NewRequestTrace(req *http.Request) *RequestTrace {
+	return &RequestTrace{start: time.Now(), request: models.NewResolvedRequest(req)}
+}
+
+func (t *RequestTrace) ClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mark(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mark(&t.dnsDone)
+		},
+		ConnectStart: func(string, string) {
+			// Dual-stack dialing may start several connections, keep the first one
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(string, string, error) {
+			t.mark(&t.connectDone)
+		},
+		TLSHandshakeStart: func() {
+			t.mark(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mark(&t.tlsDone)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.gotConn = time.Now()
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.mark(&t.firstByte)
+		},
+	}
+}
+
+// Request returns the request as it was sent, with variables and authentication applied.
+func (t *RequestTrace) Request() models.ResolvedRequest {
+	return t.request
+}
+
+func (t *RequestTrace) Redirects() []models.Redirect {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.redirects
+}
+
+func (t *RequestTrace) addRedirect(redirect models.Redirect) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.redirects = append(t.redirects, redirect)
+}
+
+func (t *RequestTrace) RemoteAddr() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.remoteAddr
+}
+
+// Timings computes the phase durations, end being the moment the body was fully read.
+func (t *RequestTrace) Timings(end time.Time) models.ResponseTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := models.ResponseTimings{
+		DNSLookup:     milliseconds(t.dnsStart, t.dnsDone),
+		TCPConnection: milliseconds(t.connectStart, t.connectDone),
+		TLSHandshake:  milliseconds(t.tlsStart, t.tlsDone),
+		Total:         milliseconds(t.start, end),
+	}
+
+	if !t.firstByte.IsZero() {
+		timings.TimeToFirstByte = milliseconds(t.gotConn, t.firstByte)
+		timings.ContentTransfer = milliseconds(t.firstByte, end)
+	}
+
+	return timings
+}
+
+func (t *RequestTrace) mark(field *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*field = time.Now()
+}
+
+func milliseconds(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return float64(to.Sub(from)) / float64(time.Millisecond)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/sirupsen/logrus"
+)
+
+// SnippetHandler renders requests as client code in the language given by the language parameter.
+type SnippetHandler struct {
+	CollectionsPath  string
+	EnvironmentsPath string
+	Logger           *logrus.Logger
+}
+
+func (h *SnippetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("requestId") == "" {
+			h.listLanguages(w)
+			return
+		}
+		h.renderSavedRequest(w, r)
+	case http.MethodPost:
+		h.renderRequest(w, r)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+func (h *SnippetHandler) listLanguages(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.GetValidSnippetLanguages()); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode response", err, h.Logger)
+	}
+}
+
+func (h *SnippetHandler) renderSavedRequest(w http.ResponseWriter, r *http.Request) {
+	collectionID := r.URL.Query().Get("collectionId")
+	if collectionID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID and request ID are required", nil, h.Logger)
+		return
+	}
+
+	collection, err := models.LoadCollection(collectionPath(r, h.CollectionsPath), collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load collection", err, h.Logger)
+		return
+	}
+
+	req, ok := collection.FindRequest(r.URL.Query().Get("requestId"))
+	if !ok {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Request not found in collection", nil, h.Logger)
+		return
+	}
+
+	h.writeSnippet(w, r, req)
+}
+
+// renderRequest renders the request in the body, so unsaved changes can be previewed.
+func (h *SnippetHandler) renderRequest(w http.ResponseWriter, r *http.Request) {
+	var req models.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err, h.Logger)
+		return
+	}
+
+	h.writeSnippet(w, r, &req)
+}
+
+func (h *SnippetHandler) writeSnippet(w http.ResponseWriter, r *http.Request, req *models.Request) {
+	language := models.SnippetLanguage(r.URL.Query().Get("language"))
+	if !language.IsValid() {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid snippet language", nil, h.Logger)
+		return
+	}
+
+	var env *models.Environment
+	if envID := r.URL.Query().Get("env"); envID != "" {
+		var err error
+		env, err = models.LoadEnvironment(h.EnvironmentsPath, envID)
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusNotFound, "Failed to load environment", err, h.Logger)
+			return
+		}
+	}
+
+	snippet, err := req.ToSnippet(language, env)
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := io.WriteString(w, snippet); err != nil {
+		h.Logger.Printf("Failed to write snippet: %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type testPKI struct {
+	caCert     *x509.Certificate
+	caKey      *ecdsa.PrivateKey
+	caPath     string
+	serverCert tls.Certificate
+	clientCert string
+	clientKey  string
+}
+
+func issueCertificate(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return cert, key
+}
+
+func writePEM(t *testing.T, path, blockType string, data []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func newTestPKI(t *testing.T, dir string) testPKI {
+	notAfter := time.Now().Add(time.Hour)
+
+	caCert, caKey := issueCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+
+	serverCert, serverKey := issueCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caCert, caKey)
+
+	clientCert, clientKey := issueCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "pumoide"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+
+	pki := testPKI{
+		caCert:     caCert,
+		caKey:      caKey,
+		caPath:     filepath.Join(dir, "ca.pem"),
+		serverCert: tls.Certificate{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey},
+		clientCert: filepath.Join(dir, "client.pem"),
+		clientKey:  filepath.Join(dir, "client-key.pem"),
+	}
+
+	writePEM(t, pki.caPath, "CERTIFICATE", caCert.Raw)
+	writePEM(t, pki.clientCert, "CERTIFICATE", clientCert.Raw)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal client key: %v", err)
+	}
+	writePEM(t, pki.clientKey, "EC PRIVATE KEY", keyDER)
+
+	return pki
+}
+
+func registerCertificate(t *testing.T, handler http.Handler, certificate models.Certificate) *httptest.ResponseRecorder {
+	body, err := json.Marshal(certificate)
+	if err != nil {
+		t.Fatalf("Failed to marshal certificate: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/certificates", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCertificateHandler_MutualTLS(t *testing.T) {
+	tempDir := t.TempDir()
+	storeDir := filepath.Join(tempDir, "certificates")
+	if err := os.MkdirAll(storeDir, 0755); err != nil {
+		t.Fatalf("Failed to create store dir: %v", err)
+	}
+	pki := newTestPKI(t, tempDir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(pki.caCert)
+
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	testServer.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	certificateHandler := &api.CertificateHandler{DefaultPath: storeDir, Logger: logger}
+	executor := &api.RequestHandler{
+		Client:           &http.Client{Timeout: 30 * time.Second},
+		CertificatesPath: storeDir,
+		Logger:           logger,
+	}
+
+	rr, _ := executeRequest(t, executor, models.Request{Method: models.MethodGet, URL: testServer.URL})
+	if rr.Code == http.StatusOK {
+		t.Fatalf("Request without registered certificates should fail")
+	}
+
+	rr = registerCertificate(t, certificateHandler, models.Certificate{
+		Name: "Test CA", Kind: models.CertificateCA, Format: models.CertificatePEM, CertPath: pki.caPath,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Failed to register CA: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = registerCertificate(t, certificateHandler, models.Certificate{
+		Name: "Client", Kind: models.CertificateClient, HostPattern: "127.0.0.1", Format: models.CertificatePEM,
+		CertPath: pki.clientCert, KeyPath: pki.clientKey,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Failed to register client certificate: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr, response := executeRequest(t, executor, models.Request{Method: models.MethodGet, URL: testServer.URL})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Request with registered certificates failed: %d %s", rr.Code, rr.Body.String())
+	}
+	if response.Body != "pumoide" {
+		t.Errorf("Server did not see the client certificate: got %q", response.Body)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/pumoide-api/certificates", nil)
+	rr = httptest.NewRecorder()
+	certificateHandler.ServeHTTP(rr, req)
+
+	var certificates []models.Certificate
+	if err := json.Unmarshal(rr.Body.Bytes(), &certificates); err != nil {
+		t.Fatalf("Failed to unmarshal certificates: %v", err)
+	}
+	if len(certificates) != 2 {
+		t.Errorf("Expected 2 certificates, got %d", len(certificates))
+	}
+}
+
+func TestCertificateHandler_RejectsMissingFiles(t *testing.T) {
+	storeDir := t.TempDir()
+	handler := &api.CertificateHandler{DefaultPath: storeDir, Logger: logger}
+
+	rr := registerCertificate(t, handler, models.Certificate{
+		Name: "Broken", Kind: models.CertificateClient, HostPattern: "*", Format: models.CertificatePEM,
+		CertPath: filepath.Join(storeDir, "missing.pem"), KeyPath: filepath.Join(storeDir, "missing-key.pem"),
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected missing files to be rejected, got %d", rr.Code)
+	}
+
+	rr = registerCertificate(t, handler, models.Certificate{Name: "No host", Kind: models.CertificateClient, Format: models.CertificatePEM})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected missing host pattern to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestCertificateHandler_RedactsPassphrase(t *testing.T) {
+	storeDir := t.TempDir()
+	handler := &api.CertificateHandler{DefaultPath: storeDir, Logger: logger}
+
+	cert, key := issueCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(4),
+		Subject:      pkix.Name{CommonName: "bundle"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, nil, nil)
+	pfx, err := pkcs12.Modern.Encode(key, cert, nil, "s3cret")
+	if err != nil {
+		t.Fatalf("Failed to encode bundle: %v", err)
+	}
+	pfxPath := filepath.Join(storeDir, "client.p12")
+	if err := os.WriteFile(pfxPath, pfx, 0600); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	rr := registerCertificate(t, handler, models.Certificate{
+		Name: "Bundle", Kind: models.CertificateClient, HostPattern: "*", Format: models.CertificatePKCS12,
+		PFXPath: pfxPath, Passphrase: "s3cret",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Failed to register bundle: %d %s", rr.Code, rr.Body.String())
+	}
+	if bytes.Contains(rr.Body.Bytes(), []byte("s3cret")) {
+		t.Errorf("Created certificate response holds the passphrase: %s", rr.Body.String())
+	}
+	var created models.Certificate
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal certificate: %v", err)
+	}
+
+	// The passphrase is kept when the update doesn't send it back
+	body, _ := json.Marshal(models.Certificate{
+		Name: "Renamed", Kind: models.CertificateClient, HostPattern: "*", Format: models.CertificatePKCS12, PFXPath: pfxPath,
+	})
+	req, _ := http.NewRequest(http.MethodPut, "/pumoide-api/certificates?id="+created.ID, bytes.NewBuffer(body))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Failed to update bundle: %d %s", rr.Code, rr.Body.String())
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/pumoide-api/certificates", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var certificates []struct {
+		Name          string `json:"name"`
+		Passphrase    string `json:"passphrase"`
+		HasPassphrase bool   `json:"hasPassphrase"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &certificates); err != nil {
+		t.Fatalf("Failed to unmarshal certificates: %v", err)
+	}
+	if len(certificates) != 1 || certificates[0].Name != "Renamed" || certificates[0].Passphrase != "" || !certificates[0].HasPassphrase {
+		t.Errorf("Unexpected certificates: %s", rr.Body.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/FedeBP/pumoide/backend/api"
@@ -180,6 +181,9 @@ func TestDeleteRequestFromCollection(t *testing.T) {
 	if status := rr.Code; status != http.StatusOK {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
+	if body := rr.Body.String(); body != "Request deleted successfully" {
+		t.Errorf("handler returned unexpected body: %q", body)
+	}
 
 	updatedCollection, err := models.LoadCollection(tempDir, "test1")
 	if err != nil {
@@ -188,6 +192,17 @@ func TestDeleteRequestFromCollection(t *testing.T) {
 	if len(updatedCollection.Requests) != 0 {
 		t.Errorf("request was not removed from collection")
 	}
+
+	req, _ = http.NewRequest("DELETE", "/pumoide-api/collections?action=unknown&id=test1", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("unknown action returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+	if _, err := models.LoadCollection(tempDir, "test1"); err != nil {
+		t.Errorf("unknown action should leave the collection in place: %v", err)
+	}
 }
 
 func TestUpdateCollection(t *testing.T) {
@@ -241,6 +256,54 @@ func TestUpdateCollection(t *testing.T) {
 	}
 }
 
+func TestSaveRequestsWithoutMethod(t *testing.T) {
+	tempDir, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	// The UI sends WebSocket and gRPC requests without a method
+	socket := models.Request{Name: "Live", Type: models.RequestWebSocket, URL: "wss://example.com/live"}
+	grpc := models.Request{Name: "Greet", Type: models.RequestGRPC, URL: "grpc://localhost:50051",
+		GRPC: &models.GRPCRequest{Source: models.GRPCSourceReflection, Service: "helloworld.Greeter", Method: "SayHello"}}
+
+	send := func(method string, url string, payload interface{}, expected int) *httptest.ResponseRecorder {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("Failed to marshal body: %v", err)
+		}
+		req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != expected {
+			t.Errorf("%s %s returned %v, want %v: %s", method, url, rr.Code, expected, rr.Body.String())
+		}
+		return rr
+	}
+
+	rr := send("POST", "/pumoide-api/collections", models.Collection{Name: "Realtime", Requests: []models.Request{socket, grpc}}, http.StatusCreated)
+	var created models.Collection
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	send("PUT", "/pumoide-api/collections?action=updateCollection&id="+created.ID,
+		models.Collection{Name: "Realtime", Requests: created.Requests}, http.StatusOK)
+	send("PUT", "/pumoide-api/collections?action=addRequest&id="+created.ID, socket, http.StatusCreated)
+	send("PUT", "/pumoide-api/collections?action=addRequest&id="+created.ID, grpc, http.StatusCreated)
+	send("PUT", "/pumoide-api/collections?action=addRequest&id="+created.ID,
+		models.Request{Name: "Bad", Method: "INVALID", URL: "http://example.com"}, http.StatusBadRequest)
+
+	saved, err := models.LoadCollection(tempDir, created.ID)
+	if err != nil {
+		t.Fatalf("Failed to load collection: %v", err)
+	}
+	if len(saved.Requests) != 4 {
+		t.Errorf("Unexpected saved requests: %+v", saved.Requests)
+	}
+}
+
 func TestDeleteCollection(t *testing.T) {
 	tempDir, handler, cleanup := setupTestEnvironment(t)
 	defer cleanup()
@@ -322,6 +385,14 @@ func TestImportCollection(t *testing.T) {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(rr.Body.Bytes(), &fields); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if _, ok := fields["report"]; ok {
+		t.Errorf("The report should only be returned when asked for")
+	}
+
 	if responseCollection.Requests[0].Method != models.MethodGet {
 		t.Errorf("Imported request has invalid method: got %v, want %v", responseCollection.Requests[0].Method, models.MethodGet)
 	}
@@ -372,7 +443,7 @@ func TestExportCollection(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var exportedCollection models.ExportedCollection
+	var exportedCollection models.PostmanCollection
 	err = json.Unmarshal(rr.Body.Bytes(), &exportedCollection)
 	if err != nil {
 		t.Fatalf("Failed to unmarshal response: %v", err)
@@ -386,3 +457,409 @@ func TestExportCollection(t *testing.T) {
 		t.Errorf("handler returned unexpected number of requests: got %v want %v", len(exportedCollection.Item), 1)
 	}
 }
+
+func TestImportCollectionWithVariables(t *testing.T) {
+	tempDir, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	handler.EnvironmentsPath = filepath.Join(tempDir, "environments")
+	if err := os.Mkdir(handler.EnvironmentsPath, 0755); err != nil {
+		t.Fatalf("Failed to create environments dir: %v", err)
+	}
+
+	importJSON := `{
+		"info": {"name": "Variables", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+		"variable": [{"key": "baseUrl", "value": "https://api.example.com"}],
+		"item": [
+			{"name": "Folder", "item": [{"name": "List", "request": {"method": "GET", "url": "{{baseUrl}}/items"}}]},
+			{"name": "Unknown", "request": {"method": "PROPFIND", "url": "{{baseUrl}}/items"}}
+		]
+	}`
+
+	req, err := http.NewRequest("POST", "/pumoide-api/collections?action=import&report=true", bytes.NewBufferString(importJSON))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusCreated, rr.Body.String())
+	}
+
+	var result models.ImportResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(result.Requests) != 1 || result.Requests[0].Name != "List" {
+		t.Errorf("Unexpected imported requests: %+v", result.Requests)
+	}
+	if len(result.Items) != 1 || result.Items[0].Folder == nil || result.Items[0].Folder.Name != "Folder" ||
+		!reflect.DeepEqual(result.Items[0].Folder.Items, []models.CollectionItem{{RequestID: result.Requests[0].ID}}) {
+		t.Errorf("Unexpected imported folders: %+v", result.Items)
+	}
+	if result.Report.Imported != 1 || result.Report.Skipped != 1 {
+		t.Errorf("Unexpected report: %+v", result.Report)
+	}
+
+	if _, err := models.LoadCollection(tempDir, result.ID); err != nil {
+		t.Errorf("Imported collection was not saved: %v", err)
+	}
+
+	if len(result.Environments) != 1 || result.Environments[0].ID == "" {
+		t.Fatalf("Expected the collection variables to be saved as an environment")
+	}
+	environment, err := models.LoadEnvironment(handler.EnvironmentsPath, result.Environments[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to load imported environment: %v", err)
+	}
+	if environment.Variables["baseUrl"] != "https://api.example.com" {
+		t.Errorf("Unexpected environment variables: %v", environment.Variables)
+	}
+}
+
+func TestImportCollectionVariablesFailure(t *testing.T) {
+	tempDir, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	// Environments can't be saved under a regular file
+	handler.EnvironmentsPath = filepath.Join(tempDir, "environments")
+	if err := os.WriteFile(handler.EnvironmentsPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	importJSON := `{
+		"info": {"name": "Variables", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+		"variable": [{"key": "baseUrl", "value": "https://api.example.com"}],
+		"item": [{"name": "List", "request": {"method": "GET", "url": "{{baseUrl}}/items"}}]
+	}`
+	req, err := http.NewRequest("POST", "/pumoide-api/collections?action=import", bytes.NewBufferString(importJSON))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+	}
+	if files, _ := filepath.Glob(filepath.Join(tempDir, "*.json")); len(files) != 0 {
+		t.Errorf("The collection of a failed import was left behind: %v", files)
+	}
+}
+
+func TestImportInvalidCollection(t *testing.T) {
+	_, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	req, err := http.NewRequest("POST", "/pumoide-api/collections?action=import", bytes.NewBufferString(`{"info": {}, "item": []}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestImportOpenAPICollection(t *testing.T) {
+	tempDir, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	handler.EnvironmentsPath = filepath.Join(tempDir, "environments")
+	if err := os.Mkdir(handler.EnvironmentsPath, 0755); err != nil {
+		t.Fatalf("Failed to create environments dir: %v", err)
+	}
+
+	document := `
+openapi: 3.0.0
+info:
+  title: Status
+  version: 1.0.0
+servers:
+  - url: https://status.example.com
+  - url: https://staging.status.example.com
+paths:
+  /status:
+    get:
+      summary: Get status
+`
+
+	req, err := http.NewRequest("POST", "/pumoide-api/collections?action=importOpenAPI", bytes.NewBufferString(document))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusCreated, rr.Body.String())
+	}
+
+	var result models.ImportResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(result.Requests) != 1 || result.Requests[0].URL != "{{baseUrl}}/status" {
+		t.Errorf("Unexpected imported requests: %+v", result.Requests)
+	}
+
+	files, err := filepath.Glob(filepath.Join(handler.EnvironmentsPath, "*.json"))
+	if err != nil {
+		t.Fatalf("Failed to list environments: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected an environment per server, got %d", len(files))
+	}
+}
+
+func TestExportOpenAPIDocument(t *testing.T) {
+	tempDir, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	handler.HistoryPath = filepath.Join(tempDir, "history")
+	if err := os.Mkdir(handler.HistoryPath, 0755); err != nil {
+		t.Fatalf("Failed to create history dir: %v", err)
+	}
+
+	request := models.Request{ID: "req1", Name: "Get status", Method: models.MethodGet, URL: "https://status.example.com/status"}
+	collection := models.Collection{ID: "test1", Name: "Status", Requests: []models.Request{request}}
+	if err := collection.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	entry := models.NewHistoryEntry(request, nil)
+	entry.Response = &models.ExecutionResponse{
+		StatusCode: http.StatusOK,
+		Headers:    []models.Header{{Key: "Content-Type", Value: "application/json"}},
+		Body:       `{"healthy": true}`,
+		Encoding:   models.EncodingText,
+	}
+	if err := entry.Save(handler.HistoryPath); err != nil {
+		t.Fatalf("Failed to save history entry: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", "/pumoide-api/collections?action=exportOpenAPI&id=test1&format=yaml", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusOK, rr.Body.String())
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/yaml" {
+		t.Errorf("handler returned unexpected content type: %s", contentType)
+	}
+
+	document, err := models.ParseOpenAPIDocument(rr.Body.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to parse exported document: %v", err)
+	}
+	if len(document.Servers) != 1 || document.Servers[0].URL != "https://status.example.com" {
+		t.Errorf("Unexpected servers: %+v", document.Servers)
+	}
+	get := document.Paths.Items["/status"].Get
+	if get == nil {
+		t.Fatalf("Missing operation: %+v", document.Paths)
+	}
+	if ok, exists := get.Responses["200"]; !exists || ok.Content["application/json"].Example == nil {
+		t.Errorf("The recorded response was not exported: %+v", get.Responses)
+	}
+}
+
+func TestImportHARCollection(t *testing.T) {
+	_, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	har := `{"log": {"version": "1.2", "creator": {"name": "WebInspector", "version": "537.36"}, "entries": [
+		{"startedDateTime": "2024-05-01T10:00:00.000Z", "request": {"method": "GET", "url": "https://api.example.com/users", "headers": []}, "response": {"status": 200, "content": {"mimeType": "application/json"}}},
+		{"startedDateTime": "2024-05-01T10:00:01.000Z", "request": {"method": "GET", "url": "https://cdn.example.com/logo.png", "headers": []}, "response": {"status": 200, "content": {"mimeType": "image/png"}}},
+		{"startedDateTime": "2024-05-01T10:00:02.000Z", "request": {"method": "GET", "url": "https://tracker.io/pixel", "headers": []}, "response": {"status": 200, "content": {"mimeType": "application/json"}}}
+	]}}`
+
+	req, err := http.NewRequest("POST", "/pumoide-api/collections?action=importHAR&domain=example.com&contentType=application/json", bytes.NewBufferString(har))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusCreated, rr.Body.String())
+	}
+
+	var result models.ImportResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(result.Requests) != 1 || result.Requests[0].URL != "https://api.example.com/users" {
+		t.Errorf("Unexpected imported requests: %+v", result.Requests)
+	}
+}
+
+func TestHTTPFileCollection(t *testing.T) {
+	tempDir, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	handler.EnvironmentsPath = filepath.Join(tempDir, "environments")
+	if err := os.Mkdir(handler.EnvironmentsPath, 0755); err != nil {
+		t.Fatalf("Failed to create environments dir: %v", err)
+	}
+
+	file := "@baseUrl = https://api.example.com\n\n### List users\nGET {{baseUrl}}/users\nAccept: application/json\n"
+	req, err := http.NewRequest("POST", "/pumoide-api/collections?action=importHTTP&name=Users&report=true", bytes.NewBufferString(file))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusCreated, rr.Body.String())
+	}
+
+	var result models.ImportResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result.Name != "Users" || len(result.Requests) != 1 || len(result.Environments) != 1 {
+		t.Fatalf("Unexpected import result: %+v", result)
+	}
+
+	req, err = http.NewRequest("GET", "/pumoide-api/collections?action=exportHTTP&id="+result.ID+"&env="+result.Environments[0].ID, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr = httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusOK, rr.Body.String())
+	}
+	expected := "@baseUrl = https://api.example.com\n\n### List users\nGET {{baseUrl}}/users\nAccept: application/json\n"
+	if rr.Body.String() != expected {
+		t.Errorf("Unexpected exported file:\n%s\nwant:\n%s", rr.Body.String(), expected)
+	}
+}
+
+func TestImportDetectsFormat(t *testing.T) {
+	_, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	export := `{"_type": "export", "__export_format": 4, "resources": [
+		{"_id": "wrk_1", "_type": "workspace", "name": "Users"},
+		{"_id": "req_1", "_type": "request", "parentId": "wrk_1", "name": "List users", "method": "GET", "url": "https://api.example.com/users"}
+	]}`
+	req, err := http.NewRequest("POST", "/pumoide-api/collections?action=import", bytes.NewBufferString(export))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rr := httptest.NewRecorder()
+
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusCreated, rr.Body.String())
+	}
+
+	var result models.ImportResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result.Name != "Users" || len(result.Requests) != 1 {
+		t.Errorf("Unexpected import result: %+v", result)
+	}
+
+	for _, format := range []string{"postman", "yaml"} {
+		req, err = http.NewRequest("POST", "/pumoide-api/collections?action=import&format="+format, bytes.NewBufferString(export))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		rr = httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("format %s: handler returned wrong status code: got %v want %v", format, status, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCollectionFolders(t *testing.T) {
+	_, handler, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	collection := models.Collection{ID: "pets", Name: "Pets", Requests: []models.Request{
+		{ID: "list", Name: "List pets", Method: models.MethodGet, URL: "https://example.com/pets"},
+		{ID: "create", Name: "Create pet", Method: models.MethodPost, URL: "https://example.com/pets"},
+	}}
+	if err := collection.Save(handler.DefaultPath); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	send := func(method string, target string, body string, expectedStatus int) *httptest.ResponseRecorder {
+		t.Helper()
+		req, err := http.NewRequest(method, target, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != expectedStatus {
+			t.Fatalf("%s %s returned wrong status code: got %v want %v: %s", method, target, rr.Code, expectedStatus, rr.Body.String())
+		}
+		return rr
+	}
+
+	rr := send("PUT", "/pumoide-api/collections?action=addFolder&id=pets", `{"name": "Admin"}`, http.StatusCreated)
+	var folder models.Folder
+	if err := json.Unmarshal(rr.Body.Bytes(), &folder); err != nil || folder.ID == "" {
+		t.Fatalf("Unexpected folder: %s", rr.Body.String())
+	}
+
+	send("PUT", "/pumoide-api/collections?action=updateFolder&id=pets&folderId="+folder.ID, `{"name": "Management", "description": "Admin only"}`, http.StatusOK)
+	send("PUT", "/pumoide-api/collections?action=updateFolder&id=pets&folderId="+folder.ID, `{"name": ""}`, http.StatusBadRequest)
+	send("PUT", "/pumoide-api/collections?action=moveItem&id=pets&itemId=create&parentId="+folder.ID, "", http.StatusOK)
+	send("PUT", "/pumoide-api/collections?action=moveItem&id=pets&itemId=create&parentId=missing", "", http.StatusNotFound)
+	send("PUT", "/pumoide-api/collections?action=moveItem&id=pets&itemId=list&position=first", "", http.StatusBadRequest)
+	send("PUT", "/pumoide-api/collections?action=addRequest&id=pets&folderId="+folder.ID, `{"name": "Delete pet", "method": "DELETE", "url": "https://example.com/pets/1"}`, http.StatusCreated)
+	send("PUT", "/pumoide-api/collections?action=reorderItems&id=pets", `["list"]`, http.StatusBadRequest)
+	rr = send("PUT", "/pumoide-api/collections?action=reorderItems&id=pets", `["`+folder.ID+`", "list"]`, http.StatusOK)
+
+	var updated models.Collection
+	if err := json.Unmarshal(rr.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(updated.Items) != 2 || updated.Items[0].Folder == nil || updated.Items[1].RequestID != "list" {
+		t.Fatalf("Unexpected items: %+v", updated.Items)
+	}
+	saved := updated.Items[0].Folder
+	if saved.Name != "Management" || saved.Description != "Admin only" || len(saved.Items) != 2 || saved.Items[0].RequestID != "create" {
+		t.Errorf("Unexpected folder: %+v", saved)
+	}
+
+	send("DELETE", "/pumoide-api/collections?action=deleteFolder&collectionId=pets&folderId="+folder.ID, "", http.StatusOK)
+	send("DELETE", "/pumoide-api/collections?action=deleteFolder&collectionId=pets&folderId="+folder.ID, "", http.StatusNotFound)
+
+	loaded, err := models.LoadCollection(handler.DefaultPath, "pets")
+	if err != nil {
+		t.Fatalf("Failed to load collection: %v", err)
+	}
+	if len(loaded.Requests) != 1 || !reflect.DeepEqual(loaded.Items, []models.CollectionItem{{RequestID: "list"}}) {
+		t.Errorf("Unexpected collection after deleting the folder: %+v", loaded)
+	}
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func newSessionServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/", HttpOnly: true})
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(cookie.Value))
+	})
+	return httptest.NewServer(mux)
+}
+
+func executeInEnvironment(t *testing.T, handler http.Handler, envID string, request models.Request) models.ExecutionResponse {
+	body, _ := json.Marshal(request)
+	target := "/pumoide-api/execute"
+	if envID != "" {
+		target += "?env=" + envID
+	}
+	req, _ := http.NewRequest(http.MethodPost, target, bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var response models.ExecutionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v (%s)", err, rr.Body.String())
+	}
+	return response
+}
+
+func TestCookieJar_PersistsSessionPerEnvironment(t *testing.T) {
+	testServer := newSessionServer()
+	defer testServer.Close()
+
+	envDir := t.TempDir()
+	cookiesDir := t.TempDir()
+	for _, id := range []string{"staging", "prod"} {
+		env := models.Environment{ID: id, Name: id}
+		if err := env.Save(envDir); err != nil {
+			t.Fatalf("Failed to save environment: %v", err)
+		}
+	}
+
+	executor := &api.RequestHandler{
+		Client:          &http.Client{Timeout: 30 * time.Second},
+		EnvironmentPath: envDir,
+		CookiesPath:     cookiesDir,
+		Logger:          logger,
+	}
+
+	executeInEnvironment(t, executor, "staging", models.Request{Method: models.MethodPost, URL: testServer.URL + "/login"})
+
+	me := executeInEnvironment(t, executor, "staging", models.Request{Method: models.MethodGet, URL: testServer.URL + "/me"})
+	if me.StatusCode != http.StatusOK || me.Body != "s3cr3t" {
+		t.Errorf("Session cookie was not sent on the next call: got %d %q", me.StatusCode, me.Body)
+	}
+
+	other := executeInEnvironment(t, executor, "prod", models.Request{Method: models.MethodGet, URL: testServer.URL + "/me"})
+	if other.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Cookies leaked to another environment: got %d", other.StatusCode)
+	}
+
+	cookieHandler := &api.CookieHandler{DefaultPath: cookiesDir, Logger: logger}
+
+	req, _ := http.NewRequest(http.MethodGet, "/pumoide-api/cookies?env=staging&domain=127.0.0.1", nil)
+	rr := httptest.NewRecorder()
+	cookieHandler.ServeHTTP(rr, req)
+
+	var cookies []models.Cookie
+	if err := json.Unmarshal(rr.Body.Bytes(), &cookies); err != nil {
+		t.Fatalf("Failed to unmarshal cookies: %v", err)
+	}
+	if len(cookies) != 1 || cookies[0].Name != "session" || !cookies[0].HostOnly {
+		t.Fatalf("Unexpected stored cookies: %+v", cookies)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, "/pumoide-api/cookies?env=staging&domain=127.0.0.1", nil)
+	rr = httptest.NewRecorder()
+	cookieHandler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Failed to delete cookies: %d", rr.Code)
+	}
+
+	me = executeInEnvironment(t, executor, "staging", models.Request{Method: models.MethodGet, URL: testServer.URL + "/me"})
+	if me.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Deleted cookie was still sent: got %d", me.StatusCode)
+	}
+}
+
+func TestCookieHandler_EditCookie(t *testing.T) {
+	testServer := newSessionServer()
+	defer testServer.Close()
+
+	cookiesDir := t.TempDir()
+	cookieHandler := &api.CookieHandler{DefaultPath: cookiesDir, Logger: logger}
+
+	body, _ := json.Marshal(models.Cookie{Name: "session", Value: "manual", Domain: "127.0.0.1"})
+	req, _ := http.NewRequest(http.MethodPut, "/pumoide-api/cookies", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	cookieHandler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Failed to put cookie: %d %s", rr.Code, rr.Body.String())
+	}
+
+	executor := &api.RequestHandler{
+		Client:      &http.Client{Timeout: 30 * time.Second},
+		CookiesPath: cookiesDir,
+		Logger:      logger,
+	}
+
+	me := executeInEnvironment(t, executor, "", models.Request{Method: models.MethodGet, URL: testServer.URL + "/me"})
+	if me.Body != "manual" {
+		t.Errorf("Cookie set by hand was not sent: got %d %q", me.StatusCode, me.Body)
+	}
+
+	body, _ = json.Marshal(models.Cookie{Name: "session", Value: "missing domain"})
+	req, _ = http.NewRequest(http.MethodPut, "/pumoide-api/cookies", bytes.NewBuffer(body))
+	rr = httptest.NewRecorder()
+	cookieHandler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected cookie without domain to be rejected, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestCurlHandler(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "curl_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	handler := &api.CurlHandler{CollectionsPath: tempDir, EnvironmentsPath: tempDir, Logger: logger}
+
+	req := httptest.NewRequest(http.MethodPost, "/pumoide-api/curl", strings.NewReader(`curl -H 'X-Token: {{token}}' {{baseUrl}}/status`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var result models.RequestImportResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if result.Request.URL != "{{baseUrl}}/status" || result.Request.Method != models.MethodGet {
+		t.Errorf("Unexpected imported request: %+v", result.Request)
+	}
+
+	collection := models.Collection{ID: "collection1", Name: "Status", Requests: []models.Request{result.Request}}
+	if err := collection.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+	env := models.Environment{ID: "env1", Variables: map[string]string{"baseUrl": "https://status.example.com", "token": "abc"}}
+	if err := env.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save environment: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/pumoide-api/curl?collectionId=collection1&env=env1&requestId="+result.Request.ID, nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	command := rr.Body.String()
+	if !strings.HasPrefix(command, "curl https://status.example.com/status") || !strings.Contains(command, "--header 'X-Token: abc'") {
+		t.Errorf("Unexpected command: %s", command)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/pumoide-api/curl", strings.NewReader("wget https://example.com"))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Unexpected status code for an invalid command: %v", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/pumoide-api/curl?collectionId=collection1&requestId=missing", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Unexpected status code for a missing request: %v", rr.Code)
+	}
+}
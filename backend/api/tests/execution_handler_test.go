@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func newHangingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+}
+
+func waitForExecution(t *testing.T, registry *api.ExecutionRegistry, id string) {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, running := range registry.List() {
+			if running == id {
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Execution %s never started", id)
+}
+
+func TestExecutionHandler_Cancel(t *testing.T) {
+	testServer := newHangingServer()
+	defer testServer.Close()
+
+	registry := api.NewExecutionRegistry()
+	executor := &api.RequestHandler{Client: &http.Client{Timeout: 30 * time.Second}, Executions: registry, Logger: logger}
+	executionHandler := &api.ExecutionHandler{Registry: registry, Logger: logger}
+
+	body, _ := json.Marshal(models.Request{Method: models.MethodGet, URL: testServer.URL})
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute?executionId=exec-1", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	finished := make(chan struct{})
+	started := time.Now()
+	go func() {
+		executor.ServeHTTP(rr, req)
+		close(finished)
+	}()
+
+	waitForExecution(t, registry, "exec-1")
+
+	cancelReq, _ := http.NewRequest(http.MethodDelete, "/pumoide-api/executions?id=exec-1", nil)
+	cancelRR := httptest.NewRecorder()
+	executionHandler.ServeHTTP(cancelRR, cancelReq)
+	if cancelRR.Code != http.StatusOK {
+		t.Fatalf("Cancel returned wrong status code: got %v want %v", cancelRR.Code, http.StatusOK)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Execution was not cancelled")
+	}
+
+	if time.Since(started) > 2*time.Second {
+		t.Errorf("Cancelled execution took too long")
+	}
+	if rr.Code != api.StatusClientClosedRequest {
+		t.Errorf("Cancelled execution returned wrong status code: got %v want %v", rr.Code, api.StatusClientClosedRequest)
+	}
+	if len(registry.List()) != 0 {
+		t.Errorf("Cancelled execution is still registered: %v", registry.List())
+	}
+
+	cancelRR = httptest.NewRecorder()
+	executionHandler.ServeHTTP(cancelRR, cancelReq)
+	if cancelRR.Code != http.StatusNotFound {
+		t.Errorf("Cancelling a finished execution returned wrong status code: got %v want %v", cancelRR.Code, http.StatusNotFound)
+	}
+}
+
+func TestExecution_CancelledOnClientDisconnect(t *testing.T) {
+	testServer := newHangingServer()
+	defer testServer.Close()
+
+	executor := &api.RequestHandler{Client: &http.Client{Timeout: 30 * time.Second}, Logger: logger}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	body, _ := json.Marshal(models.Request{Method: models.MethodGet, URL: testServer.URL})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/pumoide-api/execute", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	started := time.Now()
+	executor.ServeHTTP(rr, req)
+
+	if time.Since(started) > 2*time.Second {
+		t.Errorf("Execution was not stopped when the client disconnected")
+	}
+	if rr.Code != api.StatusClientClosedRequest {
+		t.Errorf("Disconnected execution returned wrong status code: got %v want %v", rr.Code, api.StatusClientClosedRequest)
+	}
+}
+
+func TestExecution_DuplicateID(t *testing.T) {
+	registry := api.NewExecutionRegistry()
+
+	_, _, done, err := registry.Start(context.Background(), "same")
+	if err != nil {
+		t.Fatalf("Failed to start execution: %v", err)
+	}
+	defer done()
+
+	if _, _, _, err := registry.Start(context.Background(), "same"); err == nil {
+		t.Errorf("Starting an execution with a running ID should fail")
+	}
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+const testIntrospection = `{
+  "__schema": {
+    "queryType": {"name": "Query"},
+    "mutationType": null,
+    "subscriptionType": null,
+    "types": [
+      {"kind": "OBJECT", "name": "Query", "fields": [
+        {"name": "user", "args": [{"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID"}}, "defaultValue": null}],
+         "type": {"kind": "OBJECT", "name": "User"}},
+        {"name": "users", "args": [{"name": "first", "type": {"kind": "SCALAR", "name": "Int"}, "defaultValue": "10"}],
+         "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "LIST", "name": null, "ofType": {"kind": "OBJECT", "name": "User"}}}}
+      ], "interfaces": []},
+      {"kind": "OBJECT", "name": "User", "fields": [
+        {"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID"}}},
+        {"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+        {"name": "role", "args": [], "type": {"kind": "ENUM", "name": "Role"}}
+      ], "interfaces": []},
+      {"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "MEMBER"}]},
+      {"kind": "SCALAR", "name": "ID"},
+      {"kind": "SCALAR", "name": "Int"},
+      {"kind": "SCALAR", "name": "String"},
+      {"kind": "SCALAR", "name": "Boolean"},
+      {"kind": "OBJECT", "name": "__Schema", "fields": []}
+    ],
+    "directives": [
+      {"name": "include", "locations": ["FIELD"], "args": [{"name": "if", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "Boolean"}}}]},
+      {"name": "cached", "locations": ["FIELD", "QUERY"], "args": []}
+    ]
+  }
+}`
+
+func newGraphQLServer(t *testing.T, payloads *[]models.GraphQLPayload) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload models.GraphQLPayload
+		if r.Method == http.MethodGet {
+			payload.Query = r.URL.Query().Get("query")
+			payload.Variables = json.RawMessage(r.URL.Query().Get("variables"))
+			payload.OperationName = r.URL.Query().Get("operationName")
+		} else {
+			if r.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("Expected Content-Type: application/json, got %s", r.Header.Get("Content-Type"))
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("Failed to decode GraphQL payload: %v", err)
+			}
+		}
+		*payloads = append(*payloads, payload)
+
+		w.Header().Set("Content-Type", "application/json")
+		if payload.OperationName == "IntrospectionQuery" {
+			_, _ = w.Write([]byte(`{"data": ` + testIntrospection + `}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"user": {"id": "1"}}}`))
+	}))
+}
+
+func TestRequestHandler_GraphQLBody(t *testing.T) {
+	var payloads []models.GraphQLPayload
+	testServer := newGraphQLServer(t, &payloads)
+	defer testServer.Close()
+
+	handler := newRequestHandler(t.TempDir())
+	body := &models.RequestBody{
+		Mode: models.BodyGraphQL,
+		GraphQL: &models.GraphQLBody{
+			Query:         "query GetUser($id: ID!) { user(id: $id) { id } }",
+			Variables:     `{"id": "1"}`,
+			OperationName: "GetUser",
+		},
+	}
+
+	for _, method := range []models.Method{models.MethodPost, models.MethodGet} {
+		rr, _ := executeRequest(t, handler, models.Request{Method: method, URL: testServer.URL, BodyConfig: body})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v: %s", method, rr.Code, http.StatusOK, rr.Body.String())
+		}
+	}
+
+	for i, payload := range payloads {
+		var variables map[string]string
+		if err := json.Unmarshal(payload.Variables, &variables); err != nil {
+			t.Fatalf("Failed to unmarshal variables of payload %d: %v", i, err)
+		}
+		if payload.Query != body.GraphQL.Query || payload.OperationName != "GetUser" || variables["id"] != "1" {
+			t.Errorf("Unexpected GraphQL payload %d: %+v", i, payload)
+		}
+	}
+
+	body.GraphQL.Variables = "{not json"
+	rr, _ := executeRequest(t, handler, models.Request{Method: models.MethodPost, URL: testServer.URL, BodyConfig: body})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Invalid variables should be rejected: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGraphQLHandler_IntrospectAndValidate(t *testing.T) {
+	var payloads []models.GraphQLPayload
+	testServer := newGraphQLServer(t, &payloads)
+	defer testServer.Close()
+
+	graphqlHandler := &api.GraphQLHandler{
+		DefaultPath: t.TempDir(),
+		Executor:    &api.RequestHandler{Client: &http.Client{Timeout: 30 * time.Second}, Logger: logger},
+		Logger:      logger,
+	}
+
+	serve := func(method, target string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, target, bytes.NewBuffer(data))
+		rr := httptest.NewRecorder()
+		graphqlHandler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	schemaURL := "/pumoide-api/graphql?url=" + url.QueryEscape(testServer.URL)
+	if rr := serve(http.MethodGet, schemaURL, nil); rr.Code != http.StatusNotFound {
+		t.Errorf("Schema should not be cached yet: got %v", rr.Code)
+	}
+
+	rr := serve(http.MethodPost, "/pumoide-api/graphql?action=introspect", models.Request{URL: testServer.URL})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Introspection failed: %v %s", rr.Code, rr.Body.String())
+	}
+
+	rr = serve(http.MethodGet, schemaURL, nil)
+	var schema models.GraphQLSchema
+	if err := json.Unmarshal(rr.Body.Bytes(), &schema); err != nil {
+		t.Fatalf("Failed to unmarshal schema: %v", err)
+	}
+	if schema.URL != testServer.URL || len(schema.Introspection) == 0 {
+		t.Errorf("Unexpected cached schema: %+v", schema)
+	}
+
+	testCases := []struct {
+		query string
+		valid bool
+	}{
+		{`query { user(id: "1") { id name role } }`, true},
+		{`query { users @cached { id } }`, true},
+		{`query { users(first: 5) { id @include(if: true) } }`, true},
+		{`query { user { id } }`, false},
+		{`query { user(id: "1") { email } }`, false},
+	}
+
+	for _, tc := range testCases {
+		request := models.Request{
+			URL:        testServer.URL,
+			BodyConfig: &models.RequestBody{Mode: models.BodyGraphQL, GraphQL: &models.GraphQLBody{Query: tc.query}},
+		}
+		rr := serve(http.MethodPost, "/pumoide-api/graphql?action=validate", request)
+		var result models.GraphQLValidationResult
+		if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to unmarshal validation result: %v: %s", err, rr.Body.String())
+		}
+		if result.Valid != tc.valid {
+			t.Errorf("Query %q: expected valid=%v, got %+v", tc.query, tc.valid, result)
+		}
+	}
+
+	if rr := serve(http.MethodDelete, schemaURL, nil); rr.Code != http.StatusOK {
+		t.Errorf("Failed to delete schema: %v", rr.Code)
+	}
+	if rr := serve(http.MethodDelete, schemaURL, nil); rr.Code != http.StatusNotFound {
+		t.Errorf("Deleting a missing schema should return 404, got %v", rr.Code)
+	}
+}
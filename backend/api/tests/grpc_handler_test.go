@@ -0,0 +1,339 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testProto = `syntax = "proto3";
+
+package echo;
+
+message EchoRequest {
+  string text = 1;
+  int32 repeat = 2;
+}
+
+message EchoReply {
+  string text = 1;
+  int32 index = 2;
+}
+
+service Echo {
+  rpc Say(EchoRequest) returns (EchoReply);
+  rpc Repeat(EchoRequest) returns (stream EchoReply);
+  rpc Collect(stream EchoRequest) returns (EchoReply);
+}
+`
+
+// newGRPCServer serves the echo service from testProto, with server reflection enabled.
+func newGRPCServer(t *testing.T, protoPath string) string {
+	compiler := protocompile.Compiler{Resolver: &protocompile.SourceResolver{ImportPaths: []string{filepath.Dir(protoPath)}}}
+	files, err := compiler.Compile(context.Background(), filepath.Base(protoPath))
+	if err != nil {
+		t.Fatalf("Failed to parse proto file: %v", err)
+	}
+	service := files[0].Services().ByName("Echo")
+	say := service.Methods().ByName("Say")
+	repeat := service.Methods().ByName("Repeat")
+
+	field := func(message *dynamicpb.Message, name protoreflect.Name) protoreflect.Value {
+		return message.Get(message.Descriptor().Fields().ByName(name))
+	}
+	reply := func(method protoreflect.MethodDescriptor, text string, index int32) *dynamicpb.Message {
+		message := dynamicpb.NewMessage(method.Output())
+		message.Set(method.Output().Fields().ByName("text"), protoreflect.ValueOfString(text))
+		message.Set(method.Output().Fields().ByName("index"), protoreflect.ValueOfInt32(index))
+		return message
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "echo.Echo",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Say",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				md, _ := metadata.FromIncomingContext(ctx)
+				if strings.Join(md.Get("authorization"), "") != "Bearer secret" {
+					return nil, status.Error(codes.Unauthenticated, "missing token")
+				}
+				request := dynamicpb.NewMessage(say.Input())
+				if err := dec(request); err != nil {
+					return nil, err
+				}
+				_ = grpc.SetHeader(ctx, metadata.Pairs("x-served-by", "echo"))
+				_ = grpc.SetTrailer(ctx, metadata.Pairs("x-request-text", field(request, "text").String()))
+				return reply(say, "echo: "+field(request, "text").String(), 0), nil
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Repeat",
+			ServerStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				request := dynamicpb.NewMessage(repeat.Input())
+				if err := stream.RecvMsg(request); err != nil {
+					return err
+				}
+				for i := int32(0); i < int32(field(request, "repeat").Int()); i++ {
+					if err := stream.SendMsg(reply(repeat, field(request, "text").String(), i)); err != nil {
+						return err
+					}
+				}
+				return status.Error(codes.ResourceExhausted, "no more repeats")
+			},
+		}, {
+			StreamName:    "Collect",
+			ClientStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				return status.Error(codes.Unimplemented, "not implemented")
+			},
+		}},
+		Metadata: filepath.Base(protoPath),
+	}, struct{}{})
+
+	registry := new(protoregistry.Files)
+	if err := registry.RegisterFile(files[0]); err != nil {
+		t.Fatalf("Failed to register proto file: %v", err)
+	}
+	reflectionpb.RegisterServerReflectionServer(server, reflection.NewServerV1(reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: registry,
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestGRPCHandler(t *testing.T) {
+	protoPath := filepath.Join(t.TempDir(), "echo.proto")
+	if err := os.WriteFile(protoPath, []byte(testProto), 0644); err != nil {
+		t.Fatalf("Failed to write proto file: %v", err)
+	}
+	address := newGRPCServer(t, protoPath)
+
+	handler := &api.GRPCHandler{
+		Executor: &api.RequestHandler{Client: &http.Client{Timeout: 30 * time.Second}, Executions: api.NewExecutionRegistry(), Logger: logger},
+		Logger:   logger,
+	}
+
+	serve := func(query string, request models.Request) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(request)
+		req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/grpc?"+query, bytes.NewBuffer(data))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	sources := map[string]*models.GRPCRequest{
+		"reflection": {Source: models.GRPCSourceReflection},
+		"proto":      {Source: models.GRPCSourceProto, ProtoFiles: []string{protoPath}},
+	}
+
+	for name, source := range sources {
+		request := models.Request{Type: models.RequestGRPC, URL: "grpc://" + address, GRPC: source}
+
+		rr := serve("action=services", request)
+		var services []models.GRPCService
+		if err := json.Unmarshal(rr.Body.Bytes(), &services); err != nil {
+			t.Fatalf("%s: failed to decode services: %v: %s", name, err, rr.Body.String())
+		}
+		if len(services) != 1 || services[0].Name != "echo.Echo" || len(services[0].Methods) != 3 {
+			t.Fatalf("%s: unexpected services: %+v", name, services)
+		}
+		if !services[0].Methods[1].ServerStreaming || !strings.Contains(string(services[0].Methods[0].RequestTemplate), `"repeat"`) {
+			t.Errorf("%s: unexpected methods: %+v", name, services[0].Methods)
+		}
+
+		grpcConfig := *source
+		grpcConfig.Service, grpcConfig.Method, grpcConfig.Message = "echo.Echo", "Say", `{"text": "hello"}`
+		request.GRPC = &grpcConfig
+		request.Auth = &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "secret"}}
+
+		rr = serve("action=invoke", request)
+		var response models.GRPCResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: failed to decode response: %v: %s", name, err, rr.Body.String())
+		}
+		if response.Status.Name != "OK" || len(response.Messages) != 1 || !strings.Contains(string(response.Messages[0]), "echo: hello") {
+			t.Errorf("%s: unexpected unary response: %+v", name, response)
+		}
+		if !containsHeader(response.Headers, "x-served-by", "echo") || !containsHeader(response.Trailers, "x-request-text", "hello") {
+			t.Errorf("%s: missing headers or trailers: %+v %+v", name, response.Headers, response.Trailers)
+		}
+	}
+
+	request := models.Request{
+		Type: models.RequestGRPC,
+		URL:  "grpc://" + address,
+		GRPC: &models.GRPCRequest{Source: models.GRPCSourceReflection, Service: "echo.Echo", Method: "Say", Message: `{"text": "hello"}`},
+	}
+	rr := serve("action=invoke", request)
+	var response models.GRPCResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &response)
+	if rr.Code != http.StatusOK || response.Status.Code != int(codes.Unauthenticated) || response.Status.Message != "missing token" {
+		t.Errorf("Failed calls should report their status: %v %+v", rr.Code, response)
+	}
+
+	request.GRPC.Method, request.GRPC.Message = "Repeat", `{"text": "tick", "repeat": 3}`
+	rr = serve("action=invoke&stream=true", request)
+	var names []string
+	for _, event := range readStreamEvents(t, rr.Body.String()) {
+		names = append(names, event.name)
+		if event.name == "done" {
+			_ = json.Unmarshal([]byte(event.data), &response)
+		}
+	}
+	if strings.Join(names, ",") != "started,message,message,message,done" || response.Status.Name != "ResourceExhausted" {
+		t.Errorf("Unexpected streamed call: %v %+v", names, response.Status)
+	}
+
+	request.GRPC.Method = "Collect"
+	if rr := serve("action=invoke", request); rr.Code != http.StatusBadRequest {
+		t.Errorf("Client streaming calls should be rejected, got %v", rr.Code)
+	}
+
+	request.GRPC.Method, request.GRPC.Message = "Say", `{"unknown": true}`
+	if rr := serve("action=invoke", request); rr.Code != http.StatusBadRequest {
+		t.Errorf("Invalid messages should be rejected, got %v", rr.Code)
+	}
+
+	request.GRPC.Method = "Missing"
+	if rr := serve("action=invoke", request); rr.Code != http.StatusNotFound {
+		t.Errorf("Unknown methods should return 404, got %v", rr.Code)
+	}
+}
+
+func containsHeader(headers []models.Header, key, value string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header.Key, key) && header.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// newConnectProxy tunnels the CONNECT requests carrying the test credentials, counting them.
+func newConnectProxy(t *testing.T, tunnels *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") != expected {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, buffered, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Failed to hijack connection: %v", err)
+			return
+		}
+		atomic.AddInt32(tunnels, 1)
+		_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+		go func() {
+			_, _ = io.Copy(upstream, buffered)
+			_ = upstream.Close()
+		}()
+		_, _ = io.Copy(conn, upstream)
+		_ = conn.Close()
+	}))
+}
+
+func TestGRPCHandler_ProxyAndAuth(t *testing.T) {
+	protoPath := filepath.Join(t.TempDir(), "echo.proto")
+	if err := os.WriteFile(protoPath, []byte(testProto), 0644); err != nil {
+		t.Fatalf("Failed to write proto file: %v", err)
+	}
+	address := newGRPCServer(t, protoPath)
+
+	var tunnels int32
+	proxyServer := newConnectProxy(t, &tunnels)
+	defer proxyServer.Close()
+
+	settingsDir := t.TempDir()
+	proxySettings := models.ProxySettings{URL: proxyServer.URL, Username: "user", Password: "pass"}
+	if err := proxySettings.Save(settingsDir); err != nil {
+		t.Fatalf("Failed to save proxy settings: %v", err)
+	}
+
+	handler := &api.GRPCHandler{
+		Executor: &api.RequestHandler{
+			Client:       &http.Client{Timeout: 30 * time.Second},
+			SettingsPath: settingsDir,
+			Executions:   api.NewExecutionRegistry(),
+			Logger:       logger,
+		},
+		Logger: logger,
+	}
+	invoke := func(auth *models.Auth) *httptest.ResponseRecorder {
+		request := models.Request{
+			Type: models.RequestGRPC,
+			URL:  "grpc://" + address,
+			Auth: auth,
+			GRPC: &models.GRPCRequest{Source: models.GRPCSourceReflection, Service: "echo.Echo", Method: "Say", Message: `{"text": "hello"}`},
+		}
+		data, _ := json.Marshal(request)
+		req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/grpc?action=invoke", bytes.NewBuffer(data))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The API key header is the one the server expects
+	rr := invoke(&models.Auth{Type: models.AuthAPIKey, Params: map[string]string{"key": "Authorization", "value": "Bearer secret", "in": "header"}})
+	var response models.GRPCResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v: %s", err, rr.Body.String())
+	}
+	if response.Status.Name != "OK" || len(response.Messages) != 1 {
+		t.Errorf("Unexpected response through the proxy: %+v", response)
+	}
+	if atomic.LoadInt32(&tunnels) == 0 {
+		t.Error("The call did not go through the proxy")
+	}
+
+	for _, auth := range []*models.Auth{
+		{Type: models.AuthAWSSigV4, Params: map[string]string{"access_key": "AKIA", "secret_key": "secret"}},
+		{Type: models.AuthDigest, Params: map[string]string{"username": "ana", "password": "secret"}},
+		{Type: models.AuthAPIKey, Params: map[string]string{"key": "token", "value": "secret", "in": "query"}},
+	} {
+		if rr := invoke(auth); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s authentication should be refused, got %v", auth.Type, rr.Code)
+		}
+	}
+}
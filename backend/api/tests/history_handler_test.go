@@ -0,0 +1,265 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func setupHistoryEnvironment(t *testing.T) (string, *api.RequestHandler, *api.HistoryHandler) {
+	tempDir := t.TempDir()
+
+	executor := &api.RequestHandler{
+		Client:          &http.Client{Timeout: 30 * time.Second},
+		EnvironmentPath: tempDir,
+		HistoryPath:     tempDir,
+		Logger:          logger,
+	}
+
+	return tempDir, executor, &api.HistoryHandler{DefaultPath: tempDir, Executor: executor, Logger: logger}
+}
+
+func executeThroughHandler(t *testing.T, handler http.Handler, request models.Request) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Execute returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestHistoryRecordAndSearch(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer testServer.Close()
+
+	_, executor, handler := setupHistoryEnvironment(t)
+
+	executeThroughHandler(t, executor, models.Request{Name: "Users", Method: models.MethodGet, URL: testServer.URL + "/users"})
+	executeThroughHandler(t, executor, models.Request{Name: "Missing", Method: models.MethodPost, URL: testServer.URL + "/missing", Body: "payload"})
+
+	req, _ := http.NewRequest(http.MethodGet, "/pumoide-api/history", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var entries []models.HistoryEntry
+	if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal history: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 history entries, got %d", len(entries))
+	}
+	if entries[0].Request.Name != "Missing" {
+		t.Errorf("History should be sorted newest first, got %s first", entries[0].Request.Name)
+	}
+	if entries[0].ResolvedRequest.Body != "payload" {
+		t.Errorf("Resolved request body was not recorded: got %q", entries[0].ResolvedRequest.Body)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/pumoide-api/history?status=404", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	entries = nil
+	if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal history: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Response.StatusCode != http.StatusNotFound {
+		t.Errorf("Status filter returned unexpected entries: %+v", entries)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/pumoide-api/history?q=users", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	entries = nil
+	if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal history: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Request.Name != "Users" {
+		t.Errorf("Search returned unexpected entries: %+v", entries)
+	}
+}
+
+func TestHistoryPagination(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer testServer.Close()
+
+	_, executor, handler := setupHistoryEnvironment(t)
+	executor.HistoryRetention = 3
+
+	for _, name := range []string{"First", "Second", "Third", "Fourth"} {
+		executeThroughHandler(t, executor, models.Request{Name: name, Method: models.MethodGet, URL: testServer.URL})
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/pumoide-api/history?limit=2&offset=1", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var entries []models.HistoryEntry
+	if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Failed to unmarshal history: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Request.Name != "Third" || entries[1].Request.Name != "Second" {
+		t.Errorf("Unexpected page: %+v", entries)
+	}
+	// The oldest entry is dropped beyond the retention
+	if total := rr.Header().Get("X-Total-Count"); total != "3" {
+		t.Errorf("Unexpected total count: %s", total)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/pumoide-api/history?offset=-1", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Negative offset returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHistoryReplay(t *testing.T) {
+	var calls int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(r.Header.Get("X-Token")))
+	}))
+	defer testServer.Close()
+
+	tempDir, _, handler := setupHistoryEnvironment(t)
+
+	entry := models.NewHistoryEntry(models.Request{
+		Name:    "Replay",
+		Method:  models.MethodGet,
+		URL:     testServer.URL,
+		Headers: []models.Header{{Key: "X-Token", Value: "{{token}}"}},
+	}, &models.Environment{ID: "env1", Name: "Env", Variables: map[string]string{"token": "secret"}})
+	if err := entry.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save history entry: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/history?action=replay&id="+entry.ID, nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Replay returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var response models.ExecutionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Body != "secret" {
+		t.Errorf("Replay did not use the recorded environment: got body %q", response.Body)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected upstream to be called once, got %d", calls)
+	}
+
+	if _, total, _ := models.ListHistory(tempDir, models.HistoryFilter{}); total != 2 {
+		t.Errorf("Replay should be recorded as a new history entry: got %d entries", total)
+	}
+}
+
+func TestHistoryDelete(t *testing.T) {
+	tempDir, _, handler := setupHistoryEnvironment(t)
+
+	entry := models.NewHistoryEntry(models.Request{Name: "Delete me", Method: models.MethodGet, URL: "http://example.com"}, nil)
+	if err := entry.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save history entry: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, "/pumoide-api/history?id="+entry.ID, nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Delete returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, entry.ID+".json")); !os.IsNotExist(err) {
+		t.Errorf("History entry file was not deleted")
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, "/pumoide-api/history?id="+entry.ID, nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Deleting a missing entry returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestHistoryExportHAR(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer testServer.Close()
+
+	_, executor, handler := setupHistoryEnvironment(t)
+
+	executeThroughHandler(t, executor, models.Request{Name: "Users", Method: models.MethodGet, URL: testServer.URL + "/users?page=2"})
+	time.Sleep(10 * time.Millisecond)
+	executeThroughHandler(t, executor, models.Request{
+		Name:       "Create user",
+		Method:     models.MethodPost,
+		URL:        testServer.URL + "/users",
+		BodyConfig: &models.RequestBody{Mode: models.BodyJSON, Raw: `{"name":"Ana"}`},
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/pumoide-api/history?action=exportHAR", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Export returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var har models.HAR
+	if err := json.Unmarshal(rr.Body.Bytes(), &har); err != nil {
+		t.Fatalf("Failed to unmarshal archive: %v", err)
+	}
+	if har.Log.Version != models.HARVersion || len(har.Log.Entries) != 2 {
+		t.Fatalf("Unexpected archive: version %s, %d entries", har.Log.Version, len(har.Log.Entries))
+	}
+
+	get, post := har.Log.Entries[0], har.Log.Entries[1]
+	if get.Request.Method != http.MethodGet || post.Request.Method != http.MethodPost {
+		t.Errorf("Entries should be in the order they were sent: %s, %s", get.Request.Method, post.Request.Method)
+	}
+	if len(get.Request.QueryString) != 1 || get.Request.QueryString[0] != (models.HARNameValue{Name: "page", Value: "2"}) {
+		t.Errorf("Unexpected query string: %+v", get.Request.QueryString)
+	}
+	if post.Request.PostData == nil || post.Request.PostData.Text != `{"name":"Ana"}` || post.Request.PostData.MimeType != "application/json" {
+		t.Errorf("Unexpected post data: %+v", post.Request.PostData)
+	}
+	if post.Response.Status != http.StatusCreated || post.Response.Content.Text != `{"ok":true}` || post.Response.Content.MimeType != "application/json" {
+		t.Errorf("Unexpected response: %+v", post.Response)
+	}
+	if post.Time <= 0 || post.ServerIPAddress != "127.0.0.1" {
+		t.Errorf("Unexpected timings: %v, %+v, %s", post.Time, post.Timings, post.ServerIPAddress)
+	}
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func newTestProxy(t *testing.T, seen *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*seen = append(*seen, r.URL.String())
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+		if r.Header.Get("Proxy-Authorization") != expected {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		_, _ = w.Write([]byte("proxied"))
+	}))
+}
+
+func TestProxyHandler_GlobalProxy(t *testing.T) {
+	var seen []string
+	proxyServer := newTestProxy(t, &seen)
+	defer proxyServer.Close()
+
+	settingsDir := t.TempDir()
+	proxyHandler := &api.ProxyHandler{DefaultPath: settingsDir, Logger: logger}
+	executor := &api.RequestHandler{
+		Client:       &http.Client{Timeout: 30 * time.Second},
+		SettingsPath: settingsDir,
+		Logger:       logger,
+	}
+
+	body, _ := json.Marshal(models.ProxySettings{URL: proxyServer.URL, Username: "user", Password: "pass", NoProxy: []string{"direct.example"}})
+	req, _ := http.NewRequest(http.MethodPut, "/pumoide-api/proxy", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	proxyHandler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Failed to save proxy settings: %d %s", rr.Code, rr.Body.String())
+	}
+
+	_, response := executeRequest(t, executor, models.Request{Method: models.MethodGet, URL: "http://upstream.example/path"})
+	if response.Body != "proxied" {
+		t.Errorf("Request did not go through the proxy: got %d %q", response.StatusCode, response.Body)
+	}
+	if len(seen) != 1 || seen[0] != "http://upstream.example/path" {
+		t.Errorf("Proxy received unexpected requests: %v", seen)
+	}
+
+	// Environments can opt out of the global proxy
+	env := &models.Environment{ID: "direct", Name: "Direct", Proxy: &models.ProxySettings{}}
+	envDir := t.TempDir()
+	if err := env.Save(envDir); err != nil {
+		t.Fatalf("Failed to save environment: %v", err)
+	}
+	executor.EnvironmentPath = envDir
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("direct"))
+	}))
+	defer upstream.Close()
+
+	requestBody, _ := json.Marshal(models.Request{Method: models.MethodGet, URL: upstream.URL})
+	req, _ = http.NewRequest(http.MethodPost, "/pumoide-api/execute?env=direct", bytes.NewBuffer(requestBody))
+	rr = httptest.NewRecorder()
+	executor.ServeHTTP(rr, req)
+
+	var direct models.ExecutionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &direct); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if direct.Body != "direct" || len(seen) != 1 {
+		t.Errorf("Environment without proxy should connect directly: got %q, proxy saw %v", direct.Body, seen)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, "/pumoide-api/proxy", nil)
+	rr = httptest.NewRecorder()
+	proxyHandler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Failed to delete proxy settings: %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "/pumoide-api/proxy", nil)
+	rr = httptest.NewRecorder()
+	proxyHandler.ServeHTTP(rr, req)
+
+	var settings models.ProxySettings
+	if err := json.Unmarshal(rr.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("Failed to unmarshal proxy settings: %v", err)
+	}
+	if settings.URL != "" {
+		t.Errorf("Proxy settings were not deleted: %+v", settings)
+	}
+}
+
+func TestProxyHandler_InvalidSettings(t *testing.T) {
+	proxyHandler := &api.ProxyHandler{DefaultPath: t.TempDir(), Logger: logger}
+
+	body, _ := json.Marshal(models.ProxySettings{URL: "ftp://proxy.example:21"})
+	req, _ := http.NewRequest(http.MethodPut, "/pumoide-api/proxy", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	proxyHandler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected unsupported proxy scheme to be rejected, got %d", rr.Code)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/FedeBP/pumoide/backend/utils"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -76,11 +79,7 @@ func TestRequestHandler_Handle(t *testing.T) {
 		t.Errorf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var response struct {
-		StatusCode int               `json:"statusCode"`
-		Headers    map[string]string `json:"headers"`
-		Body       string            `json:"body"`
-	}
+	var response models.ExecutionResponse
 	err = json.Unmarshal(rr.Body.Bytes(), &response)
 	if err != nil {
 		t.Fatalf("Failed to unmarshal response: %v", err)
@@ -118,3 +117,666 @@ func TestRequestHandler_InvalidMethod(t *testing.T) {
 		t.Errorf("Handler returned unexpected error message: got %v want %v", rr.Body.String(), expectedErrorMessage)
 	}
 }
+
+func TestRequestHandler_Timings(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		_, err := w.Write([]byte("hello"))
+		if err != nil {
+			http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		}
+	}))
+	defer testServer.Close()
+
+	testRequest := models.Request{Method: models.MethodGet, URL: testServer.URL}
+	requestBody, _ := json.Marshal(testRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute", bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response models.ExecutionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Timings.TimeToFirstByte < 10 {
+		t.Errorf("Expected time to first byte of at least 10ms, got %v", response.Timings.TimeToFirstByte)
+	}
+	if response.Timings.Total < response.Timings.TimeToFirstByte {
+		t.Errorf("Total time %v is lower than time to first byte %v", response.Timings.Total, response.Timings.TimeToFirstByte)
+	}
+	if response.RemoteAddr != strings.TrimPrefix(testServer.URL, "http://") {
+		t.Errorf("Expected remote address %s, got %s", strings.TrimPrefix(testServer.URL, "http://"), response.RemoteAddr)
+	}
+	if response.Protocol != "HTTP/1.1" {
+		t.Errorf("Expected protocol HTTP/1.1, got %s", response.Protocol)
+	}
+	if response.Size != 5 {
+		t.Errorf("Expected size 5, got %d", response.Size)
+	}
+}
+
+func TestRequestHandler_MultiValuedHeadersAndCookies(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Encoding")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+		http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Domain: "example.com", MaxAge: 3600, Expires: time.Now().Add(time.Hour)})
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	testRequest := models.Request{Method: models.MethodGet, URL: testServer.URL}
+	requestBody, _ := json.Marshal(testRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute", bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	handler.ServeHTTP(rr, req)
+
+	var response models.ExecutionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	var varyValues, setCookieValues []string
+	for _, header := range response.Headers {
+		switch header.Key {
+		case "Vary":
+			varyValues = append(varyValues, header.Value)
+		case "Set-Cookie":
+			setCookieValues = append(setCookieValues, header.Value)
+		}
+	}
+	if len(varyValues) != 2 || varyValues[0] != "Accept" || varyValues[1] != "Accept-Encoding" {
+		t.Errorf("Expected both Vary values in order, got %v", varyValues)
+	}
+	if len(setCookieValues) != 2 {
+		t.Errorf("Expected 2 Set-Cookie headers, got %d", len(setCookieValues))
+	}
+
+	if len(response.Cookies) != 2 {
+		t.Fatalf("Expected 2 cookies, got %d", len(response.Cookies))
+	}
+	session := response.Cookies[0]
+	if session.Name != "session" || !session.HttpOnly || session.SameSite != "Lax" || session.Path != "/" {
+		t.Errorf("Unexpected session cookie: %+v", session)
+	}
+	theme := response.Cookies[1]
+	if theme.Domain != "example.com" || theme.MaxAge != 3600 || theme.Expires == nil {
+		t.Errorf("Unexpected theme cookie: %+v", theme)
+	}
+}
+
+func TestRequestHandler_BinaryBody(t *testing.T) {
+	payload := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, err := w.Write(payload)
+		if err != nil {
+			http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		}
+	}))
+	defer testServer.Close()
+
+	testRequest := models.Request{Method: models.MethodGet, URL: testServer.URL}
+	requestBody, _ := json.Marshal(testRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute", bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	handler.ServeHTTP(rr, req)
+
+	var response models.ExecutionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Encoding != models.EncodingBase64 {
+		t.Fatalf("Expected base64 encoding, got %s", response.Encoding)
+	}
+	decoded, err := models.DecodeBody(response.Body, response.Encoding)
+	if err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if !bytes.Equal(decoded, payload) {
+		t.Errorf("Decoded body does not match: got %v want %v", decoded, payload)
+	}
+}
+
+func TestRequestHandler_SaveBodyToFile(t *testing.T) {
+	payload := []byte{0x1f, 0x8b, 0x08, 0x00, 0x01, 0x02}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, err := w.Write(payload)
+		if err != nil {
+			http.Error(w, "Failed to write response", http.StatusInternalServerError)
+		}
+	}))
+	defer testServer.Close()
+
+	tempDir := t.TempDir()
+	target := filepath.Join(tempDir, "downloads", "file.bin")
+
+	testRequest := models.Request{Method: models.MethodGet, URL: testServer.URL}
+	requestBody, _ := json.Marshal(testRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute?saveTo="+url.QueryEscape(target), bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	handler.ServeHTTP(rr, req)
+
+	var response models.ExecutionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.SavedTo != target || response.Size != int64(len(payload)) || response.Body != "" {
+		t.Errorf("Unexpected response for saved body: %+v", response)
+	}
+
+	saved, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read saved file: %v", err)
+	}
+	if !bytes.Equal(saved, payload) {
+		t.Errorf("Saved body does not match: got %v want %v", saved, payload)
+	}
+}
+
+func executeRequest(t *testing.T, handler http.Handler, request models.Request) (*httptest.ResponseRecorder, models.ExecutionResponse) {
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute", bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var response models.ExecutionResponse
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+	}
+	return rr, response
+}
+
+func newRedirectServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusFound)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/end", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/end", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("done"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRequestHandler_RedirectChain(t *testing.T) {
+	testServer := newRedirectServer()
+	defer testServer.Close()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	_, response := executeRequest(t, handler, models.Request{Method: models.MethodGet, URL: testServer.URL + "/start"})
+
+	if response.StatusCode != http.StatusOK || response.Body != "done" {
+		t.Errorf("Expected final response to be followed, got %d %q", response.StatusCode, response.Body)
+	}
+	if len(response.Redirects) != 2 {
+		t.Fatalf("Expected 2 redirects, got %d", len(response.Redirects))
+	}
+	if response.Redirects[0].StatusCode != http.StatusFound || response.Redirects[0].Location != testServer.URL+"/middle" {
+		t.Errorf("Unexpected first redirect: %+v", response.Redirects[0])
+	}
+	if response.Redirects[1].StatusCode != http.StatusMovedPermanently || response.Redirects[1].URL != testServer.URL+"/middle" {
+		t.Errorf("Unexpected second redirect: %+v", response.Redirects[1])
+	}
+}
+
+func TestRequestHandler_RedirectSettings(t *testing.T) {
+	testServer := newRedirectServer()
+	defer testServer.Close()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+
+	follow := false
+	_, response := executeRequest(t, handler, models.Request{
+		Method:   models.MethodGet,
+		URL:      testServer.URL + "/start",
+		Settings: &models.RequestSettings{FollowRedirects: &follow},
+	})
+	if response.StatusCode != http.StatusFound {
+		t.Errorf("Expected redirect not to be followed, got status %d", response.StatusCode)
+	}
+
+	// Past the limit the last redirect is returned, without recording the hop that wasn't followed
+	maxRedirects := 1
+	_, response = executeRequest(t, handler, models.Request{
+		Method:   models.MethodGet,
+		URL:      testServer.URL + "/start",
+		Settings: &models.RequestSettings{MaxRedirects: &maxRedirects},
+	})
+	if response.StatusCode != http.StatusMovedPermanently || len(response.Redirects) != 1 {
+		t.Errorf("Expected to stop after 1 redirect, got status %d and %d redirects", response.StatusCode, len(response.Redirects))
+	}
+
+	maxRedirects = 0
+	_, response = executeRequest(t, handler, models.Request{
+		Method:   models.MethodGet,
+		URL:      testServer.URL + "/start",
+		Settings: &models.RequestSettings{MaxRedirects: &maxRedirects},
+	})
+	if response.StatusCode != http.StatusFound || len(response.Redirects) != 0 {
+		t.Errorf("Expected no redirect to be followed, got status %d and %d redirects", response.StatusCode, len(response.Redirects))
+	}
+}
+
+func TestRequestHandler_TimeoutOverride(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer testServer.Close()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	rr, _ := executeRequest(t, handler, models.Request{
+		Method:   models.MethodGet,
+		URL:      testServer.URL,
+		Settings: &models.RequestSettings{TimeoutMs: 50},
+	})
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected request to time out, got status %d", rr.Code)
+	}
+}
+
+func TestRequestHandler_TLSSettings(t *testing.T) {
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	testServer.EnableHTTP2 = true
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+
+	rr, _ := executeRequest(t, handler, models.Request{Method: models.MethodGet, URL: testServer.URL})
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected self-signed certificate to be rejected, got status %d", rr.Code)
+	}
+
+	_, response := executeRequest(t, handler, models.Request{
+		Method:   models.MethodGet,
+		URL:      testServer.URL,
+		Settings: &models.RequestSettings{SkipTLSVerify: true, HTTPVersion: models.HTTPVersion2},
+	})
+	if response.Protocol != "HTTP/2.0" {
+		t.Errorf("Expected HTTP/2.0, got %q", response.Protocol)
+	}
+
+	_, response = executeRequest(t, handler, models.Request{
+		Method:   models.MethodGet,
+		URL:      testServer.URL,
+		Settings: &models.RequestSettings{SkipTLSVerify: true, HTTPVersion: models.HTTPVersion1},
+	})
+	if response.Protocol != "HTTP/1.1" {
+		t.Errorf("Expected HTTP/1.1, got %q", response.Protocol)
+	}
+}
+
+type streamEvent struct {
+	name string
+	data string
+}
+
+func readStreamEvents(t *testing.T, body string) []streamEvent {
+	var events []streamEvent
+	for _, block := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var event streamEvent
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "event: ") {
+				event.name = strings.TrimPrefix(line, "event: ")
+			} else if strings.HasPrefix(line, "data: ") {
+				event.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		if event.name == "" {
+			t.Fatalf("Malformed event block: %q", block)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestRequestHandler_StreamBody(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte(strings.Repeat("x", 10)))
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}))
+	defer testServer.Close()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	requestBody, _ := json.Marshal(models.Request{Method: models.MethodGet, URL: testServer.URL})
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute?stream=true&previewSize=15", bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if contentType := rr.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %s", contentType)
+	}
+
+	events := readStreamEvents(t, rr.Body.String())
+	if events[0].name != "started" || events[1].name != "metadata" || events[len(events)-1].name != "done" {
+		t.Fatalf("Unexpected event sequence: %+v", events)
+	}
+
+	var streamed []byte
+	for _, event := range events {
+		if event.name != "chunk" {
+			continue
+		}
+		var chunk struct {
+			Data     string `json:"data"`
+			Received int64  `json:"received"`
+		}
+		if err := json.Unmarshal([]byte(event.data), &chunk); err != nil {
+			t.Fatalf("Failed to unmarshal chunk: %v", err)
+		}
+		data, err := models.DecodeBody(chunk.Data, models.EncodingBase64)
+		if err != nil {
+			t.Fatalf("Failed to decode chunk: %v", err)
+		}
+		streamed = append(streamed, data...)
+		if chunk.Received != int64(len(streamed)) {
+			t.Errorf("Chunk reported %d received bytes, want %d", chunk.Received, len(streamed))
+		}
+	}
+	if string(streamed) != strings.Repeat("x", 30) {
+		t.Errorf("Streamed body does not match: got %q", streamed)
+	}
+
+	var final models.ExecutionResponse
+	if err := json.Unmarshal([]byte(events[len(events)-1].data), &final); err != nil {
+		t.Fatalf("Failed to unmarshal final response: %v", err)
+	}
+	if final.Size != 30 || !final.Truncated || final.Body != strings.Repeat("x", 15) {
+		t.Errorf("Unexpected final response: size=%d truncated=%v body=%q", final.Size, final.Truncated, final.Body)
+	}
+}
+
+func TestRequestHandler_StreamToFile(t *testing.T) {
+	payload := bytes.Repeat([]byte{0x00, 0xff}, 50000)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(payload)
+	}))
+	defer testServer.Close()
+
+	target := filepath.Join(t.TempDir(), "export.bin")
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	requestBody, _ := json.Marshal(models.Request{Method: models.MethodGet, URL: testServer.URL})
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute?stream=true&saveTo="+url.QueryEscape(target), bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	events := readStreamEvents(t, rr.Body.String())
+	for _, event := range events {
+		if event.name == "chunk" && strings.Contains(event.data, `"data"`) {
+			t.Fatalf("Body should not be forwarded when saving to a file: %s", event.data)
+		}
+		if event.name == "error" {
+			t.Fatalf("Stream failed: %s", event.data)
+		}
+	}
+
+	saved, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read saved file: %v", err)
+	}
+	if !bytes.Equal(saved, payload) {
+		t.Errorf("Saved body does not match: got %d bytes, want %d", len(saved), len(payload))
+	}
+}
+
+func TestRequestHandler_FormDataBody(t *testing.T) {
+	tempDir := t.TempDir()
+	uploadPath := filepath.Join(tempDir, "avatar.png")
+	if err := os.WriteFile(uploadPath, []byte("fake image"), 0644); err != nil {
+		t.Fatalf("Failed to write upload file: %v", err)
+	}
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The form is streamed, yet its length is announced
+		if r.ContentLength <= 0 || len(r.TransferEncoding) != 0 {
+			t.Errorf("Expected a Content-Length, got %d %v", r.ContentLength, r.TransferEncoding)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Failed to parse multipart form: %v", err)
+			return
+		}
+		if got := r.FormValue("name"); got != "pumoide" {
+			t.Errorf("Expected field 'name=pumoide', got '%s'", got)
+		}
+		if _, ok := r.MultipartForm.Value["skipped"]; ok {
+			t.Errorf("Disabled field should not be sent")
+		}
+
+		file, header, err := r.FormFile("avatar")
+		if err != nil {
+			t.Errorf("Expected uploaded file: %v", err)
+			return
+		}
+		defer file.Close()
+
+		content := new(bytes.Buffer)
+		content.ReadFrom(file)
+		if header.Filename != "avatar.png" || header.Header.Get("Content-Type") != "image/png" || content.String() != "fake image" {
+			t.Errorf("Unexpected file part: %s %s %q", header.Filename, header.Header.Get("Content-Type"), content.String())
+		}
+	}))
+	defer testServer.Close()
+
+	env := &models.Environment{Variables: map[string]string{"user": "pumoide", "dir": tempDir}}
+	handler := newRequestHandler(tempDir)
+	if err := env.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save environment: %v", err)
+	}
+
+	testRequest := models.Request{
+		Method: models.MethodPost,
+		URL:    testServer.URL,
+		// A user Content-Type must not drop the multipart boundary
+		Headers: []models.Header{{Key: "Content-Type", Value: "multipart/form-data"}},
+		BodyConfig: &models.RequestBody{
+			Mode: models.BodyFormData,
+			FormData: []models.FormField{
+				{Key: "name", Value: "{{user}}"},
+				{Key: "skipped", Value: "x", Disabled: true},
+				{Key: "avatar", Type: models.FormFieldFile, FilePath: "{{dir}}/avatar.png"},
+			},
+		},
+	}
+
+	requestBody, _ := json.Marshal(testRequest)
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute?env="+env.ID, bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestRequestHandler_URLEncodedAndFileBodies(t *testing.T) {
+	var gotContentType, gotBody string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := new(bytes.Buffer)
+		body.ReadFrom(r.Body)
+		gotContentType = r.Header.Get("Content-Type")
+		gotBody = body.String()
+	}))
+	defer testServer.Close()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+
+	rr, _ := executeRequest(t, handler, models.Request{
+		Method: models.MethodPost,
+		URL:    testServer.URL,
+		BodyConfig: &models.RequestBody{
+			Mode: models.BodyURLEncoded,
+			URLEncoded: []models.FormField{
+				{Key: "q", Value: "a b&c"},
+				{Key: "off", Value: "1", Disabled: true},
+			},
+		},
+	})
+	if rr.Code != http.StatusOK || gotContentType != "application/x-www-form-urlencoded" || gotBody != "q=a+b%26c" {
+		t.Errorf("Unexpected URL-encoded body: %d %s %q", rr.Code, gotContentType, gotBody)
+	}
+
+	uploadPath := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(uploadPath, []byte{0x00, 0x01, 0x02}, 0644); err != nil {
+		t.Fatalf("Failed to write upload file: %v", err)
+	}
+
+	rr, _ = executeRequest(t, handler, models.Request{
+		Method:     models.MethodPut,
+		URL:        testServer.URL,
+		BodyConfig: &models.RequestBody{Mode: models.BodyBinary, FilePath: uploadPath},
+	})
+	if rr.Code != http.StatusOK || gotContentType != "application/octet-stream" || gotBody != "\x00\x01\x02" {
+		t.Errorf("Unexpected binary body: %d %s %q", rr.Code, gotContentType, gotBody)
+	}
+
+	rr, _ = executeRequest(t, handler, models.Request{
+		Method:     models.MethodPost,
+		URL:        testServer.URL,
+		BodyConfig: &models.RequestBody{Mode: models.BodyJSON, Raw: `{"broken":`},
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Invalid JSON body should be rejected: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRequestHandler_EventSource(t *testing.T) {
+	connections := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connections++
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected Accept: text/event-stream, got %s", r.Header.Get("Accept"))
+		}
+
+		switch connections {
+		case 1:
+			if r.Header.Get("Last-Event-ID") != "" {
+				t.Errorf("First connection should not send Last-Event-ID")
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("retry: 10\n\nid: 1\nevent: log\ndata: line1\ndata: line2\n\n: keep-alive\ndata:tail\n\nid: 2\r\ndata: two\r\n\r\ndata: unterminated"))
+		case 2:
+			if r.Header.Get("Last-Event-ID") != "2" {
+				t.Errorf("Expected Last-Event-ID: 2, got '%s'", r.Header.Get("Last-Event-ID"))
+			}
+			w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			_, _ = w.Write([]byte("id: 3\ndata: three\n\n"))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer testServer.Close()
+
+	requestBody, _ := json.Marshal(models.Request{Type: models.RequestSSE, URL: testServer.URL})
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute", bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	handler.ServeHTTP(rr, req)
+
+	var names []string
+	var events []models.ServerSentEvent
+	for _, event := range readStreamEvents(t, rr.Body.String()) {
+		names = append(names, event.name)
+		if event.name == "event" {
+			var sse models.ServerSentEvent
+			if err := json.Unmarshal([]byte(event.data), &sse); err != nil {
+				t.Fatalf("Failed to decode event: %v", err)
+			}
+			events = append(events, sse)
+		}
+		if event.name == "done" && !strings.Contains(event.data, `"reason":"closed"`) {
+			t.Errorf("Expected the stream to be closed by the server: %s", event.data)
+		}
+	}
+
+	expectedNames := "started,open,event,event,event,reconnecting,open,event,reconnecting,open,done"
+	if strings.Join(names, ",") != expectedNames {
+		t.Fatalf("Unexpected events: got %v want %s", names, expectedNames)
+	}
+
+	expected := []models.ServerSentEvent{
+		{ID: "1", Event: "log", Data: "line1\nline2"},
+		{ID: "1", Event: "message", Data: "tail"},
+		{ID: "2", Event: "message", Data: "two"},
+		{ID: "3", Event: "message", Data: "three"},
+	}
+	for i, event := range events {
+		if event.ID != expected[i].ID || event.Event != expected[i].Event || event.Data != expected[i].Data {
+			t.Errorf("Unexpected event %d: got %+v want %+v", i, event, expected[i])
+		}
+	}
+}
+
+func TestRequestHandler_StopEventSource(t *testing.T) {
+	connected := make(chan struct{})
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		close(connected)
+		<-r.Context().Done()
+	}))
+	defer testServer.Close()
+
+	handler := newRequestHandler(utils.GetDefaultEnvironmentsPath())
+	handler.Executions = api.NewExecutionRegistry()
+
+	requestBody, _ := json.Marshal(models.Request{Type: models.RequestSSE, URL: testServer.URL})
+	req, _ := http.NewRequest(http.MethodPost, "/pumoide-api/execute?executionId=feed", bytes.NewBuffer(requestBody))
+	rr := httptest.NewRecorder()
+
+	finished := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(rr, req)
+		close(finished)
+	}()
+
+	<-connected
+	if !handler.Executions.Cancel("feed") {
+		t.Fatalf("Event stream should be registered as an execution")
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Event stream did not stop after cancellation")
+	}
+
+	events := readStreamEvents(t, rr.Body.String())
+	last := events[len(events)-1]
+	if last.name != "done" || !strings.Contains(last.data, `"reason":"stopped"`) {
+		t.Errorf("Expected a stopped completion, got %+v", last)
+	}
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestSnippetHandler(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "snippet_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	handler := &api.SnippetHandler{CollectionsPath: tempDir, EnvironmentsPath: tempDir, Logger: logger}
+
+	collection := models.Collection{ID: "collection1", Name: "Status", Requests: []models.Request{
+		{ID: "request1", Name: "Status", Method: models.MethodGet, URL: "{{baseUrl}}/status"},
+		{ID: "request2", Name: "Socket", Type: models.RequestWebSocket, URL: "wss://example.com"},
+	}}
+	if err := collection.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+	env := models.Environment{ID: "env1", Variables: map[string]string{"baseUrl": "https://status.example.com"}}
+	if err := env.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save environment: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/pumoide-api/snippets", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var languages []models.SnippetLanguage
+	if err := json.Unmarshal(rr.Body.Bytes(), &languages); err != nil {
+		t.Fatalf("Failed to unmarshal languages: %v", err)
+	}
+	if !reflect.DeepEqual(languages, models.GetValidSnippetLanguages()) {
+		t.Errorf("Unexpected languages: %v", languages)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/pumoide-api/snippets?collectionId=collection1&requestId=request1&language=python&env=env1", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), `url = "https://status.example.com/status"`) {
+		t.Errorf("Unexpected snippet: %s", rr.Body.String())
+	}
+
+	body := `{"method": "DELETE", "url": "https://example.com/pets/1", "auth": {"type": "bearer", "params": {"token": "abc"}}}`
+	req = httptest.NewRequest(http.MethodPost, "/pumoide-api/snippets?language=httpie", strings.NewReader(body))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	expected := "http --follow DELETE https://example.com/pets/1 \\\n  'Authorization:Bearer abc'\n"
+	if rr.Body.String() != expected {
+		t.Errorf("Unexpected snippet:\n%s\nwant:\n%s", rr.Body.String(), expected)
+	}
+
+	testCases := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"unknown language", "/pumoide-api/snippets?collectionId=collection1&requestId=request1&language=ruby", http.StatusBadRequest},
+		{"WebSocket request", "/pumoide-api/snippets?collectionId=collection1&requestId=request2&language=go", http.StatusBadRequest},
+		{"missing request", "/pumoide-api/snippets?collectionId=collection1&requestId=missing&language=go", http.StatusNotFound},
+		{"missing environment", "/pumoide-api/snippets?collectionId=collection1&requestId=request1&language=go&env=missing", http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.url, nil))
+		if rr.Code != tc.status {
+			t.Errorf("%s: unexpected status code: got %v want %v", tc.name, rr.Code, tc.status)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/api"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/gorilla/websocket"
+)
+
+// newEchoWebSocketServer greets every client, echoes its messages and closes the connection on "bye".
+func newEchoWebSocketServer(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Failed to upgrade connection: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("welcome"))
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(data) == "bye" {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4000, "goodbye"))
+				return
+			}
+			_ = conn.WriteMessage(messageType, data)
+		}
+	}))
+}
+
+func TestWebSocketHandler_Session(t *testing.T) {
+	wsServer := newEchoWebSocketServer(t)
+	defer wsServer.Close()
+
+	collectionsPath := t.TempDir()
+	collection := models.Collection{Name: "Realtime"}
+	if err := collection.Save(collectionsPath); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	handler := &api.WebSocketHandler{
+		Sessions:        api.NewWebSocketRegistry(),
+		Executor:        &api.RequestHandler{Client: &http.Client{Timeout: 30 * time.Second}, Logger: logger},
+		CollectionsPath: collectionsPath,
+		Logger:          logger,
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	post := func(query string, body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		resp, err := http.Post(server.URL+"?"+query, "application/json", bytes.NewBuffer(data))
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		return resp
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http")
+	request := models.Request{
+		Name: "Notifications",
+		URL:  wsURL,
+		Auth: &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "secret"}},
+	}
+
+	unauthorized := request
+	unauthorized.Auth = nil
+	if resp := post("action=connect", unauthorized); resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("Rejected handshake should return 502, got %v", resp.StatusCode)
+	}
+
+	resp := post("action=connect", request)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Failed to connect: %v %s", resp.StatusCode, body)
+	}
+	var session models.WebSocketSession
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		t.Fatalf("Failed to decode session: %v", err)
+	}
+	if session.Status != models.WebSocketOpen || session.URL != wsURL {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+
+	// Headers are sent once the subscription is registered, so no message can be missed after this point
+	stream, err := http.Get(server.URL + "?stream=true&id=" + session.ID)
+	if err != nil {
+		t.Fatalf("Failed to open message stream: %v", err)
+	}
+	defer stream.Body.Close()
+
+	if resp := post("action=send&id="+session.ID, models.WebSocketMessage{Type: models.WebSocketText, Data: "hello"}); resp.StatusCode != http.StatusOK {
+		t.Errorf("Failed to send text message: %v", resp.StatusCode)
+	}
+	binary := models.WebSocketMessage{Type: models.WebSocketBinary, Data: "AAEC", Encoding: models.EncodingBase64}
+	if resp := post("action=send&id="+session.ID, binary); resp.StatusCode != http.StatusOK {
+		t.Errorf("Failed to send binary message: %v", resp.StatusCode)
+	}
+	post("action=send&id="+session.ID, models.WebSocketMessage{Data: "bye"})
+
+	body, err := io.ReadAll(stream.Body)
+	if err != nil {
+		t.Fatalf("Failed to read message stream: %v", err)
+	}
+	events := readStreamEvents(t, string(body))
+
+	var received []string
+	for _, event := range events[:len(events)-1] {
+		var message models.WebSocketMessage
+		if err := json.Unmarshal([]byte(event.data), &message); err != nil {
+			t.Fatalf("Failed to decode message event: %v", err)
+		}
+		// The greeting may arrive before the stream is opened
+		if message.Direction == models.MessageReceived && message.Data != "welcome" {
+			received = append(received, message.Data)
+		}
+	}
+	if strings.Join(received, ",") != "hello,AAEC" {
+		t.Errorf("Unexpected pushed messages: %v", received)
+	}
+
+	var closed models.WebSocketSession
+	last := events[len(events)-1]
+	if err := json.Unmarshal([]byte(last.data), &closed); err != nil || last.name != "closed" {
+		t.Fatalf("Expected a closed event, got %+v", last)
+	}
+	if closed.Status != models.WebSocketClosed || closed.CloseCode != 4000 || closed.CloseReason != "goodbye" {
+		t.Errorf("Unexpected closed session: %+v", closed)
+	}
+
+	if resp := post("action=send&id="+session.ID, models.WebSocketMessage{Data: "late"}); resp.StatusCode != http.StatusConflict {
+		t.Errorf("Sending on a closed session should return 409, got %v", resp.StatusCode)
+	}
+
+	resp = post("action=save&id="+session.ID+"&collectionId="+collection.ID, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to save message log: %v", resp.StatusCode)
+	}
+
+	saved, err := models.LoadCollection(collectionsPath, collection.ID)
+	if err != nil {
+		t.Fatalf("Failed to load collection: %v", err)
+	}
+	if len(saved.Requests) != 1 || saved.Requests[0].Type != models.RequestWebSocket || len(saved.Requests[0].Messages) != 6 {
+		t.Errorf("Unexpected saved request: %+v", saved.Requests)
+	}
+
+	// Like the collection handler, the path parameter selects another collections directory
+	otherPath := t.TempDir()
+	other := &models.Collection{Name: "Elsewhere"}
+	if err := other.Save(otherPath); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+	resp = post("action=save&id="+session.ID+"&collectionId="+other.ID+"&path="+url.QueryEscape(otherPath), nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Failed to save message log in another directory: %v", resp.StatusCode)
+	}
+	if saved, err := models.LoadCollection(otherPath, other.ID); err != nil || len(saved.Requests) != 1 {
+		t.Errorf("Message log was not saved in the given directory: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"?id="+session.ID, nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("Failed to delete session: %v", err)
+	}
+	if resp, _ := http.Get(server.URL + "?id=" + session.ID); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Deleted session should not be found, got %v", resp.StatusCode)
+	}
+}
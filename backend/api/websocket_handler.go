@@ -0,0 +1,389 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ActionConnect    = "connect"
+	ActionSend       = "send"
+	ActionDisconnect = "disconnect"
+	ActionSaveLog    = "save"
+)
+
+type WebSocketHandler struct {
+	Sessions        *WebSocketRegistry
+	Executor        *RequestHandler
+	CollectionsPath string
+	Logger          *logrus.Logger
+}
+
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := r.URL.Query().Get("action")
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("id") == "" {
+			h.listSessions(w)
+		} else if r.URL.Query().Get("stream") == "true" {
+			h.streamSession(w, r)
+		} else {
+			h.getSession(w, r)
+		}
+	case http.MethodPost:
+		switch action {
+		case ActionConnect:
+			h.connect(w, r)
+		case ActionSend:
+			h.sendMessage(w, r)
+		case ActionDisconnect:
+			h.disconnect(w, r)
+		case ActionSaveLog:
+			h.saveLog(w, r)
+		default:
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid action", nil, h.Logger)
+		}
+	case http.MethodDelete:
+		h.deleteSession(w, r)
+	default:
+		apperrors.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil, h.Logger)
+	}
+}
+
+func (h *WebSocketHandler) sessionFor(w http.ResponseWriter, r *http.Request) (*webSocketSession, bool) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Session ID is required", nil, h.Logger)
+		return nil, false
+	}
+
+	session, ok := h.Sessions.get(id)
+	if !ok {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Session not found", nil, h.Logger)
+		return nil, false
+	}
+	return session, true
+}
+
+func (h *WebSocketHandler) respondWithSession(w http.ResponseWriter, status int, session models.WebSocketSession) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(session); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode session", err, h.Logger)
+	}
+}
+
+// GET methods
+
+func (h *WebSocketHandler) listSessions(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.Sessions.List()); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode sessions", err, h.Logger)
+	}
+}
+
+func (h *WebSocketHandler) getSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.sessionFor(w, r)
+	if !ok {
+		return
+	}
+	h.respondWithSession(w, http.StatusOK, session.snapshot())
+}
+
+// streamSession pushes the messages of a session to the UI as server-sent events until it is closed.
+func (h *WebSocketHandler) streamSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.sessionFor(w, r)
+	if !ok {
+		return
+	}
+
+	messages, unsubscribe := session.subscribe()
+	defer unsubscribe()
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Streaming not supported", err, h.Logger)
+		return
+	}
+
+	for {
+		select {
+		case message := <-messages:
+			if err := stream.send("message", message); err != nil {
+				return
+			}
+		case <-session.done:
+			// Flush what was received right before the connection closed
+			for len(messages) > 0 {
+				if err := stream.send("message", <-messages); err != nil {
+					return
+				}
+			}
+			info := session.snapshot()
+			info.Messages = nil
+			_ = stream.send("closed", info)
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// POST methods
+
+func (h *WebSocketHandler) connect(w http.ResponseWriter, r *http.Request) {
+	var req models.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err, h.Logger)
+		return
+	}
+	req.Type = models.RequestWebSocket
+
+	var env *models.Environment
+	if envID := r.URL.Query().Get("env"); envID != "" {
+		var err error
+		env, err = models.LoadEnvironment(h.Executor.EnvironmentPath, envID)
+		if err != nil {
+			apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to load environment", err, h.Logger)
+			return
+		}
+	}
+
+	conn, url, err := h.Executor.DialWebSocket(r.Context(), req, env)
+	if err != nil {
+		respondWithExecutionError(w, err, h.Logger)
+		return
+	}
+
+	session := newWebSocketSession(uuid.New().String(), conn, req, url)
+	h.Sessions.add(session)
+
+	h.respondWithSession(w, http.StatusCreated, session.snapshot())
+}
+
+func (h *WebSocketHandler) sendMessage(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.sessionFor(w, r)
+	if !ok {
+		return
+	}
+
+	var message models.WebSocketMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid message", err, h.Logger)
+		return
+	}
+	if message.Type == "" {
+		message.Type = models.WebSocketText
+	}
+	if err := message.Validate(); err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid message", err, h.Logger)
+		return
+	}
+
+	data, err := models.DecodeBody(message.Data, message.Encoding)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Failed to decode message", err, h.Logger)
+		return
+	}
+
+	if !session.isOpen() {
+		apperrors.RespondWithError(w, http.StatusConflict, "Session is closed", nil, h.Logger)
+		return
+	}
+
+	sent, err := session.send(message.Type, data)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusBadGateway, "Failed to send message", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sent); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode message", err, h.Logger)
+	}
+}
+
+func (h *WebSocketHandler) disconnect(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.sessionFor(w, r)
+	if !ok {
+		return
+	}
+
+	session.close()
+	h.respondWithSession(w, http.StatusOK, session.snapshot())
+}
+
+// saveLog stores the message log of a session in a collection, either on the request given by
+// requestId or on a new request created from the one the session was opened with.
+func (h *WebSocketHandler) saveLog(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.sessionFor(w, r)
+	if !ok {
+		return
+	}
+
+	collectionID := r.URL.Query().Get("collectionId")
+	if collectionID == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Collection ID is required", nil, h.Logger)
+		return
+	}
+
+	collectionsPath := collectionPath(r, h.CollectionsPath)
+	collection, err := models.LoadCollection(collectionsPath, collectionID)
+	if err != nil {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Collection not found", err, h.Logger)
+		return
+	}
+
+	info := session.snapshot()
+	var saved models.Request
+
+	if requestID := r.URL.Query().Get("requestId"); requestID != "" {
+		found := false
+		for i := range collection.Requests {
+			if collection.Requests[i].ID == requestID {
+				collection.Requests[i].Messages = info.Messages
+				saved = collection.Requests[i]
+				found = true
+				break
+			}
+		}
+		if !found {
+			apperrors.RespondWithError(w, http.StatusNotFound, "Request not found", nil, h.Logger)
+			return
+		}
+	} else {
+		saved = info.Request
+		saved.ID = uuid.New().String()
+		if saved.Name == "" {
+			saved.Name = info.URL
+		}
+		saved.Messages = info.Messages
+		if err := collection.AddRequest(saved); err != nil {
+			apperrors.RespondWithError(w, http.StatusBadRequest, "Invalid request", err, h.Logger)
+			return
+		}
+	}
+
+	if err := collection.Save(collectionsPath); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to save collection", err, h.Logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(saved); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to encode request", err, h.Logger)
+	}
+}
+
+// DELETE methods
+
+func (h *WebSocketHandler) deleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apperrors.RespondWithError(w, http.StatusBadRequest, "Session ID is required", nil, h.Logger)
+		return
+	}
+
+	session, ok := h.Sessions.remove(id)
+	if !ok {
+		apperrors.RespondWithError(w, http.StatusNotFound, "Session not found", nil, h.Logger)
+		return
+	}
+	session.close()
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte("Session deleted successfully")); err != nil {
+		apperrors.RespondWithError(w, http.StatusInternalServerError, "Failed to write response", err, h.Logger)
+	}
+}
+
+// DialWebSocket opens a WebSocket connection with the headers, auth, certificates, proxy and
+// cookies that would apply to an HTTP request to the same URL.
+func (h *RequestHandler) DialWebSocket(ctx context.Context, req models.Request, env *models.Environment) (*websocket.Conn, string, error) {
+	target, err := h.resolveURL(req, env, nil)
+	if err != nil {
+		return nil, "", apperrors.NewAppError(http.StatusBadRequest, "Invalid URL", err)
+	}
+
+	// Certificates and proxies are matched against the HTTP form of the URL
+	httpTarget := *target
+	switch target.Scheme {
+	case "ws":
+		httpTarget.Scheme = "http"
+	case "wss":
+		httpTarget.Scheme = "https"
+	default:
+		return nil, "", apperrors.NewAppError(http.StatusBadRequest, "WebSocket URLs must use ws or wss", fmt.Errorf("%s", target.Scheme))
+	}
+
+	handshake, err := http.NewRequestWithContext(ctx, http.MethodGet, httpTarget.String(), nil)
+	if err != nil {
+		return nil, "", apperrors.NewAppError(http.StatusInternalServerError, "Failed to create request", err)
+	}
+	for _, header := range req.Headers {
+		handshake.Header.Set(header.Key, h.substituteVariables(header.Value, env))
+	}
+	if req.Auth != nil {
+		if err := h.applyAuthentication(handshake, req.Auth, env); err != nil {
+			return nil, "", apperrors.NewAppError(http.StatusInternalServerError, "Failed to apply authentication", err)
+		}
+	}
+	// API keys sent in the query are added by the authentication
+	target.RawQuery = handshake.URL.RawQuery
+
+	tlsConfig, err := h.tlsConfigFor(&httpTarget)
+	if err != nil {
+		return nil, "", apperrors.NewAppError(http.StatusInternalServerError, "Failed to configure client", err)
+	}
+	proxy, err := h.proxyFor(env)
+	if err != nil {
+		return nil, "", apperrors.NewAppError(http.StatusInternalServerError, "Failed to configure client", err)
+	}
+
+	transport := h.newTransport(req.Settings, tlsConfig, proxy)
+	dialer := websocket.Dialer{
+		Proxy:            transport.Proxy,
+		TLSClientConfig:  transport.TLSClientConfig,
+		HandshakeTimeout: h.Client.Timeout,
+	}
+	if req.Settings != nil && req.Settings.TimeoutMs > 0 {
+		dialer.HandshakeTimeout = time.Duration(req.Settings.TimeoutMs) * time.Millisecond
+	}
+	// The handshake is always HTTP/1.1
+	dialer.TLSClientConfig.NextProtos = nil
+
+	jar, err := h.openCookieJar(env)
+	if err != nil {
+		return nil, "", apperrors.NewAppError(http.StatusInternalServerError, "Failed to load cookie jar", err)
+	}
+	if jar != nil {
+		dialer.Jar = jar
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, target.String(), handshake.Header)
+
+	if jar != nil {
+		if err := h.saveCookieJar(jar); err != nil {
+			h.Logger.WithError(err).Error("Failed to save cookie jar")
+		}
+	}
+
+	if err != nil {
+		if resp != nil {
+			err = fmt.Errorf("%w: server answered with status %d", err, resp.StatusCode)
+			return nil, "", apperrors.NewAppError(http.StatusBadGateway, "WebSocket handshake failed", err)
+		}
+		return nil, "", executionError(ctx, "Failed to connect", err)
+	}
+
+	return conn, target.String(), nil
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/models"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	webSocketWriteWait = 10 * time.Second
+	// webSocketCloseWait is how long a closing session waits for the server to acknowledge the close frame
+	webSocketCloseWait = 2 * time.Second
+	subscriberBuffer   = 256
+)
+
+// WebSocketRegistry keeps the WebSocket sessions opened from the UI, along with their message logs.
+// Closed sessions stay registered until they are removed, so their log can still be saved.
+type WebSocketRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*webSocketSession
+}
+
+func NewWebSocketRegistry() *WebSocketRegistry {
+	return &WebSocketRegistry{sessions: make(map[string]*webSocketSession)}
+}
+
+func (r *WebSocketRegistry) add(session *webSocketSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.info.ID] = session
+}
+
+func (r *WebSocketRegistry) get(id string) (*webSocketSession, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	return session, ok
+}
+
+func (r *WebSocketRegistry) remove(id string) (*webSocketSession, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	delete(r.sessions, id)
+	return session, ok
+}
+
+func (r *WebSocketRegistry) List() []models.WebSocketSession {
+	r.mu.Lock()
+	sessions := make([]models.WebSocketSession, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		sessions = append(sessions, session.snapshot())
+	}
+	r.mu.Unlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].OpenedAt.Before(sessions[j].OpenedAt)
+	})
+	return sessions
+}
+
+type webSocketSession struct {
+	conn *websocket.Conn
+	// writeMu serializes writes, the connection supports a single concurrent writer
+	writeMu sync.Mutex
+
+	mu          sync.Mutex
+	info        models.WebSocketSession
+	subscribers map[chan models.WebSocketMessage]struct{}
+	done        chan struct{}
+}
+
+// newWebSocketSession wraps an established connection and starts receiving its messages.
+func newWebSocketSession(id string, conn *websocket.Conn, req models.Request, url string) *webSocketSession {
+	session := &webSocketSession{
+		conn: conn,
+		info: models.WebSocketSession{
+			ID:       id,
+			URL:      url,
+			Request:  req,
+			Status:   models.WebSocketOpen,
+			OpenedAt: time.Now(),
+			Messages: []models.WebSocketMessage{},
+		},
+		subscribers: make(map[chan models.WebSocketMessage]struct{}),
+		done:        make(chan struct{}),
+	}
+
+	go session.readLoop()
+	return session
+}
+
+func (s *webSocketSession) readLoop() {
+	defer close(s.done)
+
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.markClosed(err)
+			return
+		}
+
+		kind := models.WebSocketText
+		if messageType == websocket.BinaryMessage {
+			kind = models.WebSocketBinary
+		}
+		s.record(models.NewWebSocketMessage(models.MessageReceived, kind, data))
+	}
+}
+
+// record adds a message to the log and pushes it to the subscribers. Slow subscribers miss
+// messages rather than blocking the connection, the log keeps all of them.
+func (s *webSocketSession) record(message models.WebSocketMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.info.AddMessage(message)
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- message:
+		default:
+		}
+	}
+}
+
+func (s *webSocketSession) markClosed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	closedAt := time.Now()
+	s.info.Status = models.WebSocketClosed
+	s.info.ClosedAt = &closedAt
+
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		s.info.CloseCode = closeErr.Code
+		s.info.CloseReason = closeErr.Text
+	} else {
+		s.info.CloseCode = websocket.CloseAbnormalClosure
+		s.info.CloseReason = err.Error()
+	}
+
+	_ = s.conn.Close()
+}
+
+func (s *webSocketSession) isOpen() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info.Status == models.WebSocketOpen
+}
+
+func (s *webSocketSession) send(kind models.WebSocketMessageType, data []byte) (models.WebSocketMessage, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if !s.isOpen() {
+		return models.WebSocketMessage{}, fmt.Errorf("session %s is closed", s.info.ID)
+	}
+
+	messageType := websocket.TextMessage
+	if kind == models.WebSocketBinary {
+		messageType = websocket.BinaryMessage
+	}
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(webSocketWriteWait)); err != nil {
+		return models.WebSocketMessage{}, err
+	}
+	if err := s.conn.WriteMessage(messageType, data); err != nil {
+		return models.WebSocketMessage{}, err
+	}
+
+	message := models.NewWebSocketMessage(models.MessageSent, kind, data)
+	s.record(message)
+	return message, nil
+}
+
+// close performs the closing handshake, dropping the connection if the server doesn't answer in time.
+func (s *webSocketSession) close() {
+	if s.isOpen() {
+		s.writeMu.Lock()
+		err := s.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(webSocketWriteWait))
+		s.writeMu.Unlock()
+
+		if err == nil {
+			select {
+			case <-s.done:
+			case <-time.After(webSocketCloseWait):
+			}
+		}
+	}
+
+	_ = s.conn.Close()
+	<-s.done
+}
+
+func (s *webSocketSession) subscribe() (<-chan models.WebSocketMessage, func()) {
+	subscriber := make(chan models.WebSocketMessage, subscriberBuffer)
+
+	s.mu.Lock()
+	s.subscribers[subscriber] = struct{}{}
+	s.mu.Unlock()
+
+	return subscriber, func() {
+		s.mu.Lock()
+		delete(s.subscribers, subscriber)
+		s.mu.Unlock()
+	}
+}
+
+func (s *webSocketSession) snapshot() models.WebSocketSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.info
+	info.Messages = append([]models.WebSocketMessage{}, s.info.Messages...)
+	return info
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/FedeBP/pumoide/backend/models"
 	"github.com/FedeBP/pumoide/backend/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -20,10 +21,17 @@ type Config struct {
 	RateLimitBurst          int
 	DefaultCollectionsPath  string
 	DefaultEnvironmentsPath string
+	DefaultHistoryPath      string
+	DefaultCertificatesPath string
+	DefaultSettingsPath     string
+	DefaultCookiesPath      string
+	DefaultGraphQLPath      string
 	LogFilePath             string
 	LogFileName             string
 	LogLevel                string
 	ClientTimeout           time.Duration
+	StreamPreviewSize       int64
+	HistoryRetention        int
 }
 
 type Pumoide struct {
@@ -43,6 +51,26 @@ func (a *Pumoide) Start() error {
 		return fmt.Errorf("failed to create environments directory: %w", err)
 	}
 
+	if err := utils.EnsureDir(a.config.DefaultHistoryPath); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	if err := utils.EnsureDir(a.config.DefaultCertificatesPath); err != nil {
+		return fmt.Errorf("failed to create certificates directory: %w", err)
+	}
+
+	if err := utils.EnsureDir(a.config.DefaultSettingsPath); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
+	}
+
+	if err := utils.EnsureDir(a.config.DefaultCookiesPath); err != nil {
+		return fmt.Errorf("failed to create cookies directory: %w", err)
+	}
+
+	if err := utils.EnsureDir(a.config.DefaultGraphQLPath); err != nil {
+		return fmt.Errorf("failed to create GraphQL schemas directory: %w", err)
+	}
+
 	listener, err := net.Listen("tcp", a.config.Port)
 	if err != nil {
 		a.logger.Fatalf("Failed to start listener: %v", err)
@@ -68,10 +96,17 @@ func InitPumoide() (*Pumoide, error) {
 		RateLimitBurst:          30,
 		DefaultCollectionsPath:  utils.GetDefaultCollectionsPath(),
 		DefaultEnvironmentsPath: utils.GetDefaultEnvironmentsPath(),
+		DefaultHistoryPath:      utils.GetDefaultHistoryPath(),
+		DefaultCertificatesPath: utils.GetDefaultCertificatesPath(),
+		DefaultSettingsPath:     utils.GetDefaultSettingsPath(),
+		DefaultCookiesPath:      utils.GetDefaultCookiesPath(),
+		DefaultGraphQLPath:      utils.GetDefaultGraphQLSchemasPath(),
 		LogFilePath:             utils.GetDefaultLogsPath(),
 		LogFileName:             "pumoide.log",
 		LogLevel:                "info",
 		ClientTimeout:           30 * time.Second,
+		StreamPreviewSize:       1 << 20,
+		HistoryRetention:        models.DefaultHistoryRetention,
 	}
 
 	logger := logrus.New()
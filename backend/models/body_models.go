@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+)
+
+type BodyMode string
+
+const (
+	BodyNone       BodyMode = "none"
+	BodyRaw        BodyMode = "raw"
+	BodyJSON       BodyMode = "json"
+	BodyURLEncoded BodyMode = "urlencoded"
+	BodyFormData   BodyMode = "formdata"
+	BodyBinary     BodyMode = "binary"
+	BodyGraphQL    BodyMode = "graphql"
+)
+
+type FormFieldType string
+
+const (
+	FormFieldText FormFieldType = "text"
+	FormFieldFile FormFieldType = "file"
+)
+
+type FormField struct {
+	Key   string        `json:"key"`
+	Value string        `json:"value,omitempty"`
+	Type  FormFieldType `json:"type,omitempty"`
+	// FilePath points to the local file uploaded by file fields
+	FilePath    string `json:"filePath,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// RequestBody describes a structured body. When a request has none, its raw Body string is sent as is.
+type RequestBody struct {
+	Mode BodyMode `json:"mode"`
+	Raw  string   `json:"raw,omitempty"`
+	// ContentType overrides the default Content-Type of raw and binary bodies
+	ContentType string       `json:"contentType,omitempty"`
+	URLEncoded  []FormField  `json:"urlencoded,omitempty"`
+	FormData    []FormField  `json:"formdata,omitempty"`
+	FilePath    string       `json:"filePath,omitempty"`
+	GraphQL     *GraphQLBody `json:"graphql,omitempty"`
+}
+
+func GetValidBodyModes() []BodyMode {
+	return []BodyMode{BodyNone, BodyRaw, BodyJSON, BodyURLEncoded, BodyFormData, BodyBinary, BodyGraphQL}
+}
+
+func (m BodyMode) IsValid() bool {
+	for _, validMode := range GetValidBodyModes() {
+		if m == validMode {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *RequestBody) Validate() error {
+	if !b.Mode.IsValid() {
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported body mode: %s", b.Mode), nil)
+	}
+
+	switch b.Mode {
+	case BodyURLEncoded:
+		for _, field := range b.URLEncoded {
+			if field.Key == "" {
+				return apperrors.NewAppError(http.StatusBadRequest, "Form field key cannot be empty", nil)
+			}
+			if field.Type == FormFieldFile {
+				return apperrors.NewAppError(http.StatusBadRequest, "URL-encoded bodies cannot contain files", nil)
+			}
+		}
+
+	case BodyFormData:
+		for _, field := range b.FormData {
+			if field.Key == "" {
+				return apperrors.NewAppError(http.StatusBadRequest, "Form field key cannot be empty", nil)
+			}
+			switch field.Type {
+			case "", FormFieldText:
+			case FormFieldFile:
+				if field.FilePath == "" {
+					return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("File field '%s' requires a file path", field.Key), nil)
+				}
+			default:
+				return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported form field type: %s", field.Type), nil)
+			}
+		}
+
+	case BodyBinary:
+		if b.FilePath == "" {
+			return apperrors.NewAppError(http.StatusBadRequest, "Binary bodies require a file path", nil)
+		}
+
+	case BodyGraphQL:
+		if b.GraphQL == nil {
+			return apperrors.NewAppError(http.StatusBadRequest, "GraphQL bodies require a query", nil)
+		}
+		return b.GraphQL.Validate()
+	}
+
+	return nil
+}
@@ -0,0 +1,545 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+)
+
+// bruTextBlocks are the blocks of a .bru file holding text, every other block holds key/value pairs.
+var bruTextBlocks = []string{"body:json", "body:text", "body:xml", "body:sparql", "body:graphql", "body:graphql:vars", "script:", "tests", "docs"}
+
+// bruBlock is a top level block of a .bru file, like `headers { ... }`.
+type bruBlock struct {
+	name  string
+	text  string
+	pairs []bruPair
+}
+
+// bruPair is a line of a key/value block, disabled ones start with a tilde.
+type bruPair struct {
+	key      string
+	value    string
+	disabled bool
+}
+
+type bruFile []bruBlock
+
+// parseBru splits a .bru file into its blocks. Text blocks are unindented, list blocks like
+// `vars:secret [...]` are read as pairs without values.
+func parseBru(content string) (bruFile, error) {
+	var file bruFile
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		closing := ""
+		switch {
+		case strings.HasSuffix(line, " {"), line == "{":
+			closing = "}"
+		case strings.HasSuffix(line, " ["), line == "[":
+			closing = "]"
+		default:
+			return nil, fmt.Errorf("Unexpected line %d: %s", i+1, line)
+		}
+		block := bruBlock{name: strings.TrimSpace(line[:len(line)-1])}
+
+		var content []string
+		for i++; i < len(lines) && strings.TrimRight(lines[i], " \t") != closing; i++ {
+			content = append(content, lines[i])
+		}
+		if i == len(lines) {
+			return nil, fmt.Errorf("Block %s is not closed", block.name)
+		}
+
+		if block.isText() {
+			for j, text := range content {
+				content[j] = strings.TrimPrefix(text, "  ")
+			}
+			block.text = strings.TrimSpace(strings.Join(content, "\n"))
+		} else {
+			for _, text := range content {
+				text = strings.TrimSpace(text)
+				if closing == "]" {
+					text = strings.TrimSuffix(text, ",")
+				}
+				if text == "" {
+					continue
+				}
+				pair := bruPair{}
+				if strings.HasPrefix(text, "~") {
+					pair.disabled, text = true, text[1:]
+				}
+				key, value, _ := strings.Cut(text, ":")
+				pair.key, pair.value = strings.TrimSpace(key), strings.TrimSpace(value)
+				block.pairs = append(block.pairs, pair)
+			}
+		}
+		file = append(file, block)
+	}
+	return file, nil
+}
+
+func (b bruBlock) isText() bool {
+	for _, name := range bruTextBlocks {
+		if b.name == name || (strings.HasSuffix(name, ":") && strings.HasPrefix(b.name, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f bruFile) block(name string) (bruBlock, bool) {
+	for _, block := range f {
+		if block.name == name {
+			return block, true
+		}
+	}
+	return bruBlock{}, false
+}
+
+// value returns the value of an enabled key of a pairs block.
+func (f bruFile) value(blockName string, key string) string {
+	block, _ := f.block(blockName)
+	return block.value(key)
+}
+
+func (b bruBlock) value(key string) string {
+	for _, pair := range b.pairs {
+		if pair.key == key && !pair.disabled {
+			return pair.value
+		}
+	}
+	return ""
+}
+
+// ImportBrunoCollection converts a Bruno collection, sent as a zip archive of its folder, or a
+// single .bru request. Folders become collection folders, in Bruno order. Every file of the
+// environments folder becomes an environment.
+func ImportBrunoCollection(data []byte) (*ImportResult, error) {
+	files := make(map[string]string)
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to read Bruno archive", err)
+		}
+		for _, entry := range archive.File {
+			// Only requests, folders, environments and the collection settings are read
+			if entry.FileInfo().IsDir() || (path.Ext(entry.Name) != ".bru" && path.Base(entry.Name) != "bruno.json") {
+				continue
+			}
+			content, err := readZipFile(entry)
+			if err != nil {
+				return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to read Bruno archive", err)
+			}
+			files[path.Clean(entry.Name)] = content
+		}
+	} else {
+		files["request.bru"] = string(data)
+	}
+
+	// The collection folder may be nested in the archive, bruno.json marks it
+	config := ""
+	for name := range files {
+		if path.Base(name) == "bruno.json" && (config == "" || len(name) < len(config)) {
+			config = name
+		}
+	}
+	if config == "" && files["request.bru"] == "" {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Not a Bruno collection, bruno.json is missing", nil)
+	}
+	root := path.Dir(config)
+
+	importer := brunoImporter{report: NewImportReport(), folders: make(map[string]*brunoFolder)}
+	result := &ImportResult{
+		Collection: Collection{ID: uuid.New().String(), Name: "Bruno import", Requests: []Request{}},
+	}
+	if config != "" {
+		var settings struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(files[config]), &settings); err != nil {
+			return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid bruno.json", err)
+		}
+		result.Name = defaultString(settings.Name, result.Name)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		relative := name
+		if root != "." {
+			if !strings.HasPrefix(name, root+"/") {
+				continue
+			}
+			relative = strings.TrimPrefix(name, root+"/")
+		}
+		if path.Ext(relative) != ".bru" {
+			continue
+		}
+		if err := importer.add(relative, files[name]); err != nil {
+			return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to parse Bruno collection", err)
+		}
+	}
+
+	result.Items = importer.importFolder(".", nil)
+	result.Requests = append(result.Requests, importer.requests...)
+	result.Environments = importer.environments
+	result.Report = importer.report
+	if len(result.Requests) == 0 && result.Report.Skipped == 0 {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "The collection has no requests", nil)
+	}
+
+	if err := result.Collection.Validate(); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid imported collection", err)
+	}
+	return result, nil
+}
+
+// maxBrunoFileSize bounds each decompressed file of an archive, .bru files are small text files
+const maxBrunoFileSize = 1 << 20
+
+func readZipFile(entry *zip.File) (string, error) {
+	reader, err := entry.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxBrunoFileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(content) > maxBrunoFileSize {
+		return "", fmt.Errorf("%s is larger than %d bytes", entry.Name, maxBrunoFileSize)
+	}
+	return string(content), nil
+}
+
+type brunoImporter struct {
+	report       ImportReport
+	folders      map[string]*brunoFolder
+	collection   bruFile
+	environments []*Environment
+	requests     []Request
+}
+
+type brunoFolder struct {
+	name     string
+	seq      int
+	requests []brunoRequest
+}
+
+type brunoRequest struct {
+	file bruFile
+	seq  int
+	name string
+}
+
+// add files a .bru file of the collection by its role.
+func (i *brunoImporter) add(name string, content string) error {
+	file, err := parseBru(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	dir := path.Dir(name)
+	switch {
+	case name == "collection.bru":
+		i.collection = file
+	case dir == "environments":
+		environment := &Environment{Name: strings.TrimSuffix(path.Base(name), ".bru"), Variables: make(map[string]string)}
+		vars, _ := file.block("vars")
+		for _, pair := range vars.pairs {
+			if pair.disabled {
+				i.report.Warn(environment.Name, fmt.Sprintf("Disabled variable %s was not imported", pair.key))
+				continue
+			}
+			environment.Variables[pair.key] = pair.value
+		}
+		secrets, _ := file.block("vars:secret")
+		for _, pair := range secrets.pairs {
+			environment.Variables[pair.key] = ""
+			i.report.Warn(environment.Name, fmt.Sprintf("Secret variable %s is not exported by Bruno, set its value", pair.key))
+		}
+		i.environments = append(i.environments, environment)
+	case path.Base(name) == "folder.bru":
+		folder := i.folder(dir)
+		folder.name = defaultString(file.value("meta", "name"), folder.name)
+		folder.seq, _ = strconv.Atoi(file.value("meta", "seq"))
+	default:
+		folder := i.folder(dir)
+		seq, _ := strconv.Atoi(file.value("meta", "seq"))
+		requestName := defaultString(file.value("meta", "name"), strings.TrimSuffix(path.Base(name), ".bru"))
+		folder.requests = append(folder.requests, brunoRequest{file: file, seq: seq, name: requestName})
+	}
+	return nil
+}
+
+// folder returns the folder of a directory, creating it along with its parents.
+func (i *brunoImporter) folder(dir string) *brunoFolder {
+	folder, ok := i.folders[dir]
+	if !ok {
+		folder = &brunoFolder{name: path.Base(dir)}
+		i.folders[dir] = folder
+		if dir != "." {
+			i.folder(path.Dir(dir))
+		}
+	}
+	return folder
+}
+
+// importFolder imports the subfolders of a folder, then its own requests, each in Bruno order,
+// and returns the tree items of the folder.
+func (i *brunoImporter) importFolder(dir string, folderPath []string) []CollectionItem {
+	items := []CollectionItem{}
+
+	var subfolders []string
+	for name := range i.folders {
+		if name != "." && path.Dir(name) == dir {
+			subfolders = append(subfolders, name)
+		}
+	}
+	sort.Slice(subfolders, func(a, b int) bool {
+		first, second := i.folders[subfolders[a]], i.folders[subfolders[b]]
+		if first.seq != second.seq {
+			return first.seq < second.seq
+		}
+		return first.name < second.name
+	})
+
+	for _, name := range subfolders {
+		folder := i.folders[name]
+		subPath := append(folderPath[:len(folderPath):len(folderPath)], folder.name)
+		items = append(items, CollectionItem{Folder: &Folder{
+			ID:    uuid.New().String(),
+			Name:  folder.name,
+			Items: i.importFolder(name, subPath),
+		}})
+	}
+
+	if folder, ok := i.folders[dir]; ok {
+		sort.SliceStable(folder.requests, func(a, b int) bool { return folder.requests[a].seq < folder.requests[b].seq })
+		for _, source := range folder.requests {
+			itemPath := strings.Join(append(folderPath[:len(folderPath):len(folderPath)], source.name), " / ")
+			request, err := i.convertRequest(itemPath, source.file)
+			if err != nil {
+				i.report.Skip(itemPath, err.Error())
+				continue
+			}
+			request.Name = source.name
+			i.requests = append(i.requests, request)
+			items = append(items, CollectionItem{RequestID: request.ID})
+			i.report.Imported++
+		}
+	}
+	return items
+}
+
+func (i *brunoImporter) convertRequest(name string, file bruFile) (Request, error) {
+	switch requestType := file.value("meta", "type"); requestType {
+	case "", "http", "graphql":
+	default:
+		return Request{}, fmt.Errorf("Bruno %s requests are not supported", requestType)
+	}
+
+	request := Request{ID: uuid.New().String(), Name: name}
+	var method bruBlock
+	for _, block := range file {
+		if Method(strings.ToUpper(block.name)).IsValid() {
+			request.Method, method = Method(strings.ToUpper(block.name)), block
+			break
+		}
+	}
+	if request.Method == "" {
+		return Request{}, fmt.Errorf("The request has no method block")
+	}
+	rawURL, rawQuery, _ := strings.Cut(method.value("url"), "?")
+	request.URL = i.convertURL(name, rawURL, file)
+	request.QueryParams = importedQuery(rawQuery, name, &i.report)
+	if params, ok := file.block("params:query"); ok {
+		for _, pair := range params.pairs {
+			if pair.disabled || request.QueryParams[pair.key] != "" {
+				continue
+			}
+			if request.QueryParams == nil {
+				request.QueryParams = make(map[string]string)
+			}
+			request.QueryParams[pair.key] = pair.value
+		}
+	}
+
+	headers, _ := i.collection.block("headers")
+	if len(headers.pairs) > 0 {
+		i.report.Warn(name, "Collection headers were added to the request")
+	}
+	requestHeaders, _ := file.block("headers")
+	for _, pair := range append(headers.pairs, requestHeaders.pairs...) {
+		if pair.disabled {
+			i.report.Warn(name, fmt.Sprintf("Disabled header %s was not imported", pair.key))
+			continue
+		}
+		request.Headers = append(request.Headers, Header{Key: pair.key, Value: pair.value})
+	}
+
+	request.BodyConfig = i.convertBody(name, method.value("body"), file)
+
+	authMode, authFile := method.value("auth"), file
+	if authMode == "inherit" {
+		authMode, authFile = i.collection.value("auth", "mode"), i.collection
+	}
+	request.Auth = i.convertAuth(name, authMode, authFile)
+
+	for _, block := range file {
+		switch {
+		case strings.HasPrefix(block.name, "script:"), strings.HasPrefix(block.name, "vars:"), block.name == "tests", block.name == "assert":
+			i.report.Warn(name, fmt.Sprintf("Bruno %s blocks are not supported and were not imported", block.name))
+		}
+	}
+
+	if err := request.Validate(); err != nil {
+		message := err.Error()
+		var appErr apperrors.AppError
+		if errors.As(err, &appErr) {
+			message = appErr.Message
+		}
+		return Request{}, fmt.Errorf("Invalid request: %s", message)
+	}
+	return request, nil
+}
+
+// convertURL replaces the :path parameters by their value, or by a {{variable}} when they have none.
+func (i *brunoImporter) convertURL(name string, rawURL string, file bruFile) string {
+	params, ok := file.block("params:path")
+	if !ok {
+		return rawURL
+	}
+	return postmanPathVariable.ReplaceAllStringFunc(rawURL, func(segment string) string {
+		key := segment[2:]
+		for _, pair := range params.pairs {
+			if pair.key != key {
+				continue
+			}
+			if pair.value == "" {
+				i.report.Warn(name, fmt.Sprintf("Path variable %s has no value, it was replaced by {{%s}}", key, key))
+				return "/{{" + key + "}}"
+			}
+			return "/" + pair.value
+		}
+		return segment
+	})
+}
+
+// brunoTextBodies maps the text body modes of Bruno to their content type.
+var brunoTextBodies = map[string]string{
+	"text":   "text/plain",
+	"xml":    "application/xml",
+	"sparql": "application/sparql-query",
+}
+
+func (i *brunoImporter) convertBody(name string, mode string, file bruFile) *RequestBody {
+	var body *RequestBody
+	switch mode {
+	case "", "none":
+		return nil
+	case "json":
+		block, _ := file.block("body:json")
+		body = &RequestBody{Mode: BodyJSON, Raw: block.text}
+	case "text", "xml", "sparql":
+		block, _ := file.block("body:" + mode)
+		body = &RequestBody{Mode: BodyRaw, Raw: block.text, ContentType: brunoTextBodies[mode]}
+	case "graphql":
+		query, _ := file.block("body:graphql")
+		variables, _ := file.block("body:graphql:vars")
+		body = &RequestBody{Mode: BodyGraphQL, GraphQL: &GraphQLBody{Query: query.text, Variables: variables.text}}
+	case "formUrlEncoded":
+		block, _ := file.block("body:form-urlencoded")
+		body = &RequestBody{Mode: BodyURLEncoded, URLEncoded: []FormField{}}
+		for _, pair := range block.pairs {
+			body.URLEncoded = append(body.URLEncoded, FormField{Key: pair.key, Value: pair.value, Disabled: pair.disabled})
+		}
+	case "multipartForm":
+		block, _ := file.block("body:multipart-form")
+		body = &RequestBody{Mode: BodyFormData, FormData: []FormField{}}
+		for _, pair := range block.pairs {
+			field := FormField{Key: pair.key, Value: pair.value, Disabled: pair.disabled}
+			// Files are written as @file(path), several of them separated by pipes
+			if strings.HasPrefix(pair.value, "@file(") && strings.HasSuffix(pair.value, ")") {
+				paths := strings.Split(pair.value[len("@file("):len(pair.value)-1], "|")
+				if len(paths) > 1 {
+					i.report.Warn(name, fmt.Sprintf("File field %s has %d files, only the first one was imported", pair.key, len(paths)))
+				}
+				field.Type, field.Value, field.FilePath = FormFieldFile, "", paths[0]
+			}
+			body.FormData = append(body.FormData, field)
+		}
+	default:
+		i.report.Warn(name, fmt.Sprintf("Unsupported body mode %s, the body was not imported", mode))
+		return nil
+	}
+
+	if err := body.Validate(); err != nil {
+		i.report.Warn(name, fmt.Sprintf("Invalid %s body was not imported", mode))
+		return nil
+	}
+	return body
+}
+
+func (i *brunoImporter) convertAuth(name string, mode string, file bruFile) *Auth {
+	switch mode {
+	case "", "none", "inherit":
+		return nil
+	case "basic":
+		return &Auth{Type: AuthBasic, Params: map[string]string{
+			"username": file.value("auth:basic", "username"),
+			"password": file.value("auth:basic", "password"),
+		}}
+	case "bearer":
+		return &Auth{Type: AuthBearer, Params: map[string]string{"token": file.value("auth:bearer", "token")}}
+	case "apikey":
+		in := "header"
+		if file.value("auth:apikey", "placement") == "queryparams" {
+			in = "query"
+		}
+		return &Auth{Type: AuthAPIKey, Params: map[string]string{
+			"key":   file.value("auth:apikey", "key"),
+			"value": file.value("auth:apikey", "value"),
+			"in":    in,
+		}}
+	case "awsv4":
+		return &Auth{Type: AuthAWSSigV4, Params: map[string]string{
+			"access_key":    file.value("auth:awsv4", "accessKeyId"),
+			"secret_key":    file.value("auth:awsv4", "secretAccessKey"),
+			"session_token": file.value("auth:awsv4", "sessionToken"),
+			"region":        file.value("auth:awsv4", "region"),
+			"service":       file.value("auth:awsv4", "service"),
+		}}
+	case "oauth2":
+		i.report.Warn(name, "OAuth 2.0 tokens are not part of Bruno collections, set the access token")
+		return &Auth{Type: AuthOAuth2, Params: map[string]string{"access_token": ""}}
+	case "digest":
+		i.report.Warn(name, "Digest credentials were not imported, set them along with the server realm and nonce")
+		return nil
+	}
+
+	i.report.Warn(name, fmt.Sprintf("Unsupported auth type %s, the request was imported without authentication", mode))
+	return nil
+}
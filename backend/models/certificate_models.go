@@ -0,0 +1,229 @@
+package models
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type CertificateKind string
+
+const (
+	CertificateClient CertificateKind = "client"
+	CertificateCA     CertificateKind = "ca"
+)
+
+type CertificateFormat string
+
+const (
+	CertificatePEM    CertificateFormat = "pem"
+	CertificatePKCS12 CertificateFormat = "pkcs12"
+)
+
+type Certificate struct {
+	ID   string          `json:"id"`
+	Name string          `json:"name"`
+	Kind CertificateKind `json:"kind"`
+	// HostPattern selects the hosts a client certificate is presented to, e.g. "api.example.com", "*.internal:8443" or "*"
+	HostPattern string            `json:"hostPattern,omitempty"`
+	Format      CertificateFormat `json:"format"`
+	CertPath    string            `json:"certPath,omitempty"`
+	KeyPath     string            `json:"keyPath,omitempty"`
+	PFXPath     string            `json:"pfxPath,omitempty"`
+	Passphrase  string            `json:"passphrase,omitempty"`
+}
+
+func (c *Certificate) Save(path string) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	// Entries may hold passphrases, keep them readable by the owner only
+	return os.WriteFile(filepath.Join(path, c.ID+".json"), data, 0600)
+}
+
+func LoadCertificate(path string, id string) (*Certificate, error) {
+	data, err := os.ReadFile(filepath.Join(path, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var certificate Certificate
+	err = json.Unmarshal(data, &certificate)
+	return &certificate, err
+}
+
+func ListCertificates(path string) ([]Certificate, error) {
+	files, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := []Certificate{}
+	for _, file := range files {
+		certificate, err := LoadCertificate(path, strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, *certificate)
+	}
+	return certificates, nil
+}
+
+func (c *Certificate) Validate() error {
+	if c.Name == "" {
+		return apperrors.NewAppError(http.StatusBadRequest, "Certificate name cannot be empty", nil)
+	}
+
+	switch c.Kind {
+	case CertificateCA:
+		if c.Format != CertificatePEM || c.CertPath == "" {
+			return apperrors.NewAppError(http.StatusBadRequest, "CA certificates require a PEM certificate path", nil)
+		}
+
+	case CertificateClient:
+		if c.HostPattern == "" {
+			return apperrors.NewAppError(http.StatusBadRequest, "Client certificates require a host pattern", nil)
+		}
+		if _, err := path.Match(c.HostPattern, ""); err != nil {
+			return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid host pattern: %s", c.HostPattern), err)
+		}
+
+		switch c.Format {
+		case CertificatePEM:
+			if c.CertPath == "" || c.KeyPath == "" {
+				return apperrors.NewAppError(http.StatusBadRequest, "PEM client certificates require a certificate and a key path", nil)
+			}
+		case CertificatePKCS12:
+			if c.PFXPath == "" {
+				return apperrors.NewAppError(http.StatusBadRequest, "PKCS#12 client certificates require a PFX path", nil)
+			}
+		default:
+			return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported certificate format: %s", c.Format), nil)
+		}
+
+	default:
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported certificate kind: %s", c.Kind), nil)
+	}
+
+	return nil
+}
+
+// MatchesHost reports whether a client certificate applies to host, given as "hostname" or "hostname:port".
+func (c *Certificate) MatchesHost(host string) bool {
+	if c.Kind != CertificateClient {
+		return false
+	}
+
+	pattern := strings.ToLower(c.HostPattern)
+	host = strings.ToLower(host)
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+
+	target := hostname
+	if strings.Contains(pattern, ":") {
+		target = host
+	}
+
+	matched, err := path.Match(pattern, target)
+	return err == nil && matched
+}
+
+func (c *Certificate) LoadKeyPair() (tls.Certificate, error) {
+	if c.Format == CertificatePEM {
+		return tls.LoadX509KeyPair(c.CertPath, c.KeyPath)
+	}
+
+	data, err := os.ReadFile(c.PFXPath)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	key, leaf, chain, err := pkcs12.DecodeChain(data, c.Passphrase)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	certificate := tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	for _, ca := range chain {
+		certificate.Certificate = append(certificate.Certificate, ca.Raw)
+	}
+	return certificate, nil
+}
+
+func (c *Certificate) AppendTo(pool *x509.CertPool) error {
+	data, err := os.ReadFile(c.CertPath)
+	if err != nil {
+		return err
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no PEM certificates found in %s", c.CertPath)
+	}
+	return nil
+}
+
+// NewTLSConfig builds the TLS configuration for host out of the registered certificates.
+// It returns nil when no certificate applies, so the default configuration can be kept.
+func NewTLSConfig(certificates []Certificate, host string) (*tls.Config, error) {
+	var config *tls.Config
+	var pool *x509.CertPool
+
+	for _, certificate := range certificates {
+		switch {
+		case certificate.Kind == CertificateCA:
+			if pool == nil {
+				systemPool, err := x509.SystemCertPool()
+				if err != nil {
+					systemPool = x509.NewCertPool()
+				}
+				pool = systemPool
+			}
+			if err := certificate.AppendTo(pool); err != nil {
+				return nil, fmt.Errorf("failed to load CA certificate %s: %w", certificate.Name, err)
+			}
+
+		case certificate.MatchesHost(host):
+			keyPair, err := certificate.LoadKeyPair()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate %s: %w", certificate.Name, err)
+			}
+			if config == nil {
+				config = &tls.Config{}
+			}
+			config.Certificates = append(config.Certificates, keyPair)
+		}
+	}
+
+	if pool != nil {
+		if config == nil {
+			config = &tls.Config{}
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
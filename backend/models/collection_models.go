@@ -43,20 +43,56 @@ const (
 	MethodConnect Method = "CONNECT"
 )
 
+// RequestType tells how a request is executed. An empty type is a plain HTTP request.
+type RequestType string
+
+const (
+	RequestHTTP      RequestType = "http"
+	RequestWebSocket RequestType = "websocket"
+	RequestSSE       RequestType = "sse"
+	RequestGRPC      RequestType = "grpc"
+)
+
 type Header struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
+type HTTPVersion string
+
+const (
+	HTTPVersionAuto HTTPVersion = ""
+	HTTPVersion1    HTTPVersion = "http1"
+	HTTPVersion2    HTTPVersion = "http2"
+)
+
+const DefaultMaxRedirects = 10
+
+type RequestSettings struct {
+	// FollowRedirects defaults to true when unset
+	FollowRedirects *bool `json:"followRedirects,omitempty"`
+	// MaxRedirects is the number of redirects followed, DefaultMaxRedirects when unset
+	MaxRedirects  *int        `json:"maxRedirects,omitempty"`
+	TimeoutMs     int         `json:"timeoutMs,omitempty"`
+	SkipTLSVerify bool        `json:"skipTlsVerify,omitempty"`
+	HTTPVersion   HTTPVersion `json:"httpVersion,omitempty"`
+}
+
 type Request struct {
 	ID          string            `json:"id"`
 	Name        string            `json:"name"`
+	Type        RequestType       `json:"type,omitempty"`
 	Method      Method            `json:"method"`
 	URL         string            `json:"url"`
 	Headers     []Header          `json:"headers"`
 	QueryParams map[string]string `json:"queryParams"`
 	Body        string            `json:"body"`
+	BodyConfig  *RequestBody      `json:"bodyConfig,omitempty"`
 	Auth        *Auth             `json:"auth,omitempty"`
+	Settings    *RequestSettings  `json:"settings,omitempty"`
+	GRPC        *GRPCRequest      `json:"grpc,omitempty"`
+	// Messages is the message log saved from a WebSocket session
+	Messages []WebSocketMessage `json:"messages,omitempty"`
 }
 
 type Collection struct {
@@ -64,42 +100,9 @@ type Collection struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Requests    []Request `json:"requests"`
-}
-
-type ImportedCollection struct {
-	Info struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-	} `json:"info"`
-	Item []struct {
-		Name    string `json:"name"`
-		Request struct {
-			Method string   `json:"method"`
-			URL    string   `json:"url"`
-			Header []Header `json:"header"`
-			Body   struct {
-				Mode string `json:"mode"`
-				Raw  string `json:"raw"`
-			} `json:"body"`
-		} `json:"request"`
-	} `json:"item"`
-}
-
-type ExportedCollection struct {
-	Info struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Schema      string `json:"schema"`
-	} `json:"info"`
-	Item []struct {
-		Name    string `json:"name"`
-		Request struct {
-			Method string            `json:"method"`
-			URL    string            `json:"url"`
-			Header []Header          `json:"header"`
-			Body   map[string]string `json:"body"`
-		} `json:"request"`
-	} `json:"item"`
+	// Items orders the requests in a tree of folders. Collections saved without it get all
+	// their requests at the root when loaded.
+	Items []CollectionItem `json:"items"`
 }
 
 func (c *Collection) Save(path string) error {
@@ -110,6 +113,13 @@ func (c *Collection) Save(path string) error {
 	if c.ID == "" {
 		c.ID = uuid.New().String()
 	}
+	// The tree references requests by their ID
+	for i := range c.Requests {
+		if c.Requests[i].ID == "" {
+			c.Requests[i].ID = uuid.New().String()
+		}
+	}
+	c.Items = c.tree()
 	data, err := json.Marshal(c)
 	if err != nil {
 		return err
@@ -123,8 +133,11 @@ func LoadCollection(path string, id string) (*Collection, error) {
 		return nil, err
 	}
 	var collection Collection
-	err = json.Unmarshal(data, &collection)
-	return &collection, err
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return &collection, err
+	}
+	collection.Items = collection.tree()
+	return &collection, nil
 }
 
 func (c *Collection) AddRequest(request Request) error {
@@ -136,89 +149,36 @@ func (c *Collection) AddRequest(request Request) error {
 		request.ID = uuid.New().String()
 	}
 	c.Requests = append(c.Requests, request)
+	c.Items = c.tree()
 	return nil
 }
 
-func (c *Collection) RemoveRequest(requestID string) bool {
-	for i, req := range c.Requests {
-		if req.ID == requestID {
-			c.Requests = append(c.Requests[:i], c.Requests[i+1:]...)
-			return true
+func (c *Collection) FindRequest(requestID string) (*Request, bool) {
+	for i := range c.Requests {
+		if c.Requests[i].ID == requestID {
+			return &c.Requests[i], true
 		}
 	}
-	return false
+	return nil, false
 }
 
-func (c *Collection) ToExportedCollection() ExportedCollection {
-	exported := ExportedCollection{}
-	exported.Info.Name = c.Name
-	exported.Info.Description = c.Description
-	exported.Info.Schema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
-
+func (c *Collection) RequestIDs() []string {
+	ids := make([]string, 0, len(c.Requests))
 	for _, req := range c.Requests {
-		item := struct {
-			Name    string `json:"name"`
-			Request struct {
-				Method string            `json:"method"`
-				URL    string            `json:"url"`
-				Header []Header          `json:"header"`
-				Body   map[string]string `json:"body"`
-			} `json:"request"`
-		}{
-			Name: req.Name,
-			Request: struct {
-				Method string            `json:"method"`
-				URL    string            `json:"url"`
-				Header []Header          `json:"header"`
-				Body   map[string]string `json:"body"`
-			}{
-				Method: string(req.Method),
-				URL:    req.URL,
-				Header: req.Headers,
-				Body: map[string]string{
-					"mode": "raw",
-					"raw":  req.Body,
-				},
-			},
-		}
-		exported.Item = append(exported.Item, item)
+		ids = append(ids, req.ID)
 	}
-
-	return exported
+	return ids
 }
 
-func NewCollectionFromImported(imported ImportedCollection) (Collection, error) {
-	newCollection := Collection{
-		ID:          uuid.New().String(),
-		Name:        imported.Info.Name,
-		Description: imported.Info.Description,
-	}
-
-	for _, item := range imported.Item {
-		newRequest := Request{
-			ID:      uuid.New().String(),
-			Name:    item.Name,
-			Method:  Method(item.Request.Method),
-			URL:     item.Request.URL,
-			Headers: item.Request.Header,
-		}
-
-		if item.Request.Body.Mode == "raw" {
-			newRequest.Body = item.Request.Body.Raw
-		}
-
-		if err := newRequest.Validate(); err != nil {
-			return Collection{}, apperrors.NewAppError(http.StatusBadRequest, "Invalid imported request", err)
+func (c *Collection) RemoveRequest(requestID string) bool {
+	for i, req := range c.Requests {
+		if req.ID == requestID {
+			c.Requests = append(c.Requests[:i], c.Requests[i+1:]...)
+			detachItem(&c.Items, requestID)
+			return true
 		}
-
-		newCollection.Requests = append(newCollection.Requests, newRequest)
-	}
-
-	if err := newCollection.Validate(); err != nil {
-		return Collection{}, apperrors.NewAppError(http.StatusBadRequest, "Invalid imported collection", err)
 	}
-
-	return newCollection, nil
+	return false
 }
 
 func GetValidMethods() []Method {
@@ -244,12 +204,26 @@ func (m Method) IsValid() bool {
 	return false
 }
 
+// IsHTTP reports whether the request is sent as a regular HTTP request, which is the case of event streams.
+func (t RequestType) IsHTTP() bool {
+	return t == "" || t == RequestHTTP || t == RequestSSE
+}
+
+func (t RequestType) IsValid() bool {
+	return t.IsHTTP() || t == RequestWebSocket || t == RequestGRPC
+}
+
 func (r *Request) Validate() error {
 	if r.Name == "" {
 		return apperrors.NewAppError(http.StatusBadRequest, "Request name cannot be empty", nil)
 	}
 
-	if !r.Method.IsValid() {
+	if !r.Type.IsValid() {
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported request type: %s", r.Type), nil)
+	}
+
+	// The method is only meaningful for HTTP requests, other types open their connection with GET
+	if r.Type.IsHTTP() && !r.Method.IsValid() {
 		return apperrors.NewAppError(http.StatusMethodNotAllowed, fmt.Sprintf("Invalid HTTP method: %s", r.Method), nil)
 	}
 
@@ -269,6 +243,56 @@ func (r *Request) Validate() error {
 		}
 	}
 
+	if r.BodyConfig != nil {
+		if err := r.BodyConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.Settings != nil {
+		if err := r.Settings.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.Type == RequestGRPC {
+		if r.GRPC == nil {
+			return apperrors.NewAppError(http.StatusBadRequest, "gRPC requests require a gRPC configuration", nil)
+		}
+		if err := r.GRPC.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *RequestSettings) ShouldFollowRedirects() bool {
+	return s == nil || s.FollowRedirects == nil || *s.FollowRedirects
+}
+
+func (s *RequestSettings) RedirectLimit() int {
+	if s == nil || s.MaxRedirects == nil {
+		return DefaultMaxRedirects
+	}
+	return *s.MaxRedirects
+}
+
+func (s *RequestSettings) Validate() error {
+	if s.MaxRedirects != nil && *s.MaxRedirects < 0 {
+		return apperrors.NewAppError(http.StatusBadRequest, "Max redirects cannot be negative", nil)
+	}
+
+	if s.TimeoutMs < 0 {
+		return apperrors.NewAppError(http.StatusBadRequest, "Timeout cannot be negative", nil)
+	}
+
+	switch s.HTTPVersion {
+	case HTTPVersionAuto, HTTPVersion1, HTTPVersion2:
+	default:
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported HTTP version: %s", s.HTTPVersion), nil)
+	}
+
 	return nil
 }
 
@@ -340,5 +364,5 @@ func (c *Collection) Validate() error {
 		}
 	}
 
-	return nil
+	return validateFolders(c.Items)
 }
@@ -0,0 +1,251 @@
+package models
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultCookieJarID = "default"
+
+// CookieJar is a persistent http.CookieJar, one per environment.
+type CookieJar struct {
+	mu      sync.Mutex
+	ID      string   `json:"id"`
+	Entries []Cookie `json:"cookies"`
+}
+
+func NewCookieJar(id string) *CookieJar {
+	if id == "" {
+		id = DefaultCookieJarID
+	}
+	return &CookieJar{ID: id, Entries: []Cookie{}}
+}
+
+func (j *CookieJar) Save(path string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.removeExpired(time.Now())
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, j.ID+".json"), data, 0600)
+}
+
+// LoadCookieJar returns the jar stored for id, or an empty one if nothing was stored yet.
+func LoadCookieJar(path string, id string) (*CookieJar, error) {
+	jar := NewCookieJar(id)
+
+	data, err := os.ReadFile(filepath.Join(path, jar.ID+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return jar, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, jar)
+	return jar, err
+}
+
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	host := canonicalHost(u.Hostname())
+
+	for _, c := range cookies {
+		cookie := NewCookie(c)
+
+		if cookie.Domain == "" {
+			cookie.Domain = host
+			cookie.HostOnly = true
+		} else {
+			cookie.Domain = canonicalHost(strings.TrimPrefix(cookie.Domain, "."))
+			if !domainMatches(host, cookie.Domain) {
+				continue
+			}
+		}
+
+		if cookie.Path == "" || !strings.HasPrefix(cookie.Path, "/") {
+			cookie.Path = defaultCookiePath(u.Path)
+		}
+
+		if c.MaxAge > 0 {
+			expires := now.Add(time.Duration(c.MaxAge) * time.Second)
+			cookie.Expires = &expires
+		}
+
+		j.remove(cookie.Name, cookie.Domain, cookie.Path)
+		if c.MaxAge < 0 || (cookie.Expires != nil && !cookie.Expires.After(now)) {
+			continue
+		}
+		j.Entries = append(j.Entries, cookie)
+	}
+}
+
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	host := canonicalHost(u.Hostname())
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+
+	requestPath := u.Path
+	if requestPath == "" {
+		requestPath = "/"
+	}
+
+	var matching []Cookie
+	for _, cookie := range j.Entries {
+		if cookie.Expires != nil && !cookie.Expires.After(now) {
+			continue
+		}
+		if cookie.Secure && !secure {
+			continue
+		}
+		if cookie.HostOnly && host != cookie.Domain {
+			continue
+		}
+		if !cookie.HostOnly && !domainMatches(host, cookie.Domain) {
+			continue
+		}
+		if !pathMatches(requestPath, cookie.Path) {
+			continue
+		}
+		matching = append(matching, cookie)
+	}
+
+	// More specific paths first, as browsers do
+	sort.SliceStable(matching, func(a, b int) bool {
+		return len(matching[a].Path) > len(matching[b].Path)
+	})
+
+	cookies := make([]*http.Cookie, 0, len(matching))
+	for _, cookie := range matching {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return cookies
+}
+
+// Put adds or replaces a cookie set by hand.
+func (j *CookieJar) Put(cookie Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	cookie.Domain = canonicalHost(strings.TrimPrefix(cookie.Domain, "."))
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+
+	j.remove(cookie.Name, cookie.Domain, cookie.Path)
+	j.Entries = append(j.Entries, cookie)
+}
+
+// RemoveDomain removes the cookies of domain, only the one called name if it is not empty.
+// It returns the number of removed cookies.
+func (j *CookieJar) RemoveDomain(domain string, name string) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	domain = canonicalHost(strings.TrimPrefix(domain, "."))
+	kept := j.Entries[:0]
+	removed := 0
+	for _, cookie := range j.Entries {
+		if cookie.Domain == domain && (name == "" || cookie.Name == name) {
+			removed++
+			continue
+		}
+		kept = append(kept, cookie)
+	}
+	j.Entries = kept
+	return removed
+}
+
+func (j *CookieJar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Entries = []Cookie{}
+}
+
+// List returns a copy of the stored cookies, only those of domain if it is not empty.
+func (j *CookieJar) List(domain string) []Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	domain = canonicalHost(strings.TrimPrefix(domain, "."))
+	cookies := []Cookie{}
+	for _, cookie := range j.Entries {
+		if domain == "" || cookie.Domain == domain {
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies
+}
+
+func (j *CookieJar) remove(name, domain, cookiePath string) {
+	for i, cookie := range j.Entries {
+		if cookie.Name == name && cookie.Domain == domain && cookie.Path == cookiePath {
+			j.Entries = append(j.Entries[:i], j.Entries[i+1:]...)
+			return
+		}
+	}
+}
+
+func (j *CookieJar) removeExpired(now time.Time) {
+	kept := j.Entries[:0]
+	for _, cookie := range j.Entries {
+		if cookie.Expires == nil || cookie.Expires.After(now) {
+			kept = append(kept, cookie)
+		}
+	}
+	j.Entries = kept
+}
+
+func canonicalHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+func domainMatches(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	// IP addresses only ever match exactly
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	return strings.HasSuffix(host, "."+domain)
+}
+
+func pathMatches(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+func defaultCookiePath(requestPath string) string {
+	if requestPath == "" || !strings.HasPrefix(requestPath, "/") {
+		return "/"
+	}
+	dir := path.Dir(requestPath)
+	if dir == "." {
+		return "/"
+	}
+	return dir
+}
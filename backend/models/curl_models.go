@@ -0,0 +1,699 @@
+package models
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+)
+
+// curlOptions maps the short options understood by the parser to their long names.
+var curlOptions = map[byte]string{
+	'X': "request",
+	'H': "header",
+	'd': "data",
+	'F': "form",
+	'u': "user",
+	'k': "insecure",
+	'L': "location",
+	'G': "get",
+	'I': "head",
+	'b': "cookie",
+	'A': "user-agent",
+	'e': "referer",
+	'm': "max-time",
+	'T': "upload-file",
+	'x': "proxy",
+	'E': "cert",
+	'o': "output",
+	'O': "remote-name",
+	'c': "cookie-jar",
+	'w': "write-out",
+	's': "silent",
+	'S': "show-error",
+	'v': "verbose",
+	'i': "include",
+	'f': "fail",
+	'N': "no-buffer",
+	'#': "progress-bar",
+}
+
+// curlArguments are the long options followed by an argument.
+var curlArguments = map[string]bool{
+	"request": true, "header": true, "data": true, "data-raw": true, "data-ascii": true,
+	"data-binary": true, "data-urlencode": true, "form": true, "form-string": true, "user": true,
+	"cookie": true, "user-agent": true, "referer": true, "max-time": true, "max-redirs": true,
+	"url": true, "upload-file": true, "aws-sigv4": true, "oauth2-bearer": true, "proxy": true,
+	"proxy-user": true, "cert": true, "key": true, "cacert": true, "output": true,
+	"cookie-jar": true, "write-out": true, "connect-timeout": true, "retry": true, "resolve": true,
+	"connect-to": true, "interface": true, "limit-rate": true,
+}
+
+// curlIgnored are options that don't change the request sent, or match how requests are executed:
+// redirects are followed and responses decompressed.
+var curlIgnored = map[string]bool{
+	"location": true, "compressed": true, "silent": true, "show-error": true, "verbose": true, "include": true,
+	"fail": true, "fail-with-body": true, "no-buffer": true, "progress-bar": true,
+	"no-progress-meter": true, "output": true, "remote-name": true, "cookie-jar": true,
+	"write-out": true, "connect-timeout": true, "retry": true, "limit-rate": true,
+}
+
+// curlUnsupported are options changing how the request is sent that have no equivalent.
+var curlUnsupported = map[string]bool{
+	"proxy": true, "proxy-user": true, "cert": true, "key": true, "cacert": true,
+	"resolve": true, "connect-to": true, "interface": true,
+}
+
+type curlParser struct {
+	report     ImportReport
+	req        Request
+	url        string
+	method     string
+	headers    []Header
+	data       []string
+	dataFiles  []string
+	form       []FormField
+	uploadFile string
+	user       string
+	digest     bool
+	awsSigV4   string
+	get        bool
+	head       bool
+}
+
+// ParseCurlCommand converts a curl command line into a request, as copied from a browser or a
+// terminal. Options without an equivalent are reported and ignored.
+func ParseCurlCommand(command string) (*RequestImportResult, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid command", err)
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Command must start with curl", nil)
+	}
+
+	p := &curlParser{report: NewImportReport()}
+	if err := p.parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if p.url == "" {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Command has no URL", nil)
+	}
+
+	req := p.request()
+	if err := req.Validate(); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid request: %s", err.Error()), err)
+	}
+
+	p.report.Imported = 1
+	return &RequestImportResult{Request: req, Report: p.report}, nil
+}
+
+func (p *curlParser) parse(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		var name, value string
+		hasValue := false
+		switch {
+		case strings.HasPrefix(arg, "--") && len(arg) > 2:
+			name = arg[2:]
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Short options can be grouped, the first one taking an argument ends the group
+			for j := 1; j < len(arg); j++ {
+				long, ok := curlOptions[arg[j]]
+				if !ok {
+					p.report.Warn("", fmt.Sprintf("Option -%c was ignored", arg[j]))
+					continue
+				}
+				if curlArguments[long] && j+1 < len(arg) {
+					name, value, hasValue = long, arg[j+1:], true
+					break
+				}
+				if j == len(arg)-1 {
+					name = long
+				} else if err := p.option(long, ""); err != nil {
+					return err
+				}
+			}
+			if name == "" {
+				continue
+			}
+		default:
+			if p.url != "" {
+				p.report.Warn("", fmt.Sprintf("Argument %s was ignored", arg))
+				continue
+			}
+			p.url = arg
+			continue
+		}
+
+		if curlArguments[name] && !hasValue {
+			if i+1 >= len(args) {
+				return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Option --%s requires an argument", name), nil)
+			}
+			i++
+			value = args[i]
+		}
+		if err := p.option(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *curlParser) option(name string, value string) error {
+	switch name {
+	case "request":
+		p.method = strings.ToUpper(value)
+	case "url":
+		p.url = value
+	case "header":
+		p.header(value)
+	case "data", "data-ascii", "data-binary", "data-raw":
+		p.data = append(p.data, value)
+		if file, ok := strings.CutPrefix(value, "@"); ok && name != "data-raw" {
+			p.dataFiles = append(p.dataFiles, file)
+		}
+	case "data-urlencode":
+		p.data = append(p.data, curlURLEncode(value))
+	case "form", "form-string":
+		p.formField(value, name == "form-string")
+	case "upload-file":
+		p.uploadFile = value
+	case "user":
+		p.user = value
+	case "digest":
+		p.digest = true
+	case "basic":
+		p.digest = false
+	case "aws-sigv4":
+		p.awsSigV4 = value
+	case "oauth2-bearer":
+		p.req.Auth = &Auth{Type: AuthBearer, Params: map[string]string{"token": value}}
+	case "cookie":
+		if !strings.Contains(value, "=") {
+			p.report.Warn("", fmt.Sprintf("Cookie file %s was not imported", value))
+			break
+		}
+		p.headers = append(p.headers, Header{Key: "Cookie", Value: value})
+	case "user-agent":
+		p.headers = append(p.headers, Header{Key: "User-Agent", Value: value})
+	case "referer":
+		p.headers = append(p.headers, Header{Key: "Referer", Value: value})
+	case "get":
+		p.get = true
+	case "head":
+		p.head = true
+	case "insecure":
+		p.settings().SkipTLSVerify = true
+	case "max-redirs":
+		maxRedirects, err := strconv.Atoi(value)
+		if err != nil {
+			return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid --max-redirs value: %s", value), err)
+		}
+		p.settings().MaxRedirects = &maxRedirects
+	case "max-time":
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid --max-time value: %s", value), err)
+		}
+		p.settings().TimeoutMs = int(seconds * 1000)
+	case "http1.1", "http1.0":
+		p.settings().HTTPVersion = HTTPVersion1
+	case "http2", "http2-prior-knowledge":
+		p.settings().HTTPVersion = HTTPVersion2
+	default:
+		switch {
+		case curlIgnored[name]:
+		case curlUnsupported[name]:
+			p.report.Warn("", fmt.Sprintf("Option --%s is not supported and was ignored", name))
+		default:
+			p.report.Warn("", fmt.Sprintf("Option --%s was ignored", name))
+		}
+	}
+	return nil
+}
+
+func (p *curlParser) settings() *RequestSettings {
+	if p.req.Settings == nil {
+		p.req.Settings = &RequestSettings{}
+	}
+	return p.req.Settings
+}
+
+// header adds a header line, "Name;" sends an empty header and "Name:" removes a default one.
+func (p *curlParser) header(line string) {
+	key, value, found := strings.Cut(line, ":")
+	if !found {
+		if key, ok := strings.CutSuffix(line, ";"); ok {
+			p.headers = append(p.headers, Header{Key: strings.TrimSpace(key)})
+		} else {
+			p.report.Warn("", fmt.Sprintf("Header %s was not imported", line))
+		}
+		return
+	}
+
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+
+	if strings.EqualFold(key, "Authorization") && p.req.Auth == nil {
+		if auth := authFromHeader(value); auth != nil {
+			p.req.Auth = auth
+			return
+		}
+	}
+
+	p.headers = append(p.headers, Header{Key: key, Value: value})
+}
+
+// formField parses the -F syntax: name=value, name=@file and name=<file, with ;type= and
+// ;filename= attributes.
+func (p *curlParser) formField(value string, literal bool) {
+	key, content, _ := strings.Cut(value, "=")
+	if literal {
+		p.form = append(p.form, FormField{Key: key, Value: content})
+		return
+	}
+
+	attributes := ""
+	if strings.HasPrefix(content, "@") || strings.HasPrefix(content, "<") {
+		content, attributes, _ = strings.Cut(content, ";")
+	} else if index := strings.LastIndex(content, ";type="); index >= 0 {
+		content, attributes = content[:index], content[index+1:]
+	}
+
+	field := FormField{Key: key, Value: content}
+	for _, attribute := range strings.Split(attributes, ";") {
+		if contentType, ok := strings.CutPrefix(attribute, "type="); ok {
+			field.ContentType = contentType
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(content, "@"):
+		field.Type, field.Value, field.FilePath = FormFieldFile, "", content[1:]
+	case strings.HasPrefix(content, "<"):
+		p.report.Warn("", fmt.Sprintf("Form field %s reads its value from %s, it was imported as a file", key, content[1:]))
+		field.Type, field.Value, field.FilePath = FormFieldFile, "", content[1:]
+	}
+	p.form = append(p.form, field)
+}
+
+// curlURLEncode encodes a --data-urlencode argument the way curl does.
+func curlURLEncode(value string) string {
+	if strings.HasPrefix(value, "=") {
+		return curlEscape(value[1:])
+	}
+	if key, content, ok := strings.Cut(value, "="); ok {
+		return key + "=" + curlEscape(content)
+	}
+	return curlEscape(value)
+}
+
+func curlEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func (p *curlParser) request() Request {
+	req := p.req
+	req.ID = uuid.New().String()
+	req.Headers = p.headers
+
+	rawURL, _, _ := strings.Cut(p.url, "#")
+	if !strings.Contains(rawURL, "://") && !strings.HasPrefix(rawURL, "{{") {
+		rawURL = "http://" + rawURL
+	}
+	rawURL, rawQuery, _ := strings.Cut(rawURL, "?")
+	req.URL = rawURL
+
+	if p.get && len(p.data) > 0 {
+		rawQuery = strings.Trim(rawQuery+"&"+strings.Join(p.data, "&"), "&")
+		p.data = nil
+	}
+	req.QueryParams = importedQuery(rawQuery, "", &p.report)
+
+	p.body(&req)
+	p.auth(&req)
+
+	switch {
+	case p.method != "":
+		req.Method = Method(p.method)
+	case p.head:
+		req.Method = MethodHead
+	case p.uploadFile != "":
+		req.Method = MethodPut
+	case len(p.data) > 0 || len(p.form) > 0:
+		req.Method = MethodPost
+	default:
+		req.Method = MethodGet
+	}
+
+	name := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		name = parsed.Host + parsed.Path
+	}
+	req.Name = fmt.Sprintf("%s %s", req.Method, name)
+
+	return req
+}
+
+// body picks the body mode from the data options and the Content-Type header, which is dropped
+// when the mode sets it.
+func (p *curlParser) body(req *Request) {
+	contentType := ""
+	contentTypeIndex := -1
+	for i, header := range req.Headers {
+		if strings.EqualFold(header.Key, "Content-Type") {
+			contentType, contentTypeIndex = header.Value, i
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	dropContentType := func() {
+		if contentTypeIndex >= 0 {
+			req.Headers = append(req.Headers[:contentTypeIndex], req.Headers[contentTypeIndex+1:]...)
+		}
+		if len(req.Headers) == 0 {
+			req.Headers = nil
+		}
+	}
+
+	switch {
+	case len(p.form) > 0:
+		if len(p.data) > 0 {
+			p.report.Warn("", "Data options can't be mixed with form fields, they were not imported")
+		}
+		req.BodyConfig = &RequestBody{Mode: BodyFormData, FormData: p.form}
+		if mediaType == "multipart/form-data" {
+			dropContentType()
+		}
+
+	case p.uploadFile != "":
+		req.BodyConfig = &RequestBody{Mode: BodyBinary, FilePath: p.uploadFile, ContentType: contentType}
+		dropContentType()
+
+	case len(p.data) == 1 && len(p.dataFiles) == 1:
+		req.BodyConfig = &RequestBody{Mode: BodyBinary, FilePath: p.dataFiles[0], ContentType: contentType}
+		if contentType == "" {
+			req.BodyConfig.ContentType = "application/x-www-form-urlencoded"
+		}
+		dropContentType()
+
+	case len(p.data) > 0:
+		data := strings.Join(p.data, "&")
+		for _, file := range p.dataFiles {
+			p.report.Warn("", fmt.Sprintf("Data file %s was not read, its name was imported instead", file))
+		}
+
+		req.BodyConfig = importedBody(contentType, data)
+		dropContentType()
+	}
+}
+
+func (p *curlParser) auth(req *Request) {
+	if p.user == "" {
+		return
+	}
+	username, password, _ := strings.Cut(p.user, ":")
+
+	switch {
+	case p.awsSigV4 != "":
+		// The argument is provider1[:provider2[:region[:service]]]
+		parts := strings.Split(p.awsSigV4, ":")
+		params := map[string]string{"access_key": username, "secret_key": password}
+		if len(parts) > 2 {
+			params["region"] = parts[2]
+		}
+		if len(parts) > 3 {
+			params["service"] = parts[3]
+		}
+		req.Auth = &Auth{Type: AuthAWSSigV4, Params: params}
+	case p.digest:
+		// curl answers the server challenge itself, digest auth here needs its realm and nonce upfront
+		p.report.Warn("", "Digest credentials were not imported, set them along with the server realm and nonce")
+	default:
+		req.Auth = &Auth{Type: AuthBasic, Params: map[string]string{"username": username, "password": password}}
+	}
+}
+
+// splitShellWords splits a command line the way a POSIX shell does, supporting single, double and
+// $'...' quotes and backslash line continuations.
+func splitShellWords(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		case c == '\\':
+			inWord = true
+			if i+1 < len(command) {
+				i++
+				if command[i] == '\n' {
+					// A line continuation separates words if nothing else does
+					if word.Len() == 0 {
+						inWord = false
+					}
+					continue
+				}
+				if command[i] == '\r' && i+1 < len(command) && command[i+1] == '\n' {
+					i++
+					if word.Len() == 0 {
+						inWord = false
+					}
+					continue
+				}
+				word.WriteByte(command[i])
+			}
+
+		case c == '\'':
+			inWord = true
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+
+		case c == '$' && i+1 < len(command) && command[i+1] == '\'':
+			inWord = true
+			end, err := readANSIQuote(command, i+2, &word)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '"':
+			inWord = true
+			closed := false
+			for i++; i < len(command); i++ {
+				if command[i] == '"' {
+					closed = true
+					break
+				}
+				if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("$`\"\\\n", command[i+1]) >= 0 {
+					i++
+					if command[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(command[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+
+		default:
+			inWord = true
+			word.WriteByte(c)
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// readANSIQuote decodes a $'...' string starting at start and returns the index of its closing quote.
+func readANSIQuote(command string, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(command); i++ {
+		c := command[i]
+		if c == '\'' {
+			return i, nil
+		}
+		if c != '\\' || i+1 >= len(command) {
+			word.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch command[i] {
+		case 'n':
+			word.WriteByte('\n')
+		case 't':
+			word.WriteByte('\t')
+		case 'r':
+			word.WriteByte('\r')
+		case 'a':
+			word.WriteByte('\a')
+		case 'b':
+			word.WriteByte('\b')
+		case 'e', 'E':
+			word.WriteByte(0x1b)
+		case 'f':
+			word.WriteByte('\f')
+		case 'v':
+			word.WriteByte('\v')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[command[i]]
+			end := i + 1
+			for end < len(command) && end < i+1+digits && strings.IndexByte("0123456789abcdefABCDEF", command[end]) >= 0 {
+				end++
+			}
+			value, err := strconv.ParseUint(command[i+1:end], 16, 32)
+			if err != nil {
+				word.WriteByte('\\')
+				word.WriteByte(command[i])
+				continue
+			}
+			if command[i] == 'x' {
+				word.WriteByte(byte(value))
+			} else {
+				word.WriteRune(rune(value))
+			}
+			i = end - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			end := i
+			for end < len(command) && end < i+3 && command[end] >= '0' && command[end] <= '7' {
+				end++
+			}
+			value, _ := strconv.ParseUint(command[i:end], 8, 8)
+			word.WriteByte(byte(value))
+			i = end - 1
+		default:
+			// \\, \', \" and \? stand for the character itself
+			word.WriteByte(command[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+// ToCurlCommand renders the request as a curl command, with the variables of the environment
+// resolved. Redirects are followed and responses decompressed, as when the request is executed.
+func (r *Request) ToCurlCommand(env *Environment) string {
+	resolved := r.resolve(env)
+	lines := []string{"curl " + shellQuote(resolved.URL)}
+
+	switch {
+	case resolved.Method == MethodHead:
+		lines = append(lines, "--head")
+	case resolved.Method == MethodGet && resolved.Body.Mode == BodyNone, resolved.Method == MethodPost && resolved.Body.Mode != BodyNone:
+	default:
+		lines = append(lines, curlOption("--request", string(resolved.Method)))
+	}
+
+	settings := resolved.Settings
+	if settings.ShouldFollowRedirects() {
+		lines = append(lines, "--location")
+		if settings.MaxRedirects != nil {
+			lines = append(lines, curlOption("--max-redirs", strconv.Itoa(*settings.MaxRedirects)))
+		}
+	}
+	if settings.SkipTLSVerify {
+		lines = append(lines, "--insecure")
+	}
+	if settings.TimeoutMs > 0 {
+		lines = append(lines, curlOption("--max-time", formatSeconds(settings.TimeoutMs)))
+	}
+	switch settings.HTTPVersion {
+	case HTTPVersion1:
+		lines = append(lines, "--http1.1")
+	case HTTPVersion2:
+		lines = append(lines, "--http2")
+	}
+	if headerValue(r.Headers, "Accept-Encoding") == "" {
+		lines = append(lines, "--compressed")
+	}
+
+	if auth := resolved.Auth; auth != nil {
+		switch auth.Type {
+		case AuthBasic:
+			lines = append(lines, curlOption("--user", auth.Params["username"]+":"+auth.Params["password"]))
+		case AuthDigest:
+			lines = append(lines, "--digest", curlOption("--user", auth.Params["username"]+":"+auth.Params["password"]))
+		case AuthAWSSigV4:
+			lines = append(lines,
+				curlOption("--aws-sigv4", "aws:amz:"+auth.Params["region"]+":"+auth.Params["service"]),
+				curlOption("--user", auth.Params["access_key"]+":"+auth.Params["secret_key"]))
+		}
+	}
+
+	for _, header := range resolved.Headers {
+		if header.Value == "" {
+			lines = append(lines, curlOption("--header", header.Key+";"))
+		} else {
+			lines = append(lines, curlOption("--header", header.Key+": "+header.Value))
+		}
+	}
+
+	switch resolved.Body.Mode {
+	case BodyRaw:
+		lines = append(lines, curlOption("--data-raw", resolved.Body.Text))
+	case BodyURLEncoded:
+		for _, field := range resolved.Body.Fields {
+			lines = append(lines, curlOption("--data-urlencode", curlEscape(field.Key)+"="+field.Value))
+		}
+	case BodyFormData:
+		for _, field := range resolved.Body.Fields {
+			lines = append(lines, curlFormField(field))
+		}
+	case BodyBinary:
+		lines = append(lines, curlOption("--data-binary", "@"+resolved.Body.FilePath))
+	}
+
+	return strings.Join(lines, " \\\n  ")
+}
+
+func curlOption(name string, value string) string {
+	return name + " " + shellQuote(value)
+}
+
+func curlFormField(field FormField) string {
+	if field.Type == FormFieldFile {
+		value := field.Key + "=@" + field.FilePath
+		if field.ContentType != "" {
+			value += ";type=" + field.ContentType
+		}
+		return curlOption("--form", value)
+	}
+
+	// --form-string doesn't treat @ and < as files, but can't carry a content type
+	if field.ContentType == "" {
+		return curlOption("--form-string", field.Key+"="+field.Value)
+	}
+	return curlOption("--form", field.Key+"="+field.Value+";type="+field.ContentType)
+}
+
+// shellQuote quotes a word for POSIX shells, leaving the simple ones bare.
+func shellQuote(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@,+%") == "" {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +13,7 @@ type Environment struct {
 	ID        string            `json:"id"`
 	Name      string            `json:"name"`
 	Variables map[string]string `json:"variables"`
+	Proxy     *ProxySettings    `json:"proxy,omitempty"`
 }
 
 func (e *Environment) Save(path string) error {
@@ -22,7 +24,18 @@ func (e *Environment) Save(path string) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(path, e.ID+".json"), data, 0644)
+
+	// Like the global proxy settings, proxy credentials are only readable by the owner
+	var mode os.FileMode = 0644
+	if e.Proxy != nil && (e.Proxy.Username != "" || e.Proxy.Password != "") {
+		mode = 0600
+	}
+	file := filepath.Join(path, e.ID+".json")
+	if err := os.WriteFile(file, data, mode); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of existing files
+	return os.Chmod(file, mode)
 }
 
 func LoadEnvironment(path string, id string) (*Environment, error) {
@@ -34,3 +47,14 @@ func LoadEnvironment(path string, id string) (*Environment, error) {
 	err = json.Unmarshal(data, &environment)
 	return &environment, err
 }
+
+// Resolve replaces the {{variables}} of the input with their values, a nil environment leaves it as is.
+func (e *Environment) Resolve(input string) string {
+	if e == nil {
+		return input
+	}
+	for key, value := range e.Variables {
+		input = strings.ReplaceAll(input, "{{"+key+"}}", value)
+	}
+	return input
+}
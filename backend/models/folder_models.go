@@ -0,0 +1,240 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+)
+
+// CollectionItem is an entry of the collection tree, either a folder or one of the collection
+// requests, referenced by its ID.
+type CollectionItem struct {
+	Folder    *Folder `json:"folder,omitempty"`
+	RequestID string  `json:"requestId,omitempty"`
+}
+
+type Folder struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Items       []CollectionItem `json:"items"`
+}
+
+func (item CollectionItem) ID() string {
+	if item.Folder != nil {
+		return item.Folder.ID
+	}
+	return item.RequestID
+}
+
+// tree returns the items of the collection in line with its requests: references to missing
+// requests are dropped and requests left out of the tree, like all of those of collections saved
+// before folders existed, are added at the root in their order.
+func (c *Collection) tree() []CollectionItem {
+	requests := make(map[string]bool, len(c.Requests))
+	for _, req := range c.Requests {
+		requests[req.ID] = true
+	}
+
+	placed := make(map[string]bool, len(c.Requests))
+	items := syncItems(c.Items, requests, placed)
+	for _, req := range c.Requests {
+		if !placed[req.ID] {
+			placed[req.ID] = true
+			items = append(items, CollectionItem{RequestID: req.ID})
+		}
+	}
+	return items
+}
+
+func syncItems(items []CollectionItem, requests map[string]bool, placed map[string]bool) []CollectionItem {
+	synced := []CollectionItem{}
+	for _, item := range items {
+		if item.Folder != nil {
+			folder := *item.Folder
+			if folder.ID == "" {
+				folder.ID = uuid.New().String()
+			}
+			folder.Items = syncItems(folder.Items, requests, placed)
+			synced = append(synced, CollectionItem{Folder: &folder})
+			continue
+		}
+		if requests[item.RequestID] && !placed[item.RequestID] {
+			placed[item.RequestID] = true
+			synced = append(synced, CollectionItem{RequestID: item.RequestID})
+		}
+	}
+	return synced
+}
+
+func (c *Collection) FindFolder(folderID string) (*Folder, bool) {
+	folder := findFolder(c.Items, folderID)
+	return folder, folder != nil
+}
+
+func findFolder(items []CollectionItem, folderID string) *Folder {
+	for _, item := range items {
+		if item.Folder == nil {
+			continue
+		}
+		if item.Folder.ID == folderID {
+			return item.Folder
+		}
+		if folder := findFolder(item.Folder.Items, folderID); folder != nil {
+			return folder
+		}
+	}
+	return nil
+}
+
+// children returns the items of a folder, or those of the collection root when parentID is empty.
+func (c *Collection) children(parentID string) (*[]CollectionItem, error) {
+	if parentID == "" {
+		return &c.Items, nil
+	}
+	folder, ok := c.FindFolder(parentID)
+	if !ok {
+		return nil, apperrors.NewAppError(http.StatusNotFound, "Folder not found in collection", nil)
+	}
+	return &folder.Items, nil
+}
+
+// AddFolder adds an empty folder at the end of its parent, the collection root when parentID is empty.
+func (c *Collection) AddFolder(folder *Folder, parentID string) error {
+	if folder.Name == "" {
+		return apperrors.NewAppError(http.StatusBadRequest, "Folder name cannot be empty", nil)
+	}
+	items, err := c.children(parentID)
+	if err != nil {
+		return err
+	}
+
+	if folder.ID == "" {
+		folder.ID = uuid.New().String()
+	}
+	folder.Items = []CollectionItem{}
+	*items = append(*items, CollectionItem{Folder: folder})
+	return nil
+}
+
+// RemoveFolder removes a folder along with everything it holds.
+func (c *Collection) RemoveFolder(folderID string) bool {
+	if _, ok := c.FindFolder(folderID); !ok {
+		return false
+	}
+	item, _ := detachItem(&c.Items, folderID)
+
+	removed := make(map[string]bool)
+	folderRequests(item.Folder.Items, removed)
+	requests := c.Requests[:0]
+	for _, req := range c.Requests {
+		if !removed[req.ID] {
+			requests = append(requests, req)
+		}
+	}
+	c.Requests = requests
+	return true
+}
+
+func folderRequests(items []CollectionItem, ids map[string]bool) {
+	for _, item := range items {
+		if item.Folder != nil {
+			folderRequests(item.Folder.Items, ids)
+		} else {
+			ids[item.RequestID] = true
+		}
+	}
+}
+
+// MoveItem moves a folder or a request into a folder, or to the root when parentID is empty.
+// The position is the index of the item among its new siblings, it goes last when the position
+// is negative or out of range.
+func (c *Collection) MoveItem(itemID string, parentID string, position int) error {
+	if parentID != "" {
+		target, ok := c.FindFolder(parentID)
+		if !ok {
+			return apperrors.NewAppError(http.StatusNotFound, "Folder not found in collection", nil)
+		}
+		if folder, ok := c.FindFolder(itemID); ok && (folder == target || findFolder(folder.Items, parentID) != nil) {
+			return apperrors.NewAppError(http.StatusBadRequest, "A folder cannot be moved inside itself", nil)
+		}
+	}
+
+	item, ok := detachItem(&c.Items, itemID)
+	if !ok {
+		return apperrors.NewAppError(http.StatusNotFound, "Item not found in collection", nil)
+	}
+	items, err := c.children(parentID)
+	if err != nil {
+		return err
+	}
+
+	if position < 0 || position > len(*items) {
+		position = len(*items)
+	}
+	*items = append(*items, CollectionItem{})
+	copy((*items)[position+1:], (*items)[position:])
+	(*items)[position] = item
+	return nil
+}
+
+// ReorderItems sorts the items of a folder, or of the root when parentID is empty, as listed by
+// their IDs. The order must list every item of the folder once.
+func (c *Collection) ReorderItems(parentID string, order []string) error {
+	items, err := c.children(parentID)
+	if err != nil {
+		return err
+	}
+	if len(order) != len(*items) {
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("The order must list the %d items of the folder", len(*items)), nil)
+	}
+
+	byID := make(map[string]CollectionItem, len(*items))
+	for _, item := range *items {
+		byID[item.ID()] = item
+	}
+	reordered := make([]CollectionItem, 0, len(order))
+	for _, id := range order {
+		item, ok := byID[id]
+		if !ok {
+			return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Item %s is not in the folder or is listed twice", id), nil)
+		}
+		delete(byID, id)
+		reordered = append(reordered, item)
+	}
+	*items = reordered
+	return nil
+}
+
+// detachItem removes an item from the tree and returns it.
+func detachItem(items *[]CollectionItem, itemID string) (CollectionItem, bool) {
+	for i, item := range *items {
+		if item.ID() == itemID {
+			*items = append((*items)[:i], (*items)[i+1:]...)
+			return item, true
+		}
+		if item.Folder != nil {
+			if detached, ok := detachItem(&item.Folder.Items, itemID); ok {
+				return detached, true
+			}
+		}
+	}
+	return CollectionItem{}, false
+}
+
+func validateFolders(items []CollectionItem) error {
+	for _, item := range items {
+		if item.Folder == nil {
+			continue
+		}
+		if item.Folder.Name == "" {
+			return fmt.Errorf("folder name cannot be empty")
+		}
+		if err := validateFolders(item.Folder.Items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+type GraphQLBody struct {
+	Query string `json:"query"`
+	// Variables holds the variables as JSON text so they can contain environment placeholders
+	Variables     string `json:"variables,omitempty"`
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// GraphQLPayload is the envelope sent to GraphQL servers.
+type GraphQLPayload struct {
+	Query         string          `json:"query"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+	OperationName string          `json:"operationName,omitempty"`
+}
+
+func (b *GraphQLBody) Validate() error {
+	if strings.TrimSpace(b.Query) == "" {
+		return apperrors.NewAppError(http.StatusBadRequest, "GraphQL query cannot be empty", nil)
+	}
+	return nil
+}
+
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType { kind name }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// GraphQLSchema is the cached introspection result of a GraphQL endpoint.
+type GraphQLSchema struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	FetchedAt time.Time `json:"fetchedAt"`
+	// Introspection is the "data" member of the introspection response, as expected by GraphQL tooling
+	Introspection json.RawMessage `json:"introspection"`
+}
+
+type GraphQLQueryError struct {
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+type introspectionName struct {
+	Name string `json:"name"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+type introspectionInputValue struct {
+	Name         string               `json:"name"`
+	Type         introspectionTypeRef `json:"type"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionType struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Fields []struct {
+		Name string                    `json:"name"`
+		Args []introspectionInputValue `json:"args"`
+		Type introspectionTypeRef      `json:"type"`
+	} `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	EnumValues    []introspectionName       `json:"enumValues"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+}
+
+type introspectionResult struct {
+	Schema struct {
+		QueryType        *introspectionName  `json:"queryType"`
+		MutationType     *introspectionName  `json:"mutationType"`
+		SubscriptionType *introspectionName  `json:"subscriptionType"`
+		Types            []introspectionType `json:"types"`
+		Directives       []struct {
+			Name      string                    `json:"name"`
+			Locations []string                  `json:"locations"`
+			Args      []introspectionInputValue `json:"args"`
+		} `json:"directives"`
+	} `json:"__schema"`
+}
+
+// GraphQLSchemaID derives the cache key of a schema from the endpoint URL.
+func GraphQLSchemaID(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:16])
+}
+
+func NewGraphQLSchema(url string, introspection json.RawMessage) *GraphQLSchema {
+	return &GraphQLSchema{
+		ID:            GraphQLSchemaID(url),
+		URL:           url,
+		FetchedAt:     time.Now(),
+		Introspection: introspection,
+	}
+}
+
+func (s *GraphQLSchema) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, s.ID+".json"), data, 0644)
+}
+
+func LoadGraphQLSchema(path string, url string) (*GraphQLSchema, error) {
+	data, err := os.ReadFile(filepath.Join(path, GraphQLSchemaID(url)+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var schema GraphQLSchema
+	err = json.Unmarshal(data, &schema)
+	return &schema, err
+}
+
+func DeleteGraphQLSchema(path string, url string) error {
+	return os.Remove(filepath.Join(path, GraphQLSchemaID(url)+".json"))
+}
+
+// ValidateQuery checks a query against the schema, reporting every problem found.
+func (s *GraphQLSchema) ValidateQuery(query string) (*GraphQLValidationResult, error) {
+	schema, err := s.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	_, gqlErrs := gqlparser.LoadQuery(schema, query)
+
+	queryErrors := make([]GraphQLQueryError, 0, len(gqlErrs))
+	for _, gqlErr := range gqlErrs {
+		queryError := GraphQLQueryError{Message: gqlErr.Message}
+		if len(gqlErr.Locations) > 0 {
+			queryError.Line = gqlErr.Locations[0].Line
+			queryError.Column = gqlErr.Locations[0].Column
+		}
+		queryErrors = append(queryErrors, queryError)
+	}
+	return &GraphQLValidationResult{Valid: len(queryErrors) == 0, Errors: queryErrors}, nil
+}
+
+func (s *GraphQLSchema) parse() (*ast.Schema, error) {
+	var result introspectionResult
+	if err := json.Unmarshal(s.Introspection, &result); err != nil {
+		return nil, fmt.Errorf("invalid introspection result: %w", err)
+	}
+	if result.Schema.QueryType == nil {
+		return nil, fmt.Errorf("introspection result has no query type")
+	}
+
+	sdl, err := introspectionToSDL(result)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: s.URL, Input: sdl})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+	return schema, nil
+}
+
+// introspectionToSDL rebuilds the schema definition language from an introspection result,
+// leaving out the built-in types and directives the parser already knows.
+func introspectionToSDL(result introspectionResult) (string, error) {
+	prelude, err := parser.ParseSchema(validator.Prelude)
+	if err != nil {
+		return "", err
+	}
+
+	var sdl strings.Builder
+
+	sdl.WriteString("schema {\n")
+	fmt.Fprintf(&sdl, "  query: %s\n", result.Schema.QueryType.Name)
+	if result.Schema.MutationType != nil {
+		fmt.Fprintf(&sdl, "  mutation: %s\n", result.Schema.MutationType.Name)
+	}
+	if result.Schema.SubscriptionType != nil {
+		fmt.Fprintf(&sdl, "  subscription: %s\n", result.Schema.SubscriptionType.Name)
+	}
+	sdl.WriteString("}\n\n")
+
+	for _, directive := range result.Schema.Directives {
+		if prelude.Directives.ForName(directive.Name) != nil {
+			continue
+		}
+		fmt.Fprintf(&sdl, "directive @%s%s on %s\n\n", directive.Name, inputValuesSDL(directive.Args), strings.Join(directive.Locations, " | "))
+	}
+
+	for _, t := range result.Schema.Types {
+		if strings.HasPrefix(t.Name, "__") || prelude.Definitions.ForName(t.Name) != nil {
+			continue
+		}
+
+		switch t.Kind {
+		case "SCALAR":
+			fmt.Fprintf(&sdl, "scalar %s\n\n", t.Name)
+
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			fmt.Fprintf(&sdl, "%s %s", keyword, t.Name)
+			if len(t.Interfaces) > 0 {
+				names := make([]string, 0, len(t.Interfaces))
+				for _, i := range t.Interfaces {
+					names = append(names, i.Name)
+				}
+				fmt.Fprintf(&sdl, " implements %s", strings.Join(names, " & "))
+			}
+			sdl.WriteString(" {\n")
+			for _, field := range t.Fields {
+				fmt.Fprintf(&sdl, "  %s%s: %s\n", field.Name, inputValuesSDL(field.Args), typeRefSDL(field.Type))
+			}
+			sdl.WriteString("}\n\n")
+
+		case "UNION":
+			names := make([]string, 0, len(t.PossibleTypes))
+			for _, possible := range t.PossibleTypes {
+				names = append(names, possible.Name)
+			}
+			fmt.Fprintf(&sdl, "union %s = %s\n\n", t.Name, strings.Join(names, " | "))
+
+		case "ENUM":
+			fmt.Fprintf(&sdl, "enum %s {\n", t.Name)
+			for _, value := range t.EnumValues {
+				fmt.Fprintf(&sdl, "  %s\n", value.Name)
+			}
+			sdl.WriteString("}\n\n")
+
+		case "INPUT_OBJECT":
+			fmt.Fprintf(&sdl, "input %s {\n", t.Name)
+			for _, field := range t.InputFields {
+				fmt.Fprintf(&sdl, "  %s\n", inputValueSDL(field))
+			}
+			sdl.WriteString("}\n\n")
+
+		default:
+			return "", fmt.Errorf("unknown type kind %s for %s", t.Kind, t.Name)
+		}
+	}
+
+	return sdl.String(), nil
+}
+
+func inputValuesSDL(values []introspectionInputValue) string {
+	if len(values) == 0 {
+		return ""
+	}
+	args := make([]string, 0, len(values))
+	for _, value := range values {
+		args = append(args, inputValueSDL(value))
+	}
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+func inputValueSDL(value introspectionInputValue) string {
+	sdl := value.Name + ": " + typeRefSDL(value.Type)
+	if value.DefaultValue != nil {
+		sdl += " = " + *value.DefaultValue
+	}
+	return sdl
+}
+
+func typeRefSDL(ref introspectionTypeRef) string {
+	switch ref.Kind {
+	case "NON_NULL":
+		if ref.OfType != nil {
+			return typeRefSDL(*ref.OfType) + "!"
+		}
+	case "LIST":
+		if ref.OfType != nil {
+			return "[" + typeRefSDL(*ref.OfType) + "]"
+		}
+	}
+	return ref.Name
+}
+
+type GraphQLValidationResult struct {
+	Valid  bool                `json:"valid"`
+	Errors []GraphQLQueryError `json:"errors"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+)
+
+type GRPCSource string
+
+const (
+	GRPCSourceReflection GRPCSource = "reflection"
+	GRPCSourceProto      GRPCSource = "proto"
+)
+
+// GRPCRequest describes a gRPC call. The request URL holds the target, either host:port or a
+// grpc:// (plaintext) or grpcs:// (TLS) URL.
+type GRPCRequest struct {
+	Source      GRPCSource `json:"source"`
+	ProtoFiles  []string   `json:"protoFiles,omitempty"`
+	ImportPaths []string   `json:"importPaths,omitempty"`
+	// Service is the fully-qualified service name, like "helloworld.Greeter"
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+	// Message is the request message in its JSON form
+	Message   string `json:"message,omitempty"`
+	Plaintext bool   `json:"plaintext,omitempty"`
+}
+
+type GRPCMethod struct {
+	Name            string          `json:"name"`
+	FullName        string          `json:"fullName"`
+	ClientStreaming bool            `json:"clientStreaming"`
+	ServerStreaming bool            `json:"serverStreaming"`
+	InputType       string          `json:"inputType"`
+	OutputType      string          `json:"outputType"`
+	RequestTemplate json.RawMessage `json:"requestTemplate,omitempty"`
+}
+
+type GRPCService struct {
+	Name    string       `json:"name"`
+	Methods []GRPCMethod `json:"methods"`
+}
+
+type GRPCStatus struct {
+	Code    int    `json:"code"`
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+}
+
+type GRPCResponse struct {
+	ExecutionID string            `json:"executionId"`
+	Status      GRPCStatus        `json:"status"`
+	Headers     []Header          `json:"headers"`
+	Trailers    []Header          `json:"trailers"`
+	Messages    []json.RawMessage `json:"messages"`
+	// Duration is the total time of the call, in milliseconds
+	Duration float64 `json:"duration"`
+}
+
+func (g *GRPCRequest) Validate() error {
+	switch g.Source {
+	case GRPCSourceReflection:
+	case GRPCSourceProto:
+		if len(g.ProtoFiles) == 0 {
+			return apperrors.NewAppError(http.StatusBadRequest, "gRPC requests using proto files require at least one file", nil)
+		}
+	default:
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported gRPC descriptor source: %s", g.Source), nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,487 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+)
+
+const HARVersion = "1.2"
+
+// HAR is an HTTP Archive, as exported by browser devtools.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Browser *HARCreator `json:"browser,omitempty"`
+	Pages   []HARPage   `json:"pages,omitempty"`
+	Entries []HAREntry  `json:"entries"`
+	Comment string      `json:"comment,omitempty"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HARPage struct {
+	ID              string `json:"id"`
+	StartedDateTime string `json:"startedDateTime"`
+	Title           string `json:"title"`
+}
+
+type HAREntry struct {
+	Pageref         string      `json:"pageref,omitempty"`
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Comment     string         `json:"comment,omitempty"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type HARPostData struct {
+	MimeType string     `json:"mimeType"`
+	Params   []HARParam `json:"params,omitempty"`
+	Text     string     `json:"text"`
+}
+
+type HARParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings are in milliseconds, -1 for the phases that didn't happen.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// HARFilter selects the entries imported from an archive. Domains match their subdomains too and
+// content types, matched against the response, may end with a wildcard like image/*.
+type HARFilter struct {
+	Domains      []string
+	ContentTypes []string
+}
+
+// harSkippedHeaders are set by the client when the request is sent.
+var harSkippedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Connection":        true,
+	"Accept-Encoding":   true,
+	"Transfer-Encoding": true,
+}
+
+func (f HARFilter) Matches(entry HAREntry) bool {
+	if len(f.Domains) > 0 {
+		parsed, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return false
+		}
+		host := strings.ToLower(parsed.Hostname())
+		matches := false
+		for _, domain := range f.Domains {
+			domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "."))
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+
+	if len(f.ContentTypes) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(entry.Response.Content.MimeType)
+		matches := false
+		for _, contentType := range f.ContentTypes {
+			contentType = strings.ToLower(strings.TrimSpace(contentType))
+			if prefix, ok := strings.CutSuffix(contentType, "*"); ok {
+				matches = strings.HasPrefix(mediaType, prefix)
+			} else {
+				matches = mediaType == contentType
+			}
+			if matches {
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ImportHAR creates a collection with a request per entry of the archive selected by the filter.
+// Requests are grouped in a folder per host, in the order they were sent.
+func ImportHAR(data []byte, filter HARFilter) (*ImportResult, error) {
+	var har HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid HAR file", err)
+	}
+	if har.Log.Version == "" && har.Log.Entries == nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Not a HAR file", nil)
+	}
+
+	result := &ImportResult{
+		Collection: Collection{ID: uuid.New().String(), Name: "HAR import", Requests: []Request{}},
+		Report:     NewImportReport(),
+	}
+	if len(har.Log.Pages) > 0 && har.Log.Pages[0].Title != "" {
+		result.Name = har.Log.Pages[0].Title
+	}
+	if har.Log.Creator.Name != "" {
+		result.Description = strings.TrimSpace(fmt.Sprintf("Captured with %s %s", har.Log.Creator.Name, har.Log.Creator.Version))
+	}
+
+	folders := make(map[string]*Folder)
+	result.Items = []CollectionItem{}
+	for _, entry := range har.Log.Entries {
+		if !filter.Matches(entry) {
+			continue
+		}
+
+		name := fmt.Sprintf("%s %s", entry.Request.Method, entry.Request.URL)
+		req, err := convertHAREntry(entry, name, &result.Report)
+		if err != nil {
+			result.Report.Skip(name, err.Error())
+			continue
+		}
+		result.Requests = append(result.Requests, req)
+		result.Report.Imported++
+
+		host := ""
+		if parsed, err := url.Parse(req.URL); err == nil {
+			host = parsed.Host
+		}
+		folder, ok := folders[host]
+		if !ok {
+			folder = &Folder{ID: uuid.New().String(), Name: host, Items: []CollectionItem{}}
+			folders[host] = folder
+			result.Items = append(result.Items, CollectionItem{Folder: folder})
+		}
+		folder.Items = append(folder.Items, CollectionItem{RequestID: req.ID})
+	}
+
+	return result, nil
+}
+
+func convertHAREntry(entry HAREntry, itemName string, report *ImportReport) (Request, error) {
+	source := entry.Request
+	parsed, err := url.Parse(source.URL)
+	if err != nil {
+		return Request{}, fmt.Errorf("Invalid URL: %s", source.URL)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return Request{}, fmt.Errorf("Only HTTP requests can be imported")
+	}
+
+	path := parsed.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	req := Request{
+		ID:     uuid.New().String(),
+		Name:   fmt.Sprintf("%s %s", source.Method, path),
+		Method: Method(strings.ToUpper(source.Method)),
+	}
+
+	rawQuery := parsed.RawQuery
+	parsed.RawQuery, parsed.Fragment, parsed.RawFragment = "", "", ""
+	req.URL = parsed.String()
+
+	for _, param := range parsePostmanQuery(rawQuery) {
+		key := unescapeFormValue(param.Key)
+		if req.QueryParams == nil {
+			req.QueryParams = make(map[string]string)
+		}
+		if _, ok := req.QueryParams[key]; ok {
+			report.Warn(itemName, fmt.Sprintf("Query parameter %s is repeated, only its first value was imported", key))
+			continue
+		}
+		req.QueryParams[key] = unescapeFormValue(string(param.Value))
+	}
+
+	contentType := ""
+	for _, header := range source.Headers {
+		// HTTP/2 pseudo headers, like :authority, are part of the URL
+		key := http.CanonicalHeaderKey(header.Name)
+		if strings.HasPrefix(header.Name, ":") || harSkippedHeaders[key] {
+			continue
+		}
+		switch {
+		case key == "Content-Type":
+			contentType = header.Value
+		case key == "Authorization" && req.Auth == nil && authFromHeader(header.Value) != nil:
+			req.Auth = authFromHeader(header.Value)
+		default:
+			req.Headers = append(req.Headers, Header{Key: header.Name, Value: header.Value})
+		}
+	}
+
+	if postData := source.PostData; postData != nil {
+		contentType = defaultString(contentType, postData.MimeType)
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+
+		switch {
+		case mediaType == "multipart/form-data":
+			// The text of multipart bodies is usually missing or holds the boundaries
+			body := &RequestBody{Mode: BodyFormData}
+			for _, param := range postData.Params {
+				if param.FileName != "" {
+					report.Warn(itemName, fmt.Sprintf("File field %s needs a file, it was not imported", param.Name))
+					continue
+				}
+				body.FormData = append(body.FormData, FormField{Key: param.Name, Value: param.Value, ContentType: param.ContentType})
+			}
+			req.BodyConfig = body
+		case postData.Text == "" && len(postData.Params) > 0:
+			body := &RequestBody{Mode: BodyURLEncoded}
+			for _, param := range postData.Params {
+				body.URLEncoded = append(body.URLEncoded, FormField{Key: unescapeFormValue(param.Name), Value: unescapeFormValue(param.Value)})
+			}
+			req.BodyConfig = body
+		case postData.Text != "":
+			req.BodyConfig = importedBody(contentType, postData.Text)
+		}
+		contentType = ""
+	}
+
+	if contentType != "" {
+		req.Headers = append(req.Headers, Header{Key: "Content-Type", Value: contentType})
+	}
+
+	if err := req.Validate(); err != nil {
+		return Request{}, fmt.Errorf("Invalid request: %s", err.Error())
+	}
+	return req, nil
+}
+
+// NewHAR archives executed requests, with their responses and timings. Entries are sorted by
+// the caller, HAR viewers expect them in the order they were sent.
+func NewHAR(entries []HistoryEntry) HAR {
+	har := HAR{Log: HARLog{
+		Version: HARVersion,
+		Creator: HARCreator{Name: "Pumoide", Version: "0.1.0"},
+		Entries: make([]HAREntry, 0, len(entries)),
+	}}
+
+	for _, entry := range entries {
+		har.Log.Entries = append(har.Log.Entries, newHAREntry(entry))
+	}
+	return har
+}
+
+func newHAREntry(entry HistoryEntry) HAREntry {
+	resolved := entry.ResolvedRequest
+	if resolved.Method == "" {
+		resolved.Method = defaultString(string(entry.Request.Method), string(MethodGet))
+	}
+	if resolved.URL == "" {
+		resolved.URL = entry.Request.URL
+	}
+
+	harEntry := HAREntry{
+		StartedDateTime: entry.Timestamp,
+		Request: HARRequest{
+			Method:      resolved.Method,
+			URL:         resolved.URL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     []HARCookie{},
+			Headers:     newHARHeaders(resolved.Headers),
+			QueryString: []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    int64(len(resolved.Body)),
+		},
+		Response: HARResponse{
+			Cookies:     []HARCookie{},
+			Headers:     []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1},
+		Comment: entry.Error,
+	}
+
+	if parsed, err := url.Parse(resolved.URL); err == nil {
+		for _, param := range parsePostmanQuery(parsed.RawQuery) {
+			harEntry.Request.QueryString = append(harEntry.Request.QueryString, HARNameValue{
+				Name:  unescapeFormValue(param.Key),
+				Value: unescapeFormValue(string(param.Value)),
+			})
+		}
+	}
+
+	if resolved.Body != "" {
+		postData := &HARPostData{MimeType: headerValue(resolved.Headers, "Content-Type")}
+		if resolved.Encoding == EncodingBase64 {
+			harEntry.Request.Comment = "The binary body of the request is not included"
+		} else {
+			postData.Text = resolved.Body
+		}
+		harEntry.Request.PostData = postData
+	}
+
+	response := entry.Response
+	if response == nil {
+		return harEntry
+	}
+
+	if response.Protocol != "" {
+		harEntry.Request.HTTPVersion = response.Protocol
+	}
+	harEntry.Response = HARResponse{
+		Status:      response.StatusCode,
+		StatusText:  http.StatusText(response.StatusCode),
+		HTTPVersion: harEntry.Request.HTTPVersion,
+		Cookies:     make([]HARCookie, 0, len(response.Cookies)),
+		Headers:     newHARHeaders(response.Headers),
+		Content: HARContent{
+			Size:     response.Size,
+			MimeType: headerValue(response.Headers, "Content-Type"),
+		},
+		RedirectURL: headerValue(response.Headers, "Location"),
+		HeadersSize: -1,
+		BodySize:    response.Size,
+	}
+	for _, cookie := range response.Cookies {
+		harCookie := HARCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		}
+		if cookie.Expires != nil {
+			harCookie.Expires = cookie.Expires.UTC().Format(time.RFC3339)
+		}
+		harEntry.Response.Cookies = append(harEntry.Response.Cookies, harCookie)
+	}
+
+	switch {
+	case response.SavedTo != "":
+		harEntry.Response.Comment = fmt.Sprintf("The body was saved to %s", response.SavedTo)
+	case response.Truncated:
+		harEntry.Response.Comment = "The body is truncated"
+		harEntry.Response.Content.Text = response.Body
+	default:
+		harEntry.Response.Content.Text = response.Body
+	}
+	if response.Encoding == EncodingBase64 && harEntry.Response.Content.Text != "" {
+		harEntry.Response.Content.Encoding = "base64"
+	}
+
+	// The TLS handshake is part of the connection time in HAR
+	timings := response.Timings
+	harEntry.Time = timings.Total
+	harEntry.Timings = HARTimings{
+		Blocked: -1,
+		DNS:     harTiming(timings.DNSLookup),
+		Connect: harTiming(timings.TCPConnection + timings.TLSHandshake),
+		SSL:     harTiming(timings.TLSHandshake),
+		Wait:    timings.TimeToFirstByte,
+		Receive: timings.ContentTransfer,
+	}
+	if host, _, err := net.SplitHostPort(response.RemoteAddr); err == nil {
+		harEntry.ServerIPAddress = host
+	}
+
+	return harEntry
+}
+
+func newHARHeaders(headers []Header) []HARNameValue {
+	result := make([]HARNameValue, 0, len(headers))
+	for _, header := range headers {
+		result = append(result, HARNameValue{Name: header.Key, Value: header.Value})
+	}
+	return result
+}
+
+// harTiming marks the phases that didn't happen, like the connection of a reused one.
+func harTiming(value float64) float64 {
+	if value == 0 {
+		return -1
+	}
+	return value
+}
+
+func headerValue(headers []Header, key string) string {
+	for _, header := range headers {
+		if strings.EqualFold(header.Key, key) {
+			return header.Value
+		}
+	}
+	return ""
+}
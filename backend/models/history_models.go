@@ -0,0 +1,349 @@
+package models
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bodies larger than this, typically file uploads, are not kept in the history
+const maxResolvedBodySize = 1 << 20
+
+// DefaultHistoryRetention is the number of entries kept when no retention is configured
+const DefaultHistoryRetention = 1000
+
+// The index lists a summary of every entry, newest first, so lists and searches don't read the entries
+const historyIndexFile = "index.json"
+
+// historyMu serializes the updates of the history index
+var historyMu sync.Mutex
+
+type ResolvedRequest struct {
+	Method   string       `json:"method"`
+	URL      string       `json:"url"`
+	Headers  []Header     `json:"headers"`
+	Body     string       `json:"body,omitempty"`
+	Encoding BodyEncoding `json:"encoding,omitempty"`
+}
+
+type HistoryEntry struct {
+	ID              string             `json:"id"`
+	Timestamp       time.Time          `json:"timestamp"`
+	Request         Request            `json:"request"`
+	ResolvedRequest ResolvedRequest    `json:"resolvedRequest"`
+	EnvironmentID   string             `json:"environmentId,omitempty"`
+	Environment     *Environment       `json:"environment,omitempty"`
+	Response        *ExecutionResponse `json:"response,omitempty"`
+	Error           string             `json:"error,omitempty"`
+}
+
+// HistorySummary holds the fields of an entry used to filter and sort the history.
+type HistorySummary struct {
+	ID          string    `json:"id"`
+	Timestamp   time.Time `json:"timestamp"`
+	RequestID   string    `json:"requestId,omitempty"`
+	Name        string    `json:"name"`
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	ResolvedURL string    `json:"resolvedUrl,omitempty"`
+	StatusCode  int       `json:"statusCode,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type HistoryFilter struct {
+	Query  string
+	Method string
+	Status int
+	// RequestIDs keeps the entries of the listed requests when not nil
+	RequestIDs []string
+	Offset     int
+	Limit      int
+}
+
+func NewResolvedRequest(req *http.Request) ResolvedRequest {
+	resolved := ResolvedRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: NewResponseHeaders(req.Header),
+	}
+
+	// Streamed bodies have an unknown length, read one byte past the limit to tell whether they fit
+	if req.GetBody != nil && req.ContentLength <= maxResolvedBodySize {
+		if body, err := req.GetBody(); err == nil {
+			data, err := io.ReadAll(io.LimitReader(body, maxResolvedBodySize+1))
+			_ = body.Close()
+			if err == nil && len(data) <= maxResolvedBodySize {
+				resolved.Body, resolved.Encoding = EncodeBody(req.Header.Get("Content-Type"), data)
+			}
+		}
+	}
+
+	return resolved
+}
+
+func NewHistoryEntry(req Request, env *Environment) HistoryEntry {
+	entry := HistoryEntry{
+		ID:        uuid.New().String(),
+		Timestamp: time.Now(),
+		Request:   req,
+	}
+
+	if env != nil {
+		entry.EnvironmentID = env.ID
+		// The snapshot keeps the proxy without its credentials, which stay in the environment file
+		snapshot := *env
+		if env.Proxy != nil {
+			proxy := *env.Proxy
+			proxy.Username, proxy.Password = "", ""
+			snapshot.Proxy = &proxy
+		}
+		entry.Environment = &snapshot
+	}
+
+	return entry
+}
+
+func (e *HistoryEntry) Summary() HistorySummary {
+	summary := HistorySummary{
+		ID:          e.ID,
+		Timestamp:   e.Timestamp,
+		RequestID:   e.Request.ID,
+		Name:        e.Request.Name,
+		Method:      string(e.Request.Method),
+		URL:         e.Request.URL,
+		ResolvedURL: e.ResolvedRequest.URL,
+		Error:       e.Error,
+	}
+	if e.Response != nil {
+		summary.StatusCode = e.Response.StatusCode
+	}
+	return summary
+}
+
+// Save writes the entry and adds it to the history index. Entries hold resolved headers, such as
+// Authorization, so like environments with proxy credentials they are only readable by the owner.
+func (e *HistoryEntry) Save(path string) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	index, err := loadHistoryIndex(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(path, e.ID+".json"), data, 0600); err != nil {
+		return err
+	}
+
+	summary := e.Summary()
+	position := sort.Search(len(index), func(i int) bool {
+		return !index[i].Timestamp.After(summary.Timestamp)
+	})
+	index = append(index, HistorySummary{})
+	copy(index[position+1:], index[position:])
+	index[position] = summary
+	return saveHistoryIndex(path, index)
+}
+
+func LoadHistoryEntry(path string, id string) (*HistoryEntry, error) {
+	data, err := os.ReadFile(filepath.Join(path, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var entry HistoryEntry
+	err = json.Unmarshal(data, &entry)
+	return &entry, err
+}
+
+// ListHistory returns the page of entries matching the filter, newest first, along with the number
+// of matching entries. Only the entries of the page are read, the filter runs on the index.
+func ListHistory(path string, filter HistoryFilter) ([]HistoryEntry, int, error) {
+	historyMu.Lock()
+	index, err := loadHistoryIndex(path)
+	historyMu.Unlock()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches := []HistorySummary{}
+	for _, summary := range index {
+		if filter.Matches(summary) {
+			matches = append(matches, summary)
+		}
+	}
+	total := len(matches)
+
+	if filter.Offset > 0 {
+		matches = matches[min(filter.Offset, len(matches)):]
+	}
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[:filter.Limit]
+	}
+
+	entries := make([]HistoryEntry, 0, len(matches))
+	for _, summary := range matches {
+		entry, err := LoadHistoryEntry(path, summary.ID)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, total, nil
+}
+
+// PruneHistory removes the oldest entries beyond the retention, DefaultHistoryRetention when it is
+// not positive.
+func PruneHistory(path string, retention int) error {
+	if retention <= 0 {
+		retention = DefaultHistoryRetention
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	index, err := loadHistoryIndex(path)
+	if err != nil || len(index) <= retention {
+		return err
+	}
+	for _, summary := range index[retention:] {
+		if err := os.Remove(filepath.Join(path, summary.ID+".json")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return saveHistoryIndex(path, index[:retention])
+}
+
+// DeleteHistoryEntry removes an entry, returning an os.ErrNotExist error when there is none.
+func DeleteHistoryEntry(path string, id string) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	index, err := loadHistoryIndex(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(path, id+".json")); err != nil {
+		return err
+	}
+
+	kept := index[:0]
+	for _, summary := range index {
+		if summary.ID != id {
+			kept = append(kept, summary)
+		}
+	}
+	return saveHistoryIndex(path, kept)
+}
+
+// ClearHistory removes every entry along with the index.
+func ClearHistory(path string) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadHistoryIndex reads the index, building it from the entries when missing, as for histories
+// recorded before it existed.
+func loadHistoryIndex(path string) ([]HistorySummary, error) {
+	data, err := os.ReadFile(filepath.Join(path, historyIndexFile))
+	if err == nil {
+		var index []HistorySummary
+		if err := json.Unmarshal(data, &index); err == nil {
+			return index, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	index := []HistorySummary{}
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), ".json")
+		if id+".json" == historyIndexFile {
+			continue
+		}
+		entry, err := LoadHistoryEntry(path, id)
+		if err != nil {
+			continue
+		}
+		index = append(index, entry.Summary())
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return index[i].Timestamp.After(index[j].Timestamp)
+	})
+	return index, nil
+}
+
+func saveHistoryIndex(path string, index []HistorySummary) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(path, historyIndexFile)
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of existing files
+	return os.Chmod(file, 0600)
+}
+
+func (f HistoryFilter) Matches(summary HistorySummary) bool {
+	if f.Method != "" && !strings.EqualFold(summary.Method, f.Method) {
+		return false
+	}
+
+	if f.Status != 0 && summary.StatusCode != f.Status {
+		return false
+	}
+
+	if f.RequestIDs != nil && !slices.Contains(f.RequestIDs, summary.RequestID) {
+		return false
+	}
+
+	if f.Query != "" {
+		query := strings.ToLower(f.Query)
+		fields := []string{summary.Name, summary.URL, summary.ResolvedURL, summary.Error}
+		if summary.StatusCode != 0 {
+			fields = append(fields, strconv.Itoa(summary.StatusCode))
+		}
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), query) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
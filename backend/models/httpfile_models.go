@@ -0,0 +1,478 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+)
+
+// httpFileBoundary separates the parts of exported multipart bodies.
+const httpFileBoundary = "PumoideFormBoundary"
+
+var (
+	httpFileVariable = regexp.MustCompile(`^@([\w.-]+)\s*=\s*(.*)$`)
+	httpFileVersion  = regexp.MustCompile(`^HTTP/\d(\.\d)?$`)
+)
+
+// httpFileBlock is a request of a .http file, from its request line to the next separator.
+type httpFileBlock struct {
+	name       string
+	noRedirect bool
+	lines      []string
+}
+
+// ImportHTTPFile converts a .http or .rest file, as written for the VS Code REST Client or the
+// JetBrains HTTP Client, into a collection. The file variables are imported as an environment,
+// their {{placeholders}} are the same.
+func ImportHTTPFile(data []byte, name string) (*ImportResult, error) {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if strings.TrimSpace(content) == "" {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "The file has no requests", nil)
+	}
+
+	result := &ImportResult{
+		Collection: Collection{ID: uuid.New().String(), Name: defaultString(name, "HTTP file import"), Requests: []Request{}},
+		Report:     NewImportReport(),
+	}
+	variables := make(map[string]string)
+
+	var blocks []*httpFileBlock
+	block := &httpFileBlock{}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "###") {
+			blocks = append(blocks, block)
+			block = &httpFileBlock{name: strings.TrimSpace(trimmed[3:])}
+			continue
+		}
+		if len(block.lines) > 0 {
+			block.lines = append(block.lines, line)
+			continue
+		}
+
+		// Before the request line there are only comments and variables
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, "//"):
+			comment := strings.TrimSpace(strings.TrimLeft(trimmed, "#/"))
+			if !strings.HasPrefix(comment, "@") {
+				continue
+			}
+			directive, value, _ := strings.Cut(comment[1:], " ")
+			switch directive {
+			case "name":
+				block.name = strings.TrimSpace(value)
+			case "no-redirect":
+				block.noRedirect = true
+			default:
+				result.Report.Warn(block.name, fmt.Sprintf("Directive @%s is not supported and was ignored", directive))
+			}
+		case httpFileVariable.MatchString(trimmed):
+			match := httpFileVariable.FindStringSubmatch(trimmed)
+			variables[match[1]] = strings.TrimSpace(match[2])
+		default:
+			block.lines = append(block.lines, line)
+		}
+	}
+	blocks = append(blocks, block)
+
+	for _, block := range blocks {
+		if len(block.lines) == 0 {
+			continue
+		}
+		req, err := block.request(&result.Report)
+		if err != nil {
+			result.Report.Skip(defaultString(block.name, strings.TrimSpace(block.lines[0])), err.Error())
+			continue
+		}
+		result.Requests = append(result.Requests, req)
+		result.Report.Imported++
+	}
+	if len(result.Requests) == 0 && result.Report.Skipped == 0 {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "The file has no requests", nil)
+	}
+
+	if len(variables) > 0 {
+		result.Environments = []*Environment{{Name: result.Name, Variables: variables}}
+	}
+	return result, nil
+}
+
+func (b *httpFileBlock) request(report *ImportReport) (Request, error) {
+	lines := b.lines
+	req := Request{ID: uuid.New().String(), Method: MethodGet}
+	fields := strings.Fields(lines[0])
+	if last := len(fields) - 1; last > 0 && httpFileVersion.MatchString(fields[last]) {
+		switch fields[last] {
+		case "HTTP/1.0", "HTTP/1.1":
+			req.Settings = &RequestSettings{HTTPVersion: HTTPVersion1}
+		case "HTTP/2", "HTTP/2.0":
+			req.Settings = &RequestSettings{HTTPVersion: HTTPVersion2}
+		}
+		fields = fields[:last]
+	}
+
+	graphQL := false
+	if len(fields) > 1 {
+		method := strings.ToUpper(fields[0])
+		switch {
+		case Method(method).IsValid():
+			req.Method = Method(method)
+		case method == "GRAPHQL":
+			req.Method, graphQL = MethodPost, true
+		default:
+			return Request{}, fmt.Errorf("Method %s is not supported", fields[0])
+		}
+		fields = fields[1:]
+	}
+	rawURL := strings.Join(fields, " ")
+	if b.noRedirect {
+		if req.Settings == nil {
+			req.Settings = &RequestSettings{}
+		}
+		req.Settings.FollowRedirects = new(bool)
+	}
+
+	// The query may continue over the following lines
+	lines = lines[1:]
+	for len(lines) > 0 {
+		trimmed := strings.TrimSpace(lines[0])
+		if !strings.HasPrefix(trimmed, "?") && !strings.HasPrefix(trimmed, "&") {
+			break
+		}
+		rawURL += trimmed
+		lines = lines[1:]
+	}
+
+	item := defaultString(b.name, rawURL)
+	contentType := ""
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[0])
+		lines = lines[1:]
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			report.Warn(item, fmt.Sprintf("Header line %q is invalid and was ignored", line))
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch {
+		case strings.EqualFold(key, "Content-Type"):
+			contentType = value
+		case strings.EqualFold(key, "X-Request-Type") && strings.EqualFold(value, "GraphQL"):
+			graphQL = true
+		case strings.EqualFold(key, "Authorization") && httpFileAuth(value, item, report, &req):
+		default:
+			req.Headers = append(req.Headers, Header{Key: key, Value: value})
+		}
+	}
+
+	// A relative URL is completed with the Host header
+	if strings.HasPrefix(rawURL, "/") {
+		for i, header := range req.Headers {
+			if strings.EqualFold(header.Key, "Host") {
+				rawURL = "http://" + header.Value + rawURL
+				req.Headers = append(req.Headers[:i], req.Headers[i+1:]...)
+				break
+			}
+		}
+		if len(req.Headers) == 0 {
+			req.Headers = nil
+		}
+	}
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	if !strings.Contains(rawURL, "://") && !strings.HasPrefix(rawURL, "{{") {
+		rawURL = "http://" + rawURL
+	}
+	rawURL, rawQuery, _ := strings.Cut(rawURL, "?")
+	req.URL = rawURL
+	req.QueryParams = importedQuery(rawQuery, item, report)
+
+	if body := httpFileBody(lines, item, report); body != "" {
+		switch mediaType, params, _ := mime.ParseMediaType(contentType); {
+		case graphQL:
+			req.BodyConfig = &RequestBody{Mode: BodyGraphQL, GraphQL: httpFileGraphQL(body)}
+		case strings.HasPrefix(body, "<") && !strings.Contains(body, "\n"):
+			path := strings.TrimSpace(strings.TrimPrefix(body[1:], "@"))
+			req.BodyConfig = &RequestBody{Mode: BodyBinary, FilePath: path, ContentType: contentType}
+		case mediaType == "multipart/form-data" && params["boundary"] != "":
+			if fields, ok := httpFileMultipart(body, params["boundary"]); ok {
+				req.BodyConfig = &RequestBody{Mode: BodyFormData, FormData: fields}
+			} else {
+				report.Warn(item, "The multipart body couldn't be split into fields, it was imported as raw text")
+				req.BodyConfig = &RequestBody{Mode: BodyRaw, Raw: body, ContentType: contentType}
+			}
+		default:
+			req.BodyConfig = importedBody(contentType, body)
+		}
+	} else if contentType != "" {
+		req.Headers = append(req.Headers, Header{Key: "Content-Type", Value: contentType})
+	}
+
+	warned := make(map[string]bool)
+	for _, match := range variablePattern.FindAllStringSubmatch(strings.Join(b.lines, "\n"), -1) {
+		name := strings.TrimSpace(match[1])
+		if warned[name] {
+			continue
+		}
+		warned[name] = true
+		switch {
+		case strings.HasPrefix(name, "$"):
+			report.Warn(item, fmt.Sprintf("Dynamic variable {{%s}} is not supported, set its value in the environment", name))
+		case strings.Contains(name, ".response.") || strings.Contains(name, ".request."):
+			report.Warn(item, fmt.Sprintf("Request variable {{%s}} is not supported, set its value in the environment", name))
+		}
+	}
+
+	req.Name = b.name
+	if req.Name == "" {
+		req.Name = fmt.Sprintf("%s %s", req.Method, rawURL)
+	}
+	return req, nil
+}
+
+// httpFileAuth converts an Authorization header into the auth of the request, including the
+// unencoded credentials the REST Client accepts. It returns false to keep it as a header.
+func httpFileAuth(value string, item string, report *ImportReport, req *Request) bool {
+	scheme, credentials, _ := strings.Cut(value, " ")
+	credentials = strings.TrimSpace(credentials)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if username, password, ok := strings.Cut(credentials, " "); ok {
+			req.Auth = &Auth{Type: AuthBasic, Params: map[string]string{"username": username, "password": strings.TrimSpace(password)}}
+			return true
+		}
+		if username, password, ok := strings.Cut(credentials, ":"); ok {
+			req.Auth = &Auth{Type: AuthBasic, Params: map[string]string{"username": username, "password": password}}
+			return true
+		}
+	case "digest":
+		report.Warn(item, "Digest credentials were not imported, set them along with the server realm and nonce")
+		return true
+	case "aws":
+		words := strings.Fields(credentials)
+		if len(words) < 2 {
+			return false
+		}
+		params := map[string]string{"access_key": words[0], "secret_key": words[1]}
+		for _, word := range words[2:] {
+			if key, value, ok := strings.Cut(word, ":"); ok && (key == "region" || key == "service") {
+				params[key] = value
+			} else if ok && key == "token" {
+				params["session_token"] = value
+			}
+		}
+		req.Auth = &Auth{Type: AuthAWSSigV4, Params: params}
+		return true
+	}
+
+	if auth := authFromHeader(value); auth != nil {
+		req.Auth = auth
+		return true
+	}
+	return false
+}
+
+// httpFileBody returns the body of a request, without the response handlers that may follow it.
+func httpFileBody(lines []string, item string, report *ImportReport) string {
+	var body []string
+	inScript := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inScript:
+			inScript = !strings.Contains(trimmed, "%}")
+		case strings.HasPrefix(trimmed, "> {%"):
+			report.Warn(item, "Response handler scripts are not supported and were ignored")
+			inScript = !strings.Contains(trimmed[4:], "%}")
+		case strings.HasPrefix(trimmed, ">>"):
+			report.Warn(item, "Saving the response to a file is not supported and was ignored")
+		case strings.HasPrefix(trimmed, "> "), strings.HasPrefix(trimmed, "<> "):
+		default:
+			body = append(body, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(body, "\n"))
+}
+
+// httpFileGraphQL splits a GraphQL body into the query and the variables object that may follow
+// it after a blank line.
+func httpFileGraphQL(body string) *GraphQLBody {
+	if index := strings.LastIndex(body, "\n\n"); index >= 0 {
+		variables := strings.TrimSpace(body[index:])
+		if strings.HasPrefix(variables, "{") && json.Valid([]byte(variables)) {
+			return &GraphQLBody{Query: strings.TrimSpace(body[:index]), Variables: variables}
+		}
+	}
+	return &GraphQLBody{Query: body}
+}
+
+// httpFileMultipart splits a multipart body into form fields. Parts whose content is a < path
+// reference are file fields.
+func httpFileMultipart(body string, boundary string) ([]FormField, bool) {
+	var fields []FormField
+	parts := strings.Split("\n"+body, "\n--"+boundary)
+	if len(parts) < 3 || strings.TrimSpace(parts[0]) != "" || !strings.HasPrefix(parts[len(parts)-1], "--") {
+		return nil, false
+	}
+
+	for _, part := range parts[1 : len(parts)-1] {
+		headers, content, _ := strings.Cut(strings.TrimPrefix(part, "\n"), "\n\n")
+		field := FormField{}
+		for _, line := range strings.Split(headers, "\n") {
+			key, value, _ := strings.Cut(line, ":")
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "content-disposition":
+				_, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+				if err != nil {
+					return nil, false
+				}
+				field.Key = params["name"]
+			case "content-type":
+				field.ContentType = strings.TrimSpace(value)
+			}
+		}
+
+		if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, "<") && !strings.Contains(trimmed, "\n") {
+			field.Type = FormFieldFile
+			field.FilePath = strings.TrimSpace(strings.TrimPrefix(trimmed[1:], "@"))
+		} else {
+			field.Value = content
+		}
+		fields = append(fields, field)
+	}
+	return fields, true
+}
+
+// ToHTTPFile exports the HTTP requests of the collection as a .http file, with the variables of
+// the environment declared at the top and the {{placeholders}} kept.
+func (c *Collection) ToHTTPFile(env *Environment) string {
+	var file strings.Builder
+	if env != nil && len(env.Variables) > 0 {
+		keys := make([]string, 0, len(env.Variables))
+		for key := range env.Variables {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&file, "@%s = %s\n", key, env.Variables[key])
+		}
+		file.WriteString("\n")
+	}
+
+	first := true
+	for _, req := range c.Requests {
+		if !req.Type.IsHTTP() {
+			continue
+		}
+		if !first {
+			file.WriteString("\n")
+		}
+		first = false
+		file.WriteString(req.toHTTPFileEntry())
+	}
+	return file.String()
+}
+
+func (r *Request) toHTTPFileEntry() string {
+	resolved := r.resolve(nil)
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "### %s\n", r.Name)
+	if !resolved.Settings.ShouldFollowRedirects() {
+		entry.WriteString("# @no-redirect\n")
+	}
+
+	fmt.Fprintf(&entry, "%s %s", resolved.Method, resolved.URL)
+	switch resolved.Settings.HTTPVersion {
+	case HTTPVersion1:
+		entry.WriteString(" HTTP/1.1")
+	case HTTPVersion2:
+		entry.WriteString(" HTTP/2")
+	}
+	entry.WriteString("\n")
+
+	if auth := resolved.Auth; auth != nil {
+		params := auth.Params
+		switch auth.Type {
+		case AuthBasic:
+			credentials := params["username"] + ":" + params["password"]
+			if variablePattern.MatchString(credentials) {
+				// The REST Client and the JetBrains client encode the credentials themselves
+				fmt.Fprintf(&entry, "Authorization: Basic %s %s\n", params["username"], params["password"])
+			} else {
+				fmt.Fprintf(&entry, "Authorization: Basic %s\n", base64.StdEncoding.EncodeToString([]byte(credentials)))
+			}
+		case AuthDigest:
+			fmt.Fprintf(&entry, "Authorization: Digest %s %s\n", params["username"], params["password"])
+		case AuthAWSSigV4:
+			line := fmt.Sprintf("Authorization: AWS %s %s", params["access_key"], params["secret_key"])
+			if params["session_token"] != "" {
+				line += " token:" + params["session_token"]
+			}
+			for _, key := range []string{"region", "service"} {
+				if params[key] != "" {
+					line += " " + key + ":" + params[key]
+				}
+			}
+			entry.WriteString(line + "\n")
+		}
+	}
+
+	for _, header := range resolved.Headers {
+		if resolved.Auth != nil && resolved.Auth.Type == AuthAWSSigV4 && strings.EqualFold(header.Key, "X-Amz-Security-Token") {
+			continue
+		}
+		fmt.Fprintf(&entry, "%s: %s\n", header.Key, header.Value)
+	}
+
+	switch resolved.Body.Mode {
+	case BodyRaw:
+		fmt.Fprintf(&entry, "\n%s\n", resolved.Body.Text)
+	case BodyURLEncoded:
+		var params []string
+		for _, field := range resolved.Body.Fields {
+			params = append(params, queryEscape(field.Key)+"="+queryEscape(field.Value))
+		}
+		entry.WriteString("Content-Type: application/x-www-form-urlencoded\n\n")
+		entry.WriteString(strings.Join(params, "&") + "\n")
+	case BodyFormData:
+		fmt.Fprintf(&entry, "Content-Type: multipart/form-data; boundary=%s\n\n", httpFileBoundary)
+		for _, field := range resolved.Body.Fields {
+			fmt.Fprintf(&entry, "--%s\n", httpFileBoundary)
+			if field.Type == FormFieldFile {
+				fmt.Fprintf(&entry, "Content-Disposition: form-data; name=%q; filename=%q\n", field.Key, filepath.Base(field.FilePath))
+			} else {
+				fmt.Fprintf(&entry, "Content-Disposition: form-data; name=%q\n", field.Key)
+			}
+			if field.ContentType != "" {
+				fmt.Fprintf(&entry, "Content-Type: %s\n", field.ContentType)
+			}
+			if field.Type == FormFieldFile {
+				fmt.Fprintf(&entry, "\n< %s\n", field.FilePath)
+			} else {
+				fmt.Fprintf(&entry, "\n%s\n", field.Value)
+			}
+		}
+		fmt.Fprintf(&entry, "--%s--\n", httpFileBoundary)
+	case BodyBinary:
+		fmt.Fprintf(&entry, "\n< %s\n", resolved.Body.FilePath)
+	}
+	return entry.String()
+}
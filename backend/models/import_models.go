@@ -0,0 +1,238 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+)
+
+// ImportWarning describes something an importer couldn't convert faithfully. Item is the path of
+// the affected item in the source document, empty for warnings about the whole document.
+type ImportWarning struct {
+	Item    string `json:"item,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	Imported int             `json:"imported"`
+	Skipped  int             `json:"skipped"`
+	Warnings []ImportWarning `json:"warnings"`
+}
+
+// ImportResult is the collection created by an import, along with the environments holding its
+// variables, if any, and the report of what was lost on the way.
+type ImportResult struct {
+	Collection
+	Environments []*Environment `json:"environments,omitempty"`
+	Report       ImportReport   `json:"report"`
+}
+
+// RequestImportResult is a single imported request, not saved to any collection yet.
+type RequestImportResult struct {
+	Request Request      `json:"request"`
+	Report  ImportReport `json:"report"`
+}
+
+type ImportFormat string
+
+const (
+	ImportFormatPostman  ImportFormat = "postman"
+	ImportFormatInsomnia ImportFormat = "insomnia"
+	ImportFormatBruno    ImportFormat = "bruno"
+	ImportFormatOpenAPI  ImportFormat = "openapi"
+	ImportFormatHAR      ImportFormat = "har"
+	ImportFormatHTTPFile ImportFormat = "http"
+)
+
+func GetValidImportFormats() []ImportFormat {
+	return []ImportFormat{ImportFormatPostman, ImportFormatInsomnia, ImportFormatBruno, ImportFormatOpenAPI, ImportFormatHAR, ImportFormatHTTPFile}
+}
+
+func (f ImportFormat) IsValid() bool {
+	for _, validFormat := range GetValidImportFormats() {
+		if f == validFormat {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectImportFormat guesses the format of a file from its content. Files matching no format are
+// taken for Postman collections, whose importer tells what is wrong with them.
+func DetectImportFormat(data []byte) ImportFormat {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return ImportFormatBruno
+	}
+
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(data, &document); err == nil {
+		_, hasExportFormat := document["__export_format"]
+		_, hasOpenAPI := document["openapi"]
+		_, hasSwagger := document["swagger"]
+		_, hasLog := document["log"]
+		switch {
+		case hasExportFormat || string(document["_type"]) == `"export"`:
+			return ImportFormatInsomnia
+		case hasOpenAPI || hasSwagger:
+			return ImportFormatOpenAPI
+		case hasLog:
+			return ImportFormatHAR
+		}
+		return ImportFormatPostman
+	}
+
+	// Text formats are told apart by their first line that isn't a comment
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "###") {
+			continue
+		}
+
+		switch {
+		case line == "meta {":
+			return ImportFormatBruno
+		case strings.HasPrefix(line, "openapi:"), strings.HasPrefix(line, "swagger:"):
+			return ImportFormatOpenAPI
+		case strings.HasPrefix(line, "###"), httpFileVariable.MatchString(line):
+			return ImportFormatHTTPFile
+		}
+		if method, _, ok := strings.Cut(line, " "); ok && Method(method).IsValid() {
+			return ImportFormatHTTPFile
+		}
+		break
+	}
+	return ImportFormatPostman
+}
+
+// ImportCollection imports a file in any of the supported formats, detecting it when the format
+// is empty.
+func ImportCollection(data []byte, format ImportFormat) (*ImportResult, error) {
+	if format == "" {
+		format = DetectImportFormat(data)
+	}
+
+	switch format {
+	case ImportFormatPostman:
+		return ImportPostmanCollection(data)
+	case ImportFormatInsomnia:
+		return ImportInsomniaExport(data)
+	case ImportFormatBruno:
+		return ImportBrunoCollection(data)
+	case ImportFormatOpenAPI:
+		return ImportOpenAPIDocument(data)
+	case ImportFormatHAR:
+		return ImportHAR(data, HARFilter{})
+	case ImportFormatHTTPFile:
+		return ImportHTTPFile(data, "")
+	}
+	return nil, apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported import format: %s", format), nil)
+}
+
+func NewImportReport() ImportReport {
+	return ImportReport{Warnings: []ImportWarning{}}
+}
+
+func (r *ImportReport) Warn(item string, message string) {
+	r.Warnings = append(r.Warnings, ImportWarning{Item: item, Message: message})
+}
+
+func (r *ImportReport) Skip(item string, message string) {
+	r.Skipped++
+	r.Warn(item, message)
+}
+
+// importedQuery splits a raw query string into the query parameters of a request. Requests hold
+// a single value per parameter, repeated ones are reported.
+func importedQuery(rawQuery string, item string, report *ImportReport) map[string]string {
+	params := make(map[string]string)
+	for _, param := range parsePostmanQuery(rawQuery) {
+		key := unescapeFormValue(param.Key)
+		if _, ok := params[key]; ok {
+			report.Warn(item, fmt.Sprintf("Query parameter %s is repeated, only its first value was imported", key))
+			continue
+		}
+		params[key] = unescapeFormValue(string(param.Value))
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// importedBody picks the mode of an imported body from its Content-Type. Data without one is sent
+// as a form, as curl and browsers do.
+func importedBody(contentType string, data string) *RequestBody {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case isJSONMediaType(mediaType) && json.Valid([]byte(data)):
+		body := &RequestBody{Mode: BodyJSON, Raw: data}
+		if contentType != "application/json" {
+			body.ContentType = contentType
+		}
+		return body
+	case contentType == "" || mediaType == "application/x-www-form-urlencoded":
+		if fields, ok := parseURLEncoded(data); ok {
+			return &RequestBody{Mode: BodyURLEncoded, URLEncoded: fields}
+		}
+		return &RequestBody{Mode: BodyRaw, Raw: data, ContentType: defaultString(contentType, "application/x-www-form-urlencoded")}
+	default:
+		return &RequestBody{Mode: BodyRaw, Raw: data, ContentType: contentType}
+	}
+}
+
+// unescapeFormValue decodes query parameters and form fields with the form encoding rules,
+// where a "+" stands for a space.
+func unescapeFormValue(value string) string {
+	unescaped, err := url.QueryUnescape(value)
+	if err != nil {
+		return value
+	}
+	return unescaped
+}
+
+// parseURLEncoded splits an encoded form into its fields, in order.
+func parseURLEncoded(data string) ([]FormField, bool) {
+	var fields []FormField
+	for _, pair := range strings.Split(data, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, false
+		}
+		key, err := url.QueryUnescape(key)
+		if err != nil || key == "" {
+			return nil, false
+		}
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			return nil, false
+		}
+		fields = append(fields, FormField{Key: key, Value: value})
+	}
+	return fields, len(fields) > 0
+}
+
+// authFromHeader converts the Bearer and Basic Authorization headers, nil for other schemes.
+func authFromHeader(value string) *Auth {
+	scheme, credentials, _ := strings.Cut(value, " ")
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		return &Auth{Type: AuthBearer, Params: map[string]string{"token": credentials}}
+	case "basic":
+		if decoded, err := base64.StdEncoding.DecodeString(credentials); err == nil {
+			username, password, _ := strings.Cut(string(decoded), ":")
+			return &Auth{Type: AuthBasic, Params: map[string]string{"username": username, "password": password}}
+		}
+	}
+	return nil
+}
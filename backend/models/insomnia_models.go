@@ -0,0 +1,460 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+)
+
+// InsomniaExport is an Insomnia v4 export, a flat list of resources linked to their parent.
+type InsomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	Resources    []InsomniaResource `json:"resources"`
+}
+
+// InsomniaResource is any resource of an export, only the fields of its type are set.
+type InsomniaResource struct {
+	ID          string  `json:"_id"`
+	Type        string  `json:"_type"`
+	ParentID    string  `json:"parentId"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	MetaSortKey float64 `json:"metaSortKey"`
+
+	Method                 string          `json:"method"`
+	URL                    string          `json:"url"`
+	Body                   InsomniaBody    `json:"body"`
+	Headers                []InsomniaParam `json:"headers"`
+	Parameters             []InsomniaParam `json:"parameters"`
+	Authentication         InsomniaAuth    `json:"authentication"`
+	SettingFollowRedirects string          `json:"settingFollowRedirects"`
+
+	// Data holds the variables of environments, and Environment those of request groups
+	Data        map[string]interface{} `json:"data"`
+	Environment map[string]interface{} `json:"environment"`
+}
+
+type InsomniaParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Disabled    bool   `json:"disabled"`
+	Type        string `json:"type"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+}
+
+type InsomniaBody struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text"`
+	Params   []InsomniaParam `json:"params"`
+	FileName string          `json:"fileName"`
+}
+
+type InsomniaAuth struct {
+	Type            string `json:"type"`
+	Disabled        bool   `json:"disabled"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	Token           string `json:"token"`
+	Prefix          string `json:"prefix"`
+	Key             string `json:"key"`
+	Value           string `json:"value"`
+	AddTo           string `json:"addTo"`
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken"`
+	Region          string `json:"region"`
+	Service         string `json:"service"`
+}
+
+var (
+	// insomniaVariable matches {{ _.name }}, the legacy {{ name }} and the bracket notation
+	insomniaVariable = regexp.MustCompile(`\{\{\s*(?:_\.)?(?:_\[['"])?([\w.-]+?)(?:['"]\])?\s*\}\}`)
+	insomniaTag      = regexp.MustCompile(`\{%\s*(\w+).*?%\}`)
+)
+
+// ImportInsomniaExport converts an Insomnia v4 export. The requests of every workspace are
+// imported, request groups becoming collection folders. Each environment is merged with the
+// base environment of its workspace.
+func ImportInsomniaExport(data []byte) (*ImportResult, error) {
+	var export InsomniaExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to parse Insomnia export", err)
+	}
+	if export.Type != "export" {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Not an Insomnia export", nil)
+	}
+	if export.ExportFormat != 4 {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Only Insomnia v4 exports are supported",
+			fmt.Errorf("export format %d", export.ExportFormat))
+	}
+
+	importer := insomniaImporter{report: NewImportReport(), children: make(map[string][]InsomniaResource)}
+	var workspaces []InsomniaResource
+	for _, resource := range export.Resources {
+		importer.children[resource.ParentID] = append(importer.children[resource.ParentID], resource)
+		if resource.Type == "workspace" {
+			workspaces = append(workspaces, resource)
+		}
+	}
+	for parent := range importer.children {
+		children := importer.children[parent]
+		sort.SliceStable(children, func(a, b int) bool { return children[a].MetaSortKey < children[b].MetaSortKey })
+	}
+	if len(workspaces) == 0 {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "The export has no workspace", nil)
+	}
+
+	result := &ImportResult{
+		Collection: Collection{
+			ID:          uuid.New().String(),
+			Name:        workspaces[0].Name,
+			Description: workspaces[0].Description,
+			Requests:    []Request{},
+		},
+	}
+	result.Items = []CollectionItem{}
+	for _, workspace := range workspaces {
+		if len(workspaces) == 1 {
+			result.Items = importer.importChildren(workspace.ID, nil)
+		} else {
+			result.Items = append(result.Items, CollectionItem{Folder: &Folder{
+				ID:          uuid.New().String(),
+				Name:        workspace.Name,
+				Description: workspace.Description,
+				Items:       importer.importChildren(workspace.ID, []string{workspace.Name}),
+			}})
+		}
+		result.Environments = append(result.Environments, importer.importEnvironments(workspace)...)
+	}
+	result.Requests = append(result.Requests, importer.requests...)
+	if len(workspaces) > 1 {
+		result.Name = "Insomnia import"
+		importer.report.Warn("", "The export has several workspaces, each one was imported as a folder")
+	}
+	result.Report = importer.report
+
+	if err := result.Collection.Validate(); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid imported collection", err)
+	}
+	return result, nil
+}
+
+type insomniaImporter struct {
+	report   ImportReport
+	children map[string][]InsomniaResource
+	// variables collects the variables of request groups, which become environment variables
+	variables map[string]string
+	requests  []Request
+}
+
+// importChildren imports the requests under a workspace or request group and returns its tree items.
+func (i *insomniaImporter) importChildren(parentID string, folder []string) []CollectionItem {
+	items := []CollectionItem{}
+
+	for _, resource := range i.children[parentID] {
+		name := defaultString(resource.Name, "Untitled")
+		path := append(folder[:len(folder):len(folder)], name)
+		itemPath := strings.Join(path, " / ")
+
+		switch resource.Type {
+		case "request_group":
+			if len(resource.Environment) > 0 {
+				i.report.Warn(itemPath, "Folder variables were added to every environment")
+				if i.variables == nil {
+					i.variables = make(map[string]string)
+				}
+				i.flattenVariables("", resource.Environment, i.variables)
+			}
+			items = append(items, CollectionItem{Folder: &Folder{
+				ID:          uuid.New().String(),
+				Name:        name,
+				Description: resource.Description,
+				Items:       i.importChildren(resource.ID, path),
+			}})
+
+		case "request":
+			request, err := i.convertRequest(itemPath, resource)
+			if err != nil {
+				i.report.Skip(itemPath, err.Error())
+				continue
+			}
+			request.Name = name
+			i.requests = append(i.requests, request)
+			items = append(items, CollectionItem{RequestID: request.ID})
+			i.report.Imported++
+
+		case "grpc_request", "websocket_request":
+			i.report.Skip(itemPath, fmt.Sprintf("Insomnia %s resources are not supported", strings.ReplaceAll(resource.Type, "_", " ")))
+		}
+	}
+
+	return items
+}
+
+func (i *insomniaImporter) convertRequest(name string, resource InsomniaResource) (Request, error) {
+	method := Method(strings.ToUpper(strings.TrimSpace(resource.Method)))
+	if method == "" {
+		method = MethodGet
+	}
+	if !method.IsValid() {
+		return Request{}, fmt.Errorf("Unsupported HTTP method %s", resource.Method)
+	}
+
+	rawURL, rawQuery, _ := strings.Cut(i.template(name, resource.URL), "?")
+	request := Request{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Method:      method,
+		URL:         rawURL,
+		QueryParams: importedQuery(rawQuery, name, &i.report),
+		BodyConfig:  i.convertBody(name, resource.Body),
+		Auth:        i.convertAuth(name, resource.Authentication),
+	}
+
+	for _, param := range resource.Parameters {
+		switch {
+		case param.Disabled:
+			i.report.Warn(name, fmt.Sprintf("Disabled query parameter %s was not imported", param.Name))
+		case param.Name != "":
+			if request.QueryParams == nil {
+				request.QueryParams = make(map[string]string)
+			}
+			key := i.template(name, param.Name)
+			if _, ok := request.QueryParams[key]; ok {
+				i.report.Warn(name, fmt.Sprintf("Query parameter %s is repeated, only its first value was imported", key))
+				continue
+			}
+			request.QueryParams[key] = i.template(name, param.Value)
+		}
+	}
+
+	for _, header := range resource.Headers {
+		switch {
+		case header.Disabled:
+			i.report.Warn(name, fmt.Sprintf("Disabled header %s was not imported", header.Name))
+		case header.Name == "":
+			i.report.Warn(name, "Header without a name was not imported")
+		case strings.EqualFold(header.Name, "Content-Type") && request.BodyConfig != nil:
+			// The body mode sets it
+			if request.BodyConfig.Mode == BodyRaw && request.BodyConfig.ContentType == "" {
+				request.BodyConfig.ContentType = header.Value
+			}
+		default:
+			request.Headers = append(request.Headers, Header{Key: header.Name, Value: i.template(name, header.Value)})
+		}
+	}
+
+	switch resource.SettingFollowRedirects {
+	case "on":
+		follow := true
+		request.Settings = &RequestSettings{FollowRedirects: &follow}
+	case "off":
+		request.Settings = &RequestSettings{FollowRedirects: new(bool)}
+	}
+
+	if err := request.Validate(); err != nil {
+		message := err.Error()
+		var appErr apperrors.AppError
+		if errors.As(err, &appErr) {
+			message = appErr.Message
+		}
+		return Request{}, fmt.Errorf("Invalid request: %s", message)
+	}
+	return request, nil
+}
+
+func (i *insomniaImporter) convertBody(name string, source InsomniaBody) *RequestBody {
+	var body *RequestBody
+	switch source.MimeType {
+	case "":
+		if source.Text == "" {
+			return nil
+		}
+		body = &RequestBody{Mode: BodyRaw, Raw: i.template(name, source.Text)}
+
+	case "application/json":
+		body = &RequestBody{Mode: BodyJSON, Raw: i.template(name, source.Text)}
+
+	case "application/graphql":
+		var payload struct {
+			Query         string          `json:"query"`
+			Variables     json.RawMessage `json:"variables"`
+			OperationName string          `json:"operationName"`
+		}
+		if err := json.Unmarshal([]byte(source.Text), &payload); err != nil {
+			i.report.Warn(name, "Invalid GraphQL body was not imported")
+			return nil
+		}
+		graphQL := &GraphQLBody{Query: i.template(name, payload.Query), OperationName: payload.OperationName}
+		if variables := strings.TrimSpace(string(payload.Variables)); variables != "" && variables != "null" {
+			graphQL.Variables = i.template(name, variables)
+		}
+		body = &RequestBody{Mode: BodyGraphQL, GraphQL: graphQL}
+
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		body = &RequestBody{Mode: BodyURLEncoded, URLEncoded: []FormField{}}
+		if source.MimeType == "multipart/form-data" {
+			body = &RequestBody{Mode: BodyFormData, FormData: []FormField{}}
+		}
+		for _, param := range source.Params {
+			if param.Name == "" {
+				i.report.Warn(name, "Form field without a name was not imported")
+				continue
+			}
+			field := FormField{Key: i.template(name, param.Name), ContentType: param.ContentType, Disabled: param.Disabled}
+			if param.Type == "file" {
+				if param.FileName == "" {
+					i.report.Warn(name, fmt.Sprintf("File field %s has no file and was not imported", param.Name))
+					continue
+				}
+				field.Type, field.FilePath = FormFieldFile, param.FileName
+			} else {
+				field.Value = i.template(name, param.Value)
+			}
+			if body.Mode == BodyFormData {
+				body.FormData = append(body.FormData, field)
+			} else {
+				body.URLEncoded = append(body.URLEncoded, field)
+			}
+		}
+
+	case "application/octet-stream":
+		if source.FileName == "" {
+			return nil
+		}
+		body = &RequestBody{Mode: BodyBinary, FilePath: source.FileName}
+
+	default:
+		body = &RequestBody{Mode: BodyRaw, Raw: i.template(name, source.Text), ContentType: source.MimeType}
+	}
+
+	if err := body.Validate(); err != nil {
+		i.report.Warn(name, fmt.Sprintf("Invalid %s body was not imported", source.MimeType))
+		return nil
+	}
+	return body
+}
+
+func (i *insomniaImporter) convertAuth(name string, source InsomniaAuth) *Auth {
+	if source.Type == "" || source.Type == "none" {
+		return nil
+	}
+	if source.Disabled {
+		i.report.Warn(name, "Disabled authentication was not imported")
+		return nil
+	}
+
+	template := func(value string) string {
+		return i.template(name, value)
+	}
+	switch source.Type {
+	case "basic":
+		return &Auth{Type: AuthBasic, Params: map[string]string{"username": template(source.Username), "password": template(source.Password)}}
+	case "bearer":
+		if prefix := strings.TrimSpace(source.Prefix); prefix != "" && !strings.EqualFold(prefix, "Bearer") {
+			i.report.Warn(name, fmt.Sprintf("Token prefix %s was replaced by Bearer", prefix))
+		}
+		return &Auth{Type: AuthBearer, Params: map[string]string{"token": template(source.Token)}}
+	case "apikey":
+		in := "header"
+		if source.AddTo == "queryParams" {
+			in = "query"
+		} else if source.AddTo == "cookie" {
+			i.report.Warn(name, fmt.Sprintf("API key %s was sent as a cookie, it is now sent as a header", source.Key))
+		}
+		return &Auth{Type: AuthAPIKey, Params: map[string]string{"key": template(source.Key), "value": template(source.Value), "in": in}}
+	case "iam":
+		return &Auth{Type: AuthAWSSigV4, Params: map[string]string{
+			"access_key":    template(source.AccessKeyID),
+			"secret_key":    template(source.SecretAccessKey),
+			"session_token": template(source.SessionToken),
+			"region":        template(source.Region),
+			"service":       template(source.Service),
+		}}
+	case "oauth2":
+		// Tokens are fetched by Insomnia and never exported
+		i.report.Warn(name, "OAuth 2.0 tokens are not part of Insomnia exports, set the access token")
+		return &Auth{Type: AuthOAuth2, Params: map[string]string{"access_token": ""}}
+	case "digest":
+		i.report.Warn(name, "Digest credentials were not imported, set them along with the server realm and nonce")
+		return nil
+	}
+
+	i.report.Warn(name, fmt.Sprintf("Unsupported auth type %s, the request was imported without authentication", source.Type))
+	return nil
+}
+
+// template rewrites Insomnia variables as Pumoide {{variables}} and reports the template tags,
+// which are left untouched.
+func (i *insomniaImporter) template(name string, value string) string {
+	for _, match := range insomniaTag.FindAllStringSubmatch(value, -1) {
+		i.report.Warn(name, fmt.Sprintf("Template tag %s is not supported and was left as is", match[1]))
+	}
+	return insomniaVariable.ReplaceAllString(value, "{{$1}}")
+}
+
+// importEnvironments returns the sub environments of the workspace merged with its base
+// environment, or the base environment alone when it has none.
+func (i *insomniaImporter) importEnvironments(workspace InsomniaResource) []*Environment {
+	var environments []*Environment
+	for _, base := range i.children[workspace.ID] {
+		if base.Type != "environment" {
+			continue
+		}
+
+		variables := make(map[string]string)
+		i.flattenVariables("", base.Data, variables)
+		for key, value := range i.variables {
+			if _, ok := variables[key]; !ok {
+				variables[key] = value
+			}
+		}
+
+		subEnvironments := 0
+		for _, child := range i.children[base.ID] {
+			if child.Type != "environment" {
+				continue
+			}
+			subEnvironments++
+			merged := make(map[string]string, len(variables))
+			for key, value := range variables {
+				merged[key] = value
+			}
+			i.flattenVariables("", child.Data, merged)
+			environments = append(environments, &Environment{Name: child.Name, Variables: merged})
+		}
+		if subEnvironments == 0 && len(variables) > 0 {
+			environments = append(environments, &Environment{Name: defaultString(base.Name, workspace.Name), Variables: variables})
+		}
+	}
+	if len(environments) == 0 && len(i.variables) > 0 {
+		environments = append(environments, &Environment{Name: workspace.Name, Variables: i.variables})
+	}
+	return environments
+}
+
+// flattenVariables writes nested variables with their dotted path, as templates read them.
+func (i *insomniaImporter) flattenVariables(prefix string, data map[string]interface{}, variables map[string]string) {
+	for key, value := range data {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			i.flattenVariables(prefix+key+".", typed, variables)
+		case string:
+			variables[prefix+key] = i.template("", typed)
+		case nil:
+			variables[prefix+key] = ""
+		default:
+			encoded, _ := json.Marshal(typed)
+			variables[prefix+key] = string(encoded)
+		}
+	}
+}
@@ -0,0 +1,1441 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// maxOpenAPIExampleDepth bounds the examples generated from recursive schemas
+const maxOpenAPIExampleDepth = 8
+
+type OpenAPIDocument struct {
+	OpenAPI    string                `json:"openapi"`
+	Swagger    string                `json:"swagger,omitempty"`
+	Info       OpenAPIInfo           `json:"info"`
+	Servers    []OpenAPIServer       `json:"servers,omitempty"`
+	Paths      OpenAPIPaths          `json:"paths"`
+	Components OpenAPIComponents     `json:"components,omitempty"`
+	Security   []map[string][]string `json:"security,omitempty"`
+	Tags       []OpenAPITag          `json:"tags,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	URL         string                           `json:"url"`
+	Description string                           `json:"description,omitempty"`
+	Variables   map[string]OpenAPIServerVariable `json:"variables,omitempty"`
+}
+
+type OpenAPIServerVariable struct {
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+type OpenAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIPaths keeps the paths in the order of the document.
+type OpenAPIPaths struct {
+	Keys  []string
+	Items map[string]OpenAPIPathItem
+}
+
+type OpenAPIPathItem struct {
+	Summary     string             `json:"summary,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Servers     []OpenAPIServer    `json:"servers,omitempty"`
+	Parameters  []OpenAPIParameter `json:"parameters,omitempty"`
+	Get         *OpenAPIOperation  `json:"get,omitempty"`
+	Put         *OpenAPIOperation  `json:"put,omitempty"`
+	Post        *OpenAPIOperation  `json:"post,omitempty"`
+	Delete      *OpenAPIOperation  `json:"delete,omitempty"`
+	Options     *OpenAPIOperation  `json:"options,omitempty"`
+	Head        *OpenAPIOperation  `json:"head,omitempty"`
+	Patch       *OpenAPIOperation  `json:"patch,omitempty"`
+	Trace       *OpenAPIOperation  `json:"trace,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                     `json:"operationId,omitempty"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Servers     []OpenAPIServer            `json:"servers,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses,omitempty"`
+	// Security is nil when the operation uses the document security, an empty list disables it
+	Security   *[]map[string][]string `json:"security,omitempty"`
+	Deprecated bool                   `json:"deprecated,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Ref         string                    `json:"$ref,omitempty"`
+	Name        string                    `json:"name,omitempty"`
+	In          string                    `json:"in,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Required    bool                      `json:"required,omitempty"`
+	Schema      *OpenAPISchema            `json:"schema,omitempty"`
+	Example     interface{}               `json:"example,omitempty"`
+	Examples    map[string]OpenAPIExample `json:"examples,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Ref         string                      `json:"$ref,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Required    bool                        `json:"required,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIResponse struct {
+	Ref         string                      `json:"$ref,omitempty"`
+	Description string                      `json:"description"`
+	Headers     map[string]OpenAPIParameter `json:"headers,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema   *OpenAPISchema            `json:"schema,omitempty"`
+	Example  interface{}               `json:"example,omitempty"`
+	Examples map[string]OpenAPIExample `json:"examples,omitempty"`
+}
+
+type OpenAPIExample struct {
+	Ref     string      `json:"$ref,omitempty"`
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value,omitempty"`
+}
+
+type OpenAPISchema struct {
+	Ref         string                    `json:"$ref,omitempty"`
+	Type        OpenAPITypes              `json:"type,omitempty"`
+	Format      string                    `json:"format,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Properties  map[string]*OpenAPISchema `json:"properties,omitempty"`
+	Required    []string                  `json:"required,omitempty"`
+	Items       *OpenAPISchema            `json:"items,omitempty"`
+	AllOf       []*OpenAPISchema          `json:"allOf,omitempty"`
+	OneOf       []*OpenAPISchema          `json:"oneOf,omitempty"`
+	AnyOf       []*OpenAPISchema          `json:"anyOf,omitempty"`
+	Enum        []interface{}             `json:"enum,omitempty"`
+	Const       interface{}               `json:"const,omitempty"`
+	Default     interface{}               `json:"default,omitempty"`
+	Example     interface{}               `json:"example,omitempty"`
+	// Examples is the 3.1 list of examples
+	Examples []interface{} `json:"examples,omitempty"`
+}
+
+// OpenAPITypes is the schema type, 3.1 documents may list several of them.
+type OpenAPITypes []string
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema        `json:"schemas,omitempty"`
+	Parameters      map[string]OpenAPIParameter      `json:"parameters,omitempty"`
+	RequestBodies   map[string]OpenAPIRequestBody    `json:"requestBodies,omitempty"`
+	Responses       map[string]OpenAPIResponse       `json:"responses,omitempty"`
+	Examples        map[string]OpenAPIExample        `json:"examples,omitempty"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+func (p *OpenAPIPaths) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return fmt.Errorf("paths must be an object")
+	}
+
+	p.Items = make(map[string]OpenAPIPathItem)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+
+		var item OpenAPIPathItem
+		if err := decoder.Decode(&item); err != nil {
+			return fmt.Errorf("path %s: %w", key, err)
+		}
+		if _, ok := p.Items[key]; !ok {
+			p.Keys = append(p.Keys, key)
+		}
+		p.Items[key] = item
+	}
+	return nil
+}
+
+func (p OpenAPIPaths) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range p.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		item, err := json.Marshal(p.Items[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(item)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (t *OpenAPITypes) UnmarshalJSON(data []byte) error {
+	if data[0] == '[' {
+		return json.Unmarshal(data, (*[]string)(t))
+	}
+
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*t = OpenAPITypes{single}
+	return nil
+}
+
+func (t OpenAPITypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// primary returns the type of the schema, ignoring the "null" type of nullable 3.1 schemas.
+func (t OpenAPITypes) primary() string {
+	for _, name := range t {
+		if name != "null" {
+			return name
+		}
+	}
+	return ""
+}
+
+// ParseOpenAPIDocument reads a JSON or YAML OpenAPI 3.0 or 3.1 document.
+func ParseOpenAPIDocument(data []byte) (*OpenAPIDocument, error) {
+	data, err := yamlToJSON(data)
+	if err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to parse OpenAPI document", err)
+	}
+
+	var document OpenAPIDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to parse OpenAPI document", err)
+	}
+
+	if document.Swagger != "" {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Only OpenAPI 3.0 and 3.1 documents are supported",
+			fmt.Errorf("swagger %s", document.Swagger))
+	}
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Only OpenAPI 3.0 and 3.1 documents are supported",
+			fmt.Errorf("openapi %q", document.OpenAPI))
+	}
+
+	return &document, nil
+}
+
+// yamlToJSON converts a YAML document to JSON, keeping the order of the keys. JSON is valid YAML,
+// so JSON documents go through unchanged.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if node.Kind == 0 {
+		return nil, fmt.Errorf("empty document")
+	}
+
+	var buf bytes.Buffer
+	if err := writeYAMLNode(&buf, &node); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeYAMLNode(buf *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		return writeYAMLNode(buf, node.Content[0])
+
+	case yaml.AliasNode:
+		return writeYAMLNode(buf, node.Alias)
+
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeYAMLNode(buf, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeYAMLNode(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+
+	case yaml.ScalarNode:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		// Non-string scalars that JSON can't hold, like timestamps, are kept as written
+		switch value.(type) {
+		case nil, bool, int, int64, uint64, float64, string:
+		default:
+			value = node.Value
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+
+	return nil
+}
+
+// ImportOpenAPIDocument generates a collection with one request per operation, in a folder per
+// tag, the first one of each operation. Each server becomes an environment holding the baseUrl,
+// along with the path parameters and the credentials referenced as {{variables}}.
+func ImportOpenAPIDocument(data []byte) (*ImportResult, error) {
+	document, err := ParseOpenAPIDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	converter := openAPIConverter{
+		document:  document,
+		report:    NewImportReport(),
+		variables: make(map[string]string),
+		folders:   make(map[string]*Folder),
+		items:     []CollectionItem{},
+	}
+
+	name := document.Info.Title
+	if name == "" {
+		name = "Imported API"
+	}
+	result := &ImportResult{
+		Collection: Collection{
+			ID:          uuid.New().String(),
+			Name:        name,
+			Description: document.Info.Description,
+		},
+	}
+
+	for _, path := range document.Paths.Keys {
+		result.Requests = append(result.Requests, converter.convertPath(path, document.Paths.Items[path])...)
+	}
+	result.Items = converter.items
+	result.Environments = converter.environments(name)
+	result.Report = converter.report
+
+	if err := result.Collection.Validate(); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid imported collection", err)
+	}
+
+	return result, nil
+}
+
+type openAPIConverter struct {
+	document *OpenAPIDocument
+	report   ImportReport
+	// variables are the {{variables}} used by the requests, with their example value
+	variables map[string]string
+	// folders holds the folder of each tag, items the tree they are added to in order
+	folders map[string]*Folder
+	items   []CollectionItem
+}
+
+var openAPIPathParameter = regexp.MustCompile(`\{([^{}]+)\}`)
+
+func (c *openAPIConverter) convertPath(path string, item OpenAPIPathItem) []Request {
+	operations := []struct {
+		method    Method
+		operation *OpenAPIOperation
+	}{
+		{MethodGet, item.Get},
+		{MethodPut, item.Put},
+		{MethodPost, item.Post},
+		{MethodDelete, item.Delete},
+		{MethodOptions, item.Options},
+		{MethodHead, item.Head},
+		{MethodPatch, item.Patch},
+		{MethodTrace, item.Trace},
+	}
+
+	var requests []Request
+	for _, entry := range operations {
+		if entry.operation == nil {
+			continue
+		}
+
+		request, err := c.convertOperation(path, item, entry.method, entry.operation)
+		if err != nil {
+			c.report.Skip(fmt.Sprintf("%s %s", entry.method, path), err.Error())
+			continue
+		}
+		requests = append(requests, request)
+		c.addItem(entry.operation.Tags, request.ID)
+		c.report.Imported++
+	}
+	return requests
+}
+
+// addItem adds a request to the folder of its first tag, created on first use, or to the root.
+func (c *openAPIConverter) addItem(tags []string, requestID string) {
+	if len(tags) == 0 || tags[0] == "" {
+		c.items = append(c.items, CollectionItem{RequestID: requestID})
+		return
+	}
+
+	folder, ok := c.folders[tags[0]]
+	if !ok {
+		folder = &Folder{ID: uuid.New().String(), Name: tags[0], Items: []CollectionItem{}}
+		for _, tag := range c.document.Tags {
+			if tag.Name == folder.Name {
+				folder.Description = tag.Description
+			}
+		}
+		c.folders[tags[0]] = folder
+		c.items = append(c.items, CollectionItem{Folder: folder})
+	}
+	folder.Items = append(folder.Items, CollectionItem{RequestID: requestID})
+}
+
+func (c *openAPIConverter) convertOperation(path string, item OpenAPIPathItem, method Method, operation *OpenAPIOperation) (Request, error) {
+	name := operation.Summary
+	if name == "" {
+		name = operation.OperationID
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s %s", method, path)
+	}
+
+	baseURL := "{{baseUrl}}"
+	servers := operation.Servers
+	if len(servers) == 0 {
+		servers = item.Servers
+	}
+	if len(servers) > 0 {
+		baseURL = serverURL(servers[0])
+	}
+
+	request := Request{
+		ID:     uuid.New().String(),
+		Name:   name,
+		Method: method,
+		URL:    strings.TrimSuffix(baseURL, "/") + openAPIPathParameter.ReplaceAllString(path, "{{$1}}"),
+	}
+
+	var cookies []string
+	for _, parameter := range c.parameters(item.Parameters, operation.Parameters) {
+		value := c.parameterValue(parameter)
+
+		switch parameter.In {
+		case "path":
+			c.addVariable(parameter.Name, value)
+		case "query":
+			if !parameter.Required {
+				continue
+			}
+			if request.QueryParams == nil {
+				request.QueryParams = make(map[string]string)
+			}
+			request.QueryParams[parameter.Name] = value
+		case "header":
+			// OpenAPI describes these headers with the body and security instead
+			switch http.CanonicalHeaderKey(parameter.Name) {
+			case "Accept", "Content-Type", "Authorization":
+				continue
+			}
+			if parameter.Required {
+				request.Headers = append(request.Headers, Header{Key: parameter.Name, Value: value})
+			}
+		case "cookie":
+			if parameter.Required {
+				cookies = append(cookies, parameter.Name+"="+value)
+			}
+		}
+	}
+	if len(cookies) > 0 {
+		request.Headers = append(request.Headers, Header{Key: "Cookie", Value: strings.Join(cookies, "; ")})
+	}
+
+	if operation.RequestBody != nil {
+		request.BodyConfig = c.convertBody(name, operation.RequestBody)
+	}
+
+	security := c.document.Security
+	if operation.Security != nil {
+		security = *operation.Security
+	}
+	request.Auth = c.convertSecurity(name, security)
+
+	if err := request.Validate(); err != nil {
+		return Request{}, err
+	}
+	return request, nil
+}
+
+// parameters merges the path item parameters with the operation ones, which override them.
+func (c *openAPIConverter) parameters(shared []OpenAPIParameter, own []OpenAPIParameter) []OpenAPIParameter {
+	var parameters []OpenAPIParameter
+	index := make(map[string]int)
+
+	for _, parameter := range append(append([]OpenAPIParameter{}, shared...), own...) {
+		resolved, ok := c.resolveParameter(parameter)
+		if !ok {
+			c.report.Warn("", fmt.Sprintf("Unresolved parameter reference %s", parameter.Ref))
+			continue
+		}
+
+		key := resolved.In + ":" + resolved.Name
+		if i, ok := index[key]; ok {
+			parameters[i] = resolved
+			continue
+		}
+		index[key] = len(parameters)
+		parameters = append(parameters, resolved)
+	}
+	return parameters
+}
+
+func (c *openAPIConverter) parameterValue(parameter OpenAPIParameter) string {
+	if parameter.Example != nil {
+		return exampleString(parameter.Example)
+	}
+	if example, ok := c.firstExample(parameter.Examples); ok {
+		return exampleString(example)
+	}
+	return exampleString(c.example(parameter.Schema, 0))
+}
+
+func (c *openAPIConverter) convertBody(name string, body *OpenAPIRequestBody) *RequestBody {
+	body, ok := c.resolveRequestBody(body)
+	if !ok || len(body.Content) == 0 {
+		return nil
+	}
+
+	mediaType, content := preferredMediaType(body.Content)
+	var example interface{}
+	if content.Example != nil {
+		example = content.Example
+	} else if value, ok := c.firstExample(content.Examples); ok {
+		example = value
+	} else {
+		example = c.example(content.Schema, 0)
+	}
+
+	switch {
+	case isJSONMediaType(mediaType):
+		raw := ""
+		if example != nil {
+			encoded, err := json.MarshalIndent(example, "", "  ")
+			if err != nil {
+				c.report.Warn(name, "The body example could not be encoded")
+				return &RequestBody{Mode: BodyJSON}
+			}
+			raw = string(encoded)
+		}
+		requestBody := &RequestBody{Mode: BodyJSON, Raw: raw}
+		if mediaType != "application/json" {
+			requestBody.ContentType = mediaType
+		}
+		return requestBody
+
+	case mediaType == "application/x-www-form-urlencoded":
+		return &RequestBody{Mode: BodyURLEncoded, URLEncoded: c.formFields(name, content.Schema, example, false)}
+
+	case mediaType == "multipart/form-data":
+		return &RequestBody{Mode: BodyFormData, FormData: c.formFields(name, content.Schema, example, true)}
+
+	case mediaType == "application/octet-stream" || strings.HasPrefix(mediaType, "image/"):
+		c.report.Warn(name, fmt.Sprintf("The %s body needs a file, it was not imported", mediaType))
+		return nil
+	}
+
+	return &RequestBody{Mode: BodyRaw, Raw: exampleString(example), ContentType: mediaType}
+}
+
+func (c *openAPIConverter) formFields(name string, schema *OpenAPISchema, example interface{}, multipart bool) []FormField {
+	schema = c.resolveSchema(schema)
+	values, _ := example.(map[string]interface{})
+	fields := []FormField{}
+	if schema == nil {
+		return fields
+	}
+
+	keys := make([]string, 0, len(schema.Properties))
+	for key := range schema.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property := c.resolveSchema(schema.Properties[key])
+		if property != nil && (property.Format == "binary" || property.Format == "base64") {
+			if multipart {
+				c.report.Warn(name, fmt.Sprintf("File field %s needs a file, it was not imported", key))
+			}
+			continue
+		}
+		fields = append(fields, FormField{Key: key, Value: exampleString(values[key])})
+	}
+	return fields
+}
+
+// convertSecurity maps the first security requirement. Credentials are left as {{variables}}
+// named after the scheme, which are added to the environments.
+func (c *openAPIConverter) convertSecurity(name string, requirements []map[string][]string) *Auth {
+	if len(requirements) == 0 {
+		return nil
+	}
+	if len(requirements) > 1 {
+		c.report.Warn(name, "Only the first of the alternative security requirements was imported")
+	}
+
+	schemeNames := make([]string, 0, len(requirements[0]))
+	for schemeName := range requirements[0] {
+		schemeNames = append(schemeNames, schemeName)
+	}
+	if len(schemeNames) == 0 {
+		return nil
+	}
+	sort.Strings(schemeNames)
+	if len(schemeNames) > 1 {
+		c.report.Warn(name, fmt.Sprintf("Only the %s security scheme was imported, requests support a single one", schemeNames[0]))
+	}
+
+	schemeName := schemeNames[0]
+	scheme, ok := c.document.Components.SecuritySchemes[schemeName]
+	if !ok {
+		c.report.Warn(name, fmt.Sprintf("Unknown security scheme %s", schemeName))
+		return nil
+	}
+
+	switch {
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+		c.addVariable("username", "")
+		c.addVariable("password", "")
+		return &Auth{Type: AuthBasic, Params: map[string]string{"username": "{{username}}", "password": "{{password}}"}}
+
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+		c.addVariable(schemeName, "")
+		return &Auth{Type: AuthBearer, Params: map[string]string{"token": "{{" + schemeName + "}}"}}
+
+	case scheme.Type == "apiKey" && (scheme.In == "header" || scheme.In == "query"):
+		c.addVariable(schemeName, "")
+		return &Auth{Type: AuthAPIKey, Params: map[string]string{"key": scheme.Name, "value": "{{" + schemeName + "}}", "in": scheme.In}}
+
+	case scheme.Type == "oauth2" || scheme.Type == "openIdConnect":
+		c.addVariable(schemeName, "")
+		return &Auth{Type: AuthOAuth2, Params: map[string]string{"access_token": "{{" + schemeName + "}}"}}
+
+	case scheme.Type == "mutualTLS":
+		c.report.Warn(name, "Mutual TLS is configured with client certificates, not on the request")
+		return nil
+	}
+
+	description := scheme.Type
+	if scheme.Scheme != "" {
+		description += " " + scheme.Scheme
+	} else if scheme.In != "" {
+		description += " in " + scheme.In
+	}
+	c.report.Warn(name, fmt.Sprintf("Unsupported security scheme %s (%s)", schemeName, description))
+	return nil
+}
+
+// addVariable records a variable, keeping the first non-empty example value seen.
+func (c *openAPIConverter) addVariable(name string, value string) {
+	if current, ok := c.variables[name]; !ok || current == "" {
+		c.variables[name] = value
+	}
+}
+
+// environments creates an environment per server, all of them holding the request variables.
+func (c *openAPIConverter) environments(name string) []*Environment {
+	servers := c.document.Servers
+	if len(servers) == 0 {
+		c.report.Warn("", "The document declares no servers, set the baseUrl variable of the environment")
+		servers = []OpenAPIServer{{URL: ""}}
+	}
+
+	environments := make([]*Environment, 0, len(servers))
+	for _, server := range servers {
+		variables := map[string]string{"baseUrl": strings.TrimSuffix(serverURL(server), "/")}
+		for key, value := range c.variables {
+			variables[key] = value
+		}
+
+		environmentName := name
+		if len(servers) > 1 {
+			label := server.Description
+			if label == "" {
+				label = variables["baseUrl"]
+			}
+			environmentName = fmt.Sprintf("%s (%s)", name, label)
+		}
+
+		if strings.HasPrefix(server.URL, "/") {
+			c.report.Warn("", fmt.Sprintf("Server %s is relative to the location of the document, complete its baseUrl", server.URL))
+		}
+		environments = append(environments, &Environment{Name: environmentName, Variables: variables})
+	}
+	return environments
+}
+
+// serverURL applies the default values of the server variables.
+func serverURL(server OpenAPIServer) string {
+	url := server.URL
+	for name, variable := range server.Variables {
+		url = strings.ReplaceAll(url, "{"+name+"}", variable.Default)
+	}
+	return url
+}
+
+// example builds a value for the schema, preferring the examples and defaults it declares.
+func (c *openAPIConverter) example(schema *OpenAPISchema, depth int) interface{} {
+	schema = c.resolveSchema(schema)
+	if schema == nil || depth > maxOpenAPIExampleDepth {
+		return nil
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Default != nil:
+		return schema.Default
+	case schema.Const != nil:
+		return schema.Const
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		merged := make(map[string]interface{})
+		for _, part := range schema.AllOf {
+			value := c.example(part, depth+1)
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			for key, property := range object {
+				merged[key] = property
+			}
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return c.example(schema.OneOf[0], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return c.example(schema.AnyOf[0], depth+1)
+	}
+
+	schemaType := schema.Type.primary()
+	if schemaType == "" && schema.Properties != nil {
+		schemaType = "object"
+	}
+
+	switch schemaType {
+	case "object":
+		object := make(map[string]interface{}, len(schema.Properties))
+		for key, property := range schema.Properties {
+			object[key] = c.example(property, depth+1)
+		}
+		return object
+	case "array":
+		if item := c.example(schema.Items, depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "string":
+		return stringExample(schema.Format)
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "time":
+		return "00:00:00"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "ipv6":
+		return "::1"
+	}
+	return "string"
+}
+
+func (c *openAPIConverter) firstExample(examples map[string]OpenAPIExample) (interface{}, bool) {
+	if len(examples) == 0 {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(examples))
+	for key := range examples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	example := examples[keys[0]]
+	if example.Ref != "" {
+		resolved, ok := c.document.Components.Examples[strings.TrimPrefix(example.Ref, "#/components/examples/")]
+		if !ok {
+			return nil, false
+		}
+		example = resolved
+	}
+	return example.Value, example.Value != nil
+}
+
+// resolveSchema follows local references, only references to the components are supported.
+func (c *openAPIConverter) resolveSchema(schema *OpenAPISchema) *OpenAPISchema {
+	for i := 0; schema != nil && schema.Ref != "" && i < maxOpenAPIExampleDepth; i++ {
+		resolved, ok := c.document.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			c.report.Warn("", fmt.Sprintf("Unresolved schema reference %s", schema.Ref))
+			return nil
+		}
+		schema = resolved
+	}
+	return schema
+}
+
+func (c *openAPIConverter) resolveParameter(parameter OpenAPIParameter) (OpenAPIParameter, bool) {
+	if parameter.Ref == "" {
+		return parameter, true
+	}
+	resolved, ok := c.document.Components.Parameters[strings.TrimPrefix(parameter.Ref, "#/components/parameters/")]
+	return resolved, ok && resolved.Ref == ""
+}
+
+func (c *openAPIConverter) resolveRequestBody(body *OpenAPIRequestBody) (*OpenAPIRequestBody, bool) {
+	if body.Ref == "" {
+		return body, true
+	}
+	resolved, ok := c.document.Components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]
+	if !ok {
+		c.report.Warn("", fmt.Sprintf("Unresolved request body reference %s", body.Ref))
+	}
+	return &resolved, ok
+}
+
+// preferredMediaType picks JSON, then forms, then whatever comes first alphabetically.
+func preferredMediaType(content map[string]OpenAPIMediaType) (string, OpenAPIMediaType) {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	rank := func(mediaType string) int {
+		switch {
+		case mediaType == "application/json":
+			return 0
+		case isJSONMediaType(mediaType):
+			return 1
+		case mediaType == "application/x-www-form-urlencoded":
+			return 2
+		case mediaType == "multipart/form-data":
+			return 3
+		}
+		return 4
+	}
+	sort.SliceStable(mediaTypes, func(i, j int) bool {
+		return rank(mediaTypes[i]) < rank(mediaTypes[j])
+	})
+
+	return mediaTypes[0], content[mediaTypes[0]]
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// exampleString formats an example as a parameter or form value.
+func exampleString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+const OpenAPIVersion = "3.0.3"
+
+// ToOpenAPIDocument describes the HTTP requests of the collection as an OpenAPI document. The
+// variables of the server part of the URLs become server variables and those of the path become
+// path parameters, both defaulting to the environment values. The recorded responses of each
+// request, newest first, give the response of each status code. Requests sharing a method and a
+// path are described by the first of them. The top-level folder of a request gives its tag.
+func (c *Collection) ToOpenAPIDocument(env *Environment, responses map[string][]ExecutionResponse) *OpenAPIDocument {
+	exporter := openAPIExporter{
+		document: &OpenAPIDocument{
+			OpenAPI: OpenAPIVersion,
+			Info:    OpenAPIInfo{Title: c.Name, Description: c.Description, Version: "1.0.0"},
+			Paths:   OpenAPIPaths{Items: make(map[string]OpenAPIPathItem)},
+		},
+		env:          env,
+		operationIDs: make(map[string]bool),
+	}
+
+	tags := make(map[string]string)
+	for _, item := range c.tree() {
+		if item.Folder == nil {
+			continue
+		}
+		ids := make(map[string]bool)
+		folderRequests(item.Folder.Items, ids)
+		for id := range ids {
+			tags[id] = item.Folder.Name
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, req := range c.Requests {
+		if req.Type.IsHTTP() && exporter.addRequest(req, tags[req.ID], responses[req.ID]) && tags[req.ID] != "" {
+			used[tags[req.ID]] = true
+		}
+	}
+	for _, item := range c.tree() {
+		if item.Folder != nil && used[item.Folder.Name] {
+			delete(used, item.Folder.Name)
+			exporter.document.Tags = append(exporter.document.Tags, OpenAPITag{Name: item.Folder.Name, Description: item.Folder.Description})
+		}
+	}
+
+	return exporter.document
+}
+
+// ToYAML writes the document as YAML, in the same order as its JSON form.
+func (d *OpenAPIDocument) ToYAML() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	clearYAMLStyle(&node)
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// clearYAMLStyle turns the flow style of a node decoded from JSON into the usual block style.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+type openAPIExporter struct {
+	document     *OpenAPIDocument
+	env          *Environment
+	operationIDs map[string]bool
+}
+
+var variablePattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// addRequest describes the request as an operation, unless one already exists for its method and path.
+func (e *openAPIExporter) addRequest(req Request, tag string, responses []ExecutionResponse) bool {
+	server, rawPath, rawQuery := splitRequestURL(req.URL)
+	path := variablePattern.ReplaceAllString(rawPath, "{$1}")
+
+	item, exists := e.document.Paths.Items[path]
+	method := req.Method
+	if method == "" {
+		method = MethodGet
+	}
+	operation := item.operation(method)
+	if *operation != nil {
+		return false
+	}
+
+	op := &OpenAPIOperation{
+		OperationID: e.operationID(strings.TrimSpace(tag + " " + req.Name)),
+		Summary:     req.Name,
+		Responses:   e.responses(responses),
+	}
+	if tag != "" {
+		op.Tags = []string{tag}
+	}
+
+	if server != "" {
+		openAPIServer := e.server(server)
+		switch {
+		case len(e.document.Servers) == 0:
+			e.document.Servers = []OpenAPIServer{openAPIServer}
+		case e.document.Servers[0].URL != openAPIServer.URL:
+			op.Servers = []OpenAPIServer{openAPIServer}
+		}
+	}
+
+	for _, match := range variablePattern.FindAllStringSubmatch(rawPath, -1) {
+		op.Parameters = appendOpenAPIParameter(op.Parameters, match[1], "path", e.variable(match[1]))
+	}
+	query := parsePostmanQuery(rawQuery)
+	keys := make([]string, 0, len(req.QueryParams))
+	for key := range req.QueryParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		query = append(query, PostmanKeyValue{Key: key, Value: PostmanValue(req.QueryParams[key])})
+	}
+	for _, param := range query {
+		op.Parameters = appendOpenAPIParameter(op.Parameters, unescapeQuery(param.Key), "query", unescapeQuery(string(param.Value)))
+	}
+
+	contentType := ""
+	for _, header := range req.Headers {
+		switch http.CanonicalHeaderKey(header.Key) {
+		case "Content-Type":
+			contentType = header.Value
+		case "Accept", "Authorization":
+		default:
+			op.Parameters = appendOpenAPIParameter(op.Parameters, header.Key, "header", header.Value)
+		}
+	}
+
+	op.RequestBody = openAPIRequestBody(req, contentType)
+	op.Security = e.security(req.Auth)
+
+	if !exists {
+		e.document.Paths.Keys = append(e.document.Paths.Keys, path)
+	}
+	*operation = op
+	e.document.Paths.Items[path] = item
+	return true
+}
+
+// splitRequestURL splits a URL into its server, its path and its query. A leading variable, like
+// {{baseUrl}}, stands for the server.
+func splitRequestURL(raw string) (string, string, string) {
+	raw, _, _ = strings.Cut(raw, "#")
+	raw, rawQuery, _ := strings.Cut(raw, "?")
+
+	start := 0
+	if index := strings.Index(raw, "://"); index >= 0 {
+		start = index + 3
+	} else if strings.HasPrefix(raw, "{{") {
+		if end := strings.Index(raw, "}}"); end >= 0 {
+			start = end + 2
+		}
+	}
+
+	slash := strings.Index(raw[start:], "/")
+	if slash < 0 {
+		return raw, "/", rawQuery
+	}
+	return raw[:start+slash], raw[start+slash:], rawQuery
+}
+
+func (item *OpenAPIPathItem) operation(method Method) **OpenAPIOperation {
+	switch method {
+	case MethodPut:
+		return &item.Put
+	case MethodPost:
+		return &item.Post
+	case MethodDelete:
+		return &item.Delete
+	case MethodOptions:
+		return &item.Options
+	case MethodHead:
+		return &item.Head
+	case MethodPatch:
+		return &item.Patch
+	case MethodTrace:
+		return &item.Trace
+	}
+	return &item.Get
+}
+
+func (e *openAPIExporter) server(raw string) OpenAPIServer {
+	server := OpenAPIServer{URL: variablePattern.ReplaceAllString(raw, "{$1}")}
+	for _, match := range variablePattern.FindAllStringSubmatch(raw, -1) {
+		if server.Variables == nil {
+			server.Variables = make(map[string]OpenAPIServerVariable)
+		}
+		server.Variables[match[1]] = OpenAPIServerVariable{Default: e.variable(match[1])}
+	}
+	return server
+}
+
+func (e *openAPIExporter) variable(name string) string {
+	if e.env == nil {
+		return ""
+	}
+	return e.env.Variables[name]
+}
+
+// operationID derives a unique camel case identifier from the request name.
+func (e *openAPIExporter) operationID(name string) string {
+	var builder strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			if builder.Len() == 0 {
+				builder.WriteString(strings.ToLower(string(r)))
+			} else if upper {
+				builder.WriteString(strings.ToUpper(string(r)))
+			} else {
+				builder.WriteRune(r)
+			}
+			upper = false
+		default:
+			upper = true
+		}
+	}
+
+	id := builder.String()
+	if id == "" {
+		id = "operation"
+	}
+	unique := id
+	for i := 2; e.operationIDs[unique]; i++ {
+		unique = id + strconv.Itoa(i)
+	}
+	e.operationIDs[unique] = true
+	return unique
+}
+
+func appendOpenAPIParameter(parameters []OpenAPIParameter, name string, in string, example string) []OpenAPIParameter {
+	for _, parameter := range parameters {
+		if parameter.Name == name && parameter.In == in {
+			return parameters
+		}
+	}
+
+	parameter := OpenAPIParameter{Name: name, In: in, Required: in == "path", Schema: &OpenAPISchema{Type: OpenAPITypes{"string"}}}
+	if example != "" {
+		parameter.Example = example
+	}
+	return append(parameters, parameter)
+}
+
+func openAPIRequestBody(req Request, contentType string) *OpenAPIRequestBody {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	config := req.BodyConfig
+	if config == nil {
+		if req.Body == "" {
+			return nil
+		}
+		config = &RequestBody{Mode: BodyRaw, Raw: req.Body, ContentType: mediaType}
+		if mediaType == "" && json.Valid([]byte(req.Body)) {
+			config.Mode = BodyJSON
+		}
+	}
+
+	var content OpenAPIMediaType
+	switch config.Mode {
+	case BodyJSON, BodyRaw:
+		if mediaType == "" {
+			mediaType = config.ContentType
+		}
+		if mediaType == "" {
+			mediaType = "text/plain"
+			if config.Mode == BodyJSON {
+				mediaType = "application/json"
+			}
+		}
+		content = textMediaType(mediaType, config.Raw)
+
+	case BodyURLEncoded, BodyFormData:
+		mediaType = "application/x-www-form-urlencoded"
+		fields := config.URLEncoded
+		if config.Mode == BodyFormData {
+			mediaType = "multipart/form-data"
+			fields = config.FormData
+		}
+
+		schema := &OpenAPISchema{Type: OpenAPITypes{"object"}, Properties: make(map[string]*OpenAPISchema)}
+		example := make(map[string]interface{})
+		for _, field := range fields {
+			if field.Disabled {
+				continue
+			}
+			if field.Type == FormFieldFile {
+				schema.Properties[field.Key] = &OpenAPISchema{Type: OpenAPITypes{"string"}, Format: "binary"}
+				continue
+			}
+			schema.Properties[field.Key] = &OpenAPISchema{Type: OpenAPITypes{"string"}}
+			example[field.Key] = field.Value
+		}
+		content = OpenAPIMediaType{Schema: schema}
+		if len(example) > 0 {
+			content.Example = example
+		}
+
+	case BodyBinary:
+		mediaType = defaultString(config.ContentType, "application/octet-stream")
+		content = OpenAPIMediaType{Schema: &OpenAPISchema{Type: OpenAPITypes{"string"}, Format: "binary"}}
+
+	case BodyGraphQL:
+		if config.GraphQL == nil {
+			return nil
+		}
+		example := map[string]interface{}{"query": config.GraphQL.Query}
+		if config.GraphQL.OperationName != "" {
+			example["operationName"] = config.GraphQL.OperationName
+		}
+		if variables := strings.TrimSpace(config.GraphQL.Variables); variables != "" && json.Valid([]byte(variables)) {
+			example["variables"] = json.RawMessage(variables)
+		}
+		mediaType = "application/json"
+		content = OpenAPIMediaType{Schema: inferSchema(example, 0), Example: example}
+
+	default:
+		return nil
+	}
+
+	return &OpenAPIRequestBody{Content: map[string]OpenAPIMediaType{mediaType: content}}
+}
+
+// textMediaType describes a text body, JSON ones get a schema inferred from their content.
+func textMediaType(mediaType string, body string) OpenAPIMediaType {
+	if isJSONMediaType(mediaType) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(body), &value); err == nil {
+			return OpenAPIMediaType{Schema: inferSchema(value, 0), Example: json.RawMessage(body)}
+		}
+	}
+
+	content := OpenAPIMediaType{Schema: &OpenAPISchema{Type: OpenAPITypes{"string"}}}
+	if body != "" {
+		content.Example = body
+	}
+	return content
+}
+
+func (e *openAPIExporter) responses(recorded []ExecutionResponse) map[string]OpenAPIResponse {
+	responses := make(map[string]OpenAPIResponse)
+
+	for _, response := range recorded {
+		code := strconv.Itoa(response.StatusCode)
+		if _, ok := responses[code]; ok {
+			continue
+		}
+
+		description := http.StatusText(response.StatusCode)
+		if description == "" {
+			description = "Response"
+		}
+		openAPIResponse := OpenAPIResponse{Description: description}
+
+		var contentType string
+		for _, header := range response.Headers {
+			if strings.EqualFold(header.Key, "Content-Type") {
+				contentType = header.Value
+			}
+		}
+		mediaType, _, _ := strings.Cut(contentType, ";")
+		if mediaType = strings.TrimSpace(mediaType); mediaType != "" {
+			content := OpenAPIMediaType{Schema: &OpenAPISchema{Type: OpenAPITypes{"string"}}}
+			// Truncated and binary bodies are not complete examples
+			if response.Encoding == EncodingBase64 {
+				content.Schema.Format = "binary"
+			} else if !response.Truncated && response.SavedTo == "" {
+				content = textMediaType(mediaType, response.Body)
+			}
+			openAPIResponse.Content = map[string]OpenAPIMediaType{mediaType: content}
+		}
+
+		responses[code] = openAPIResponse
+	}
+
+	if len(responses) == 0 {
+		responses["default"] = OpenAPIResponse{Description: "Default response"}
+	}
+	return responses
+}
+
+// security declares the scheme of the auth and returns the operation requirement.
+func (e *openAPIExporter) security(auth *Auth) *[]map[string][]string {
+	if auth == nil {
+		return nil
+	}
+
+	var name string
+	var scheme OpenAPISecurityScheme
+	switch auth.Type {
+	case AuthNone:
+		return &[]map[string][]string{}
+	case AuthBasic:
+		name, scheme = "basicAuth", OpenAPISecurityScheme{Type: "http", Scheme: "basic"}
+	case AuthBearer:
+		name, scheme = "bearerAuth", OpenAPISecurityScheme{Type: "http", Scheme: "bearer"}
+	case AuthDigest:
+		name, scheme = "digestAuth", OpenAPISecurityScheme{Type: "http", Scheme: "digest"}
+	case AuthOAuth2:
+		name, scheme = "oauth2Auth", OpenAPISecurityScheme{Type: "http", Scheme: "bearer", Description: "OAuth 2.0 access token"}
+	case AuthAWSSigV4:
+		name, scheme = "awsSigV4", OpenAPISecurityScheme{Type: "apiKey", In: "header", Name: "Authorization", Description: "AWS Signature Version 4"}
+	case AuthAPIKey:
+		name, scheme = "apiKeyAuth", OpenAPISecurityScheme{Type: "apiKey", In: auth.Params["in"], Name: auth.Params["key"]}
+	default:
+		return nil
+	}
+
+	schemes := e.document.Components.SecuritySchemes
+	if schemes == nil {
+		schemes = make(map[string]OpenAPISecurityScheme)
+		e.document.Components.SecuritySchemes = schemes
+	}
+
+	unique := name
+	for i := 2; ; i++ {
+		existing, ok := schemes[unique]
+		if !ok {
+			schemes[unique] = scheme
+			break
+		}
+		if existing == scheme {
+			break
+		}
+		unique = name + strconv.Itoa(i)
+	}
+
+	return &[]map[string][]string{{unique: {}}}
+}
+
+// inferSchema describes the shape of a JSON value.
+func inferSchema(value interface{}, depth int) *OpenAPISchema {
+	if depth > maxOpenAPIExampleDepth {
+		return &OpenAPISchema{}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		schema := &OpenAPISchema{Type: OpenAPITypes{"object"}, Properties: make(map[string]*OpenAPISchema, len(v))}
+		for key, property := range v {
+			schema.Properties[key] = inferSchema(property, depth+1)
+		}
+		return schema
+	case []interface{}:
+		schema := &OpenAPISchema{Type: OpenAPITypes{"array"}, Items: &OpenAPISchema{}}
+		if len(v) > 0 {
+			schema.Items = inferSchema(v[0], depth+1)
+		}
+		return schema
+	case json.RawMessage:
+		var decoded interface{}
+		if err := json.Unmarshal(v, &decoded); err != nil {
+			return &OpenAPISchema{}
+		}
+		return inferSchema(decoded, depth)
+	case string:
+		return &OpenAPISchema{Type: OpenAPITypes{"string"}}
+	case float64:
+		if v == float64(int64(v)) {
+			return &OpenAPISchema{Type: OpenAPITypes{"integer"}}
+		}
+		return &OpenAPISchema{Type: OpenAPITypes{"number"}}
+	case bool:
+		return &OpenAPISchema{Type: OpenAPITypes{"boolean"}}
+	}
+	return &OpenAPISchema{}
+}
+
+func defaultString(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
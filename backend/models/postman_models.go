@@ -0,0 +1,1094 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/google/uuid"
+)
+
+const PostmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// PostmanCollection is a Postman collection in the v2.0 or v2.1 format. Both versions only differ
+// in how auth parameters are written, which PostmanAuth handles.
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Auth     *PostmanAuth      `json:"auth,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+	Event    []json.RawMessage `json:"event,omitempty"`
+}
+
+type PostmanInfo struct {
+	PostmanID   string             `json:"_postman_id,omitempty"`
+	Name        string             `json:"name"`
+	Description PostmanDescription `json:"description,omitempty"`
+	Schema      string             `json:"schema"`
+}
+
+// PostmanItem is either a folder, holding other items, or a request.
+type PostmanItem struct {
+	ID                      string                  `json:"id,omitempty"`
+	Name                    string                  `json:"name"`
+	Description             PostmanDescription      `json:"description,omitempty"`
+	Item                    []PostmanItem           `json:"item,omitempty"`
+	Request                 *PostmanRequest         `json:"request,omitempty"`
+	Response                []json.RawMessage       `json:"response,omitempty"`
+	Auth                    *PostmanAuth            `json:"auth,omitempty"`
+	Event                   []json.RawMessage       `json:"event,omitempty"`
+	ProtocolProfileBehavior *PostmanProtocolProfile `json:"protocolProfileBehavior,omitempty"`
+	Pumoide                 *PostmanExtension       `json:"pumoide,omitempty"`
+}
+
+// PostmanExtension keeps what a v2.1 item has no place for: the request types other than HTTP,
+// with their gRPC call and message log, and the request timeout. Postman ignores it.
+type PostmanExtension struct {
+	Type      RequestType        `json:"type,omitempty"`
+	GRPC      *GRPCRequest       `json:"grpc,omitempty"`
+	Messages  []WebSocketMessage `json:"messages,omitempty"`
+	TimeoutMs int                `json:"timeoutMs,omitempty"`
+}
+
+type PostmanRequest struct {
+	Method      string             `json:"method,omitempty"`
+	URL         PostmanURL         `json:"url"`
+	Header      PostmanHeaders     `json:"header,omitempty"`
+	Body        *PostmanBody       `json:"body,omitempty"`
+	Auth        *PostmanAuth       `json:"auth,omitempty"`
+	Description PostmanDescription `json:"description,omitempty"`
+}
+
+type PostmanURL struct {
+	Raw      string            `json:"raw,omitempty"`
+	Protocol string            `json:"protocol,omitempty"`
+	Host     PostmanStrings    `json:"host,omitempty"`
+	Port     string            `json:"port,omitempty"`
+	Path     PostmanStrings    `json:"path,omitempty"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	Hash     string            `json:"hash,omitempty"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanKeyValue struct {
+	Key         string             `json:"key"`
+	Value       PostmanValue       `json:"value"`
+	Disabled    bool               `json:"disabled,omitempty"`
+	Description PostmanDescription `json:"description,omitempty"`
+}
+
+// PostmanHeaders is a header list, which v2.0 collections may also write as a single string.
+type PostmanHeaders []PostmanKeyValue
+
+type PostmanVariable struct {
+	ID       string       `json:"id,omitempty"`
+	Key      string       `json:"key"`
+	Value    PostmanValue `json:"value"`
+	Type     string       `json:"type,omitempty"`
+	Disabled bool         `json:"disabled,omitempty"`
+}
+
+type PostmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []PostmanFormField  `json:"urlencoded,omitempty"`
+	FormData   []PostmanFormField  `json:"formdata,omitempty"`
+	File       *PostmanFile        `json:"file,omitempty"`
+	GraphQL    *PostmanGraphQL     `json:"graphql,omitempty"`
+	Options    *PostmanBodyOptions `json:"options,omitempty"`
+	Disabled   bool                `json:"disabled,omitempty"`
+}
+
+type PostmanFormField struct {
+	Key   string       `json:"key"`
+	Value PostmanValue `json:"value,omitempty"`
+	Type  string       `json:"type,omitempty"`
+	// Src is the path of the uploaded file, Postman allows several files per field
+	Src         PostmanStrings `json:"src,omitempty"`
+	ContentType string         `json:"contentType,omitempty"`
+	Disabled    bool           `json:"disabled,omitempty"`
+}
+
+type PostmanFile struct {
+	Src     string `json:"src,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type PostmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+	// OperationName isn't written by Postman, which ignores it
+	OperationName string `json:"operationName,omitempty"`
+}
+
+type PostmanBodyOptions struct {
+	Raw *PostmanRawOptions `json:"raw,omitempty"`
+}
+
+type PostmanRawOptions struct {
+	Language string `json:"language,omitempty"`
+}
+
+type PostmanProtocolProfile struct {
+	FollowRedirects *bool  `json:"followRedirects,omitempty"`
+	MaxRedirects    *int   `json:"maxRedirects,omitempty"`
+	StrictSSL       *bool  `json:"strictSSL,omitempty"`
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+}
+
+// PostmanAuth holds the parameters of the auth type only, v2.0 writes them as an object and
+// v2.1 as a list of key/value pairs.
+type PostmanAuth struct {
+	Type   string
+	Params map[string]string
+}
+
+// PostmanDescription is either a plain string or an object with the text in its content.
+type PostmanDescription string
+
+// PostmanValue is a string that may be written as any JSON scalar.
+type PostmanValue string
+
+// PostmanStrings is a list of strings that may be written as a single string. Elements may also be
+// objects with the string in their value, like the path segments of v2.1 URLs.
+type PostmanStrings []string
+
+func (d *PostmanDescription) UnmarshalJSON(data []byte) error {
+	if data[0] != '{' {
+		var value PostmanValue
+		err := json.Unmarshal(data, &value)
+		*d = PostmanDescription(value)
+		return err
+	}
+
+	var description struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &description); err != nil {
+		return err
+	}
+	*d = PostmanDescription(description.Content)
+	return nil
+}
+
+func (v *PostmanValue) UnmarshalJSON(data []byte) error {
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*v = ""
+	case data[0] == '"':
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*v = PostmanValue(value)
+	default:
+		*v = PostmanValue(data)
+	}
+	return nil
+}
+
+func (s *PostmanStrings) UnmarshalJSON(data []byte) error {
+	if data[0] != '[' {
+		var value PostmanValue
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*s = nil
+		if value != "" {
+			*s = PostmanStrings{string(value)}
+		}
+		return nil
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+
+	*s = make(PostmanStrings, 0, len(elements))
+	for _, element := range elements {
+		if element[0] == '{' {
+			var segment struct {
+				Value string `json:"value"`
+			}
+			if err := json.Unmarshal(element, &segment); err != nil {
+				return err
+			}
+			*s = append(*s, segment.Value)
+			continue
+		}
+
+		var value PostmanValue
+		if err := json.Unmarshal(element, &value); err != nil {
+			return err
+		}
+		*s = append(*s, string(value))
+	}
+	return nil
+}
+
+// MarshalJSON writes the item list of folders even when it is empty, Postman tells folders from
+// requests by it.
+func (i PostmanItem) MarshalJSON() ([]byte, error) {
+	type postmanItem PostmanItem
+	if i.Request != nil || i.Item == nil {
+		return json.Marshal(postmanItem(i))
+	}
+	return json.Marshal(struct {
+		postmanItem
+		Item []PostmanItem `json:"item"`
+	}{postmanItem(i), i.Item})
+}
+
+func (r *PostmanRequest) UnmarshalJSON(data []byte) error {
+	if data[0] == '"' {
+		var raw string
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		*r = PostmanRequest{URL: PostmanURL{Raw: raw}}
+		return nil
+	}
+
+	type postmanRequest PostmanRequest
+	return json.Unmarshal(data, (*postmanRequest)(r))
+}
+
+func (u *PostmanURL) UnmarshalJSON(data []byte) error {
+	if data[0] == '"' {
+		var raw string
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		*u = PostmanURL{Raw: raw}
+		return nil
+	}
+
+	type postmanURL PostmanURL
+	return json.Unmarshal(data, (*postmanURL)(u))
+}
+
+func (h *PostmanHeaders) UnmarshalJSON(data []byte) error {
+	if data[0] != '"' {
+		return json.Unmarshal(data, (*[]PostmanKeyValue)(h))
+	}
+
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*h = nil
+	for _, line := range strings.Split(raw, "\n") {
+		key, value, _ := strings.Cut(line, ":")
+		if key = strings.TrimSpace(key); key != "" {
+			*h = append(*h, PostmanKeyValue{Key: key, Value: PostmanValue(strings.TrimSpace(value))})
+		}
+	}
+	return nil
+}
+
+func (a *PostmanAuth) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	if authType, ok := fields["type"]; ok {
+		if err := json.Unmarshal(authType, &a.Type); err != nil {
+			return fmt.Errorf("auth type: %w", err)
+		}
+	}
+	a.Params = make(map[string]string)
+
+	params, ok := fields[a.Type]
+	if !ok || bytes.Equal(params, []byte("null")) {
+		return nil
+	}
+
+	if params[0] == '[' {
+		var list []PostmanKeyValue
+		if err := json.Unmarshal(params, &list); err != nil {
+			return fmt.Errorf("%s auth: %w", a.Type, err)
+		}
+		for _, param := range list {
+			a.Params[param.Key] = string(param.Value)
+		}
+		return nil
+	}
+
+	var object map[string]PostmanValue
+	if err := json.Unmarshal(params, &object); err != nil {
+		return fmt.Errorf("%s auth: %w", a.Type, err)
+	}
+	for key, value := range object {
+		a.Params[key] = string(value)
+	}
+	return nil
+}
+
+// ImportPostmanCollection converts a Postman v2.0 or v2.1 collection. Requests that can't be
+// represented are skipped and every loss is listed in the report. Folders become collection
+// folders and report items are named after their folder path. Collection variables end up in
+// an environment, which is how Pumoide resolves {{variables}}.
+func ImportPostmanCollection(data []byte) (*ImportResult, error) {
+	var postman PostmanCollection
+	if err := json.Unmarshal(data, &postman); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Failed to parse Postman collection", err)
+	}
+
+	if postman.Info.Schema != "" && !strings.Contains(postman.Info.Schema, "/collection/v2.") {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Only Postman v2.0 and v2.1 collections are supported",
+			fmt.Errorf("schema %s", postman.Info.Schema))
+	}
+	if postman.Info.Name == "" && postman.Item == nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Not a Postman v2 collection", nil)
+	}
+
+	importer := postmanImporter{report: NewImportReport(), ids: make(map[string]bool)}
+	result := &ImportResult{
+		Collection: Collection{
+			ID:          uuid.New().String(),
+			Name:        postman.Info.Name,
+			Description: string(postman.Info.Description),
+		},
+	}
+
+	if len(postman.Event) > 0 {
+		importer.report.Warn("", "Collection scripts are not supported and were not imported")
+	}
+
+	result.Items = importer.importItems(postman.Item, nil, postman.Auth)
+	result.Requests = importer.requests
+	result.Environments = importer.importVariables(postman)
+	result.Report = importer.report
+
+	if err := result.Collection.Validate(); err != nil {
+		return nil, apperrors.NewAppError(http.StatusBadRequest, "Invalid imported collection", err)
+	}
+
+	return result, nil
+}
+
+type postmanImporter struct {
+	report   ImportReport
+	ids      map[string]bool
+	requests []Request
+}
+
+var postmanPathVariable = regexp.MustCompile(`/:([^/?#]+)`)
+
+// postmanRawLanguages maps the languages of raw bodies to their content type, JSON has its own body mode
+var postmanRawLanguages = map[string]string{
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"javascript": "application/javascript",
+}
+
+// importItems imports the requests of a folder level and returns its tree items.
+func (i *postmanImporter) importItems(items []PostmanItem, folder []string, auth *PostmanAuth) []CollectionItem {
+	tree := []CollectionItem{}
+
+	for _, item := range items {
+		name := item.Name
+		if name == "" {
+			name = "Untitled"
+		}
+		path := append(folder[:len(folder):len(folder)], name)
+		itemPath := strings.Join(path, " / ")
+
+		if item.Request == nil {
+			if item.Item == nil {
+				i.report.Skip(itemPath, "Item has neither a request nor children")
+				continue
+			}
+			if len(item.Event) > 0 {
+				i.report.Warn(itemPath, "Folder scripts are not supported and were not imported")
+			}
+
+			folderAuth := auth
+			if item.Auth != nil {
+				folderAuth = item.Auth
+			}
+			tree = append(tree, CollectionItem{Folder: &Folder{
+				ID:          i.newID(item.ID),
+				Name:        name,
+				Description: string(item.Description),
+				Items:       i.importItems(item.Item, path, folderAuth),
+			}})
+			continue
+		}
+
+		request, err := i.convertRequest(itemPath, item, auth)
+		if err != nil {
+			i.report.Skip(itemPath, err.Error())
+			continue
+		}
+		request.Name = name
+		i.requests = append(i.requests, request)
+		tree = append(tree, CollectionItem{RequestID: request.ID})
+		i.report.Imported++
+	}
+
+	return tree
+}
+
+// newID keeps item IDs, so that requests and folders keep their identity across an export and an
+// import, unless they are missing or already taken.
+func (i *postmanImporter) newID(id string) string {
+	if id == "" || i.ids[id] {
+		id = uuid.New().String()
+	}
+	i.ids[id] = true
+	return id
+}
+
+func (i *postmanImporter) convertRequest(name string, item PostmanItem, inherited *PostmanAuth) (Request, error) {
+	source := item.Request
+	extension := item.Pumoide
+	if extension == nil {
+		extension = &PostmanExtension{}
+	}
+
+	method := Method(strings.ToUpper(strings.TrimSpace(source.Method)))
+	if method == "" {
+		method = MethodGet
+	}
+	if !extension.Type.IsHTTP() {
+		// Other request types open their connection with GET, which is all Postman gets
+		method = ""
+	} else if !method.IsValid() {
+		return Request{}, fmt.Errorf("Unsupported HTTP method %s", source.Method)
+	}
+
+	request := Request{
+		ID:          i.newID(item.ID),
+		Name:        name,
+		Type:        extension.Type,
+		Method:      method,
+		URL:         i.convertURL(name, source.URL),
+		QueryParams: i.convertQuery(name, source.URL),
+		BodyConfig:  i.convertBody(name, source.Body),
+		Settings:    item.ProtocolProfileBehavior.toSettings(),
+		GRPC:        extension.GRPC,
+		Messages:    extension.Messages,
+	}
+
+	for _, header := range source.Header {
+		switch {
+		case header.Disabled:
+			i.report.Warn(name, fmt.Sprintf("Disabled header %s was not imported", header.Key))
+		case header.Key == "":
+			i.report.Warn(name, "Header without a name was not imported")
+		default:
+			request.Headers = append(request.Headers, Header{Key: header.Key, Value: string(header.Value)})
+		}
+	}
+
+	auth := inherited
+	if source.Auth != nil && source.Auth.Type != "inherit" {
+		auth = source.Auth
+	}
+	if auth != nil {
+		converted, err := auth.toAuth()
+		if err != nil {
+			i.report.Warn(name, err.Error())
+		} else {
+			request.Auth = converted
+		}
+	}
+
+	if extension.TimeoutMs > 0 {
+		if request.Settings == nil {
+			request.Settings = &RequestSettings{}
+		}
+		request.Settings.TimeoutMs = extension.TimeoutMs
+	}
+
+	if len(item.Event) > 0 {
+		i.report.Warn(name, "Request scripts are not supported and were not imported")
+	}
+	if len(item.Response) > 0 {
+		i.report.Warn(name, fmt.Sprintf("%d saved example responses were not imported", len(item.Response)))
+	}
+
+	if err := request.Validate(); err != nil {
+		message := err.Error()
+		var appErr apperrors.AppError
+		if errors.As(err, &appErr) {
+			message = appErr.Message
+		}
+		return Request{}, fmt.Errorf("Invalid request: %s", message)
+	}
+
+	return request, nil
+}
+
+// convertURL returns the URL without its query, which is imported as query parameters. Declared
+// path variables are replaced by their value, or by a {{variable}} when they have none.
+func (i *postmanImporter) convertURL(name string, source PostmanURL) string {
+	raw := i.convertURLPath(name, source)
+
+	fragment := source.Hash
+	if _, rawFragment, ok := strings.Cut(source.Raw, "#"); ok && fragment == "" {
+		fragment = rawFragment
+	}
+	if fragment != "" {
+		raw += "#" + fragment
+	}
+	return raw
+}
+
+func (i *postmanImporter) convertURLPath(name string, source PostmanURL) string {
+	raw := source.Raw
+	if raw == "" {
+		if source.Protocol != "" {
+			raw = source.Protocol + "://"
+		}
+		raw += strings.Join(source.Host, ".")
+		if source.Port != "" {
+			raw += ":" + source.Port
+		}
+		if len(source.Path) > 0 {
+			raw += "/" + strings.Join(source.Path, "/")
+		}
+	}
+
+	if index := strings.IndexAny(raw, "?#"); index >= 0 {
+		raw = raw[:index]
+	}
+
+	if len(source.Variable) == 0 {
+		return raw
+	}
+
+	values := make(map[string]string, len(source.Variable))
+	for _, variable := range source.Variable {
+		values[variable.name()] = string(variable.Value)
+	}
+
+	return postmanPathVariable.ReplaceAllStringFunc(raw, func(segment string) string {
+		key := segment[2:]
+		value, ok := values[key]
+		if !ok {
+			return segment
+		}
+		if value == "" {
+			i.report.Warn(name, fmt.Sprintf("Path variable %s has no value, it was replaced by {{%s}}", key, key))
+			return "/{{" + key + "}}"
+		}
+		return "/" + value
+	})
+}
+
+func (i *postmanImporter) convertQuery(name string, source PostmanURL) map[string]string {
+	query := source.Query
+	if query == nil {
+		if _, rawQuery, ok := strings.Cut(source.Raw, "?"); ok {
+			rawQuery, _, _ = strings.Cut(rawQuery, "#")
+			query = parsePostmanQuery(rawQuery)
+		}
+	}
+	if len(query) == 0 {
+		return nil
+	}
+
+	params := make(map[string]string, len(query))
+	for _, param := range query {
+		key := unescapeQuery(param.Key)
+		switch {
+		case param.Disabled:
+			i.report.Warn(name, fmt.Sprintf("Disabled query parameter %s was not imported", key))
+		case key == "":
+			i.report.Warn(name, "Query parameter without a name was not imported")
+		default:
+			if _, ok := params[key]; ok {
+				i.report.Warn(name, fmt.Sprintf("Query parameter %s is repeated, only its first value was imported", key))
+				continue
+			}
+			params[key] = unescapeQuery(string(param.Value))
+		}
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+func (i *postmanImporter) convertBody(name string, source *PostmanBody) *RequestBody {
+	if source == nil || source.Disabled {
+		return nil
+	}
+
+	var body *RequestBody
+	switch source.Mode {
+	case "", "raw":
+		if source.Raw == "" {
+			return nil
+		}
+		body = &RequestBody{Mode: BodyRaw, Raw: source.Raw}
+		if source.Options != nil && source.Options.Raw != nil {
+			if source.Options.Raw.Language == "json" {
+				body.Mode = BodyJSON
+			} else {
+				body.ContentType = postmanRawLanguages[source.Options.Raw.Language]
+			}
+		}
+
+	case "urlencoded":
+		body = &RequestBody{Mode: BodyURLEncoded, URLEncoded: []FormField{}}
+		for _, field := range source.URLEncoded {
+			if field.Key == "" {
+				i.report.Warn(name, "Form field without a name was not imported")
+				continue
+			}
+			body.URLEncoded = append(body.URLEncoded, FormField{Key: field.Key, Value: string(field.Value), Disabled: field.Disabled})
+		}
+
+	case "formdata":
+		body = &RequestBody{Mode: BodyFormData, FormData: []FormField{}}
+		for _, field := range source.FormData {
+			if field.Key == "" {
+				i.report.Warn(name, "Form field without a name was not imported")
+				continue
+			}
+
+			formField := FormField{Key: field.Key, ContentType: field.ContentType, Disabled: field.Disabled}
+			if field.Type != "file" {
+				formField.Value = string(field.Value)
+				body.FormData = append(body.FormData, formField)
+				continue
+			}
+
+			if len(field.Src) == 0 || field.Src[0] == "" {
+				i.report.Warn(name, fmt.Sprintf("File field %s has no file and was not imported", field.Key))
+				continue
+			}
+			if len(field.Src) > 1 {
+				i.report.Warn(name, fmt.Sprintf("File field %s has %d files, only the first one was imported", field.Key, len(field.Src)))
+			}
+			formField.Type = FormFieldFile
+			formField.FilePath = field.Src[0]
+			body.FormData = append(body.FormData, formField)
+		}
+
+	case "file":
+		if source.File == nil || source.File.Src == "" {
+			i.report.Warn(name, "File body has no file and was not imported")
+			return nil
+		}
+		body = &RequestBody{Mode: BodyBinary, FilePath: source.File.Src}
+
+	case "graphql":
+		if source.GraphQL == nil {
+			return nil
+		}
+		body = &RequestBody{Mode: BodyGraphQL, GraphQL: &GraphQLBody{
+			Query:         source.GraphQL.Query,
+			Variables:     source.GraphQL.Variables,
+			OperationName: source.GraphQL.OperationName,
+		}}
+
+	default:
+		i.report.Warn(name, fmt.Sprintf("Unsupported body mode %s, the body was not imported", source.Mode))
+		return nil
+	}
+
+	if err := body.Validate(); err != nil {
+		i.report.Warn(name, fmt.Sprintf("Invalid %s body was not imported", source.Mode))
+		return nil
+	}
+	return body
+}
+
+func (i *postmanImporter) importVariables(postman PostmanCollection) []*Environment {
+	variables := make(map[string]string)
+	for _, variable := range postman.Variable {
+		key := variable.name()
+		switch {
+		case key == "":
+			continue
+		case variable.Disabled:
+			i.report.Warn("", fmt.Sprintf("Disabled collection variable %s was not imported", key))
+		default:
+			variables[key] = string(variable.Value)
+		}
+	}
+
+	if len(variables) == 0 {
+		return nil
+	}
+	return []*Environment{{Name: postman.Info.Name, Variables: variables}}
+}
+
+// name returns the variable key, v2.0 collections may only set its ID.
+func (v PostmanVariable) name() string {
+	if v.Key != "" {
+		return v.Key
+	}
+	return v.ID
+}
+
+type postmanAuthMapping struct {
+	postmanType string
+	authType    AuthType
+	// params maps Postman parameter names to Pumoide ones
+	params map[string]string
+}
+
+var postmanAuthMappings = []postmanAuthMapping{
+	{"noauth", AuthNone, map[string]string{}},
+	{"basic", AuthBasic, map[string]string{"username": "username", "password": "password"}},
+	{"bearer", AuthBearer, map[string]string{"token": "token"}},
+	{"apikey", AuthAPIKey, map[string]string{"key": "key", "value": "value", "in": "in"}},
+	{"oauth2", AuthOAuth2, map[string]string{"accessToken": "access_token", "tokenType": "token_type"}},
+	{"awsv4", AuthAWSSigV4, map[string]string{
+		"accessKey":    "access_key",
+		"secretKey":    "secret_key",
+		"sessionToken": "session_token",
+		"region":       "region",
+		"service":      "service",
+	}},
+	{"digest", AuthDigest, map[string]string{
+		"username":    "username",
+		"password":    "password",
+		"realm":       "realm",
+		"nonce":       "nonce",
+		"qop":         "qop",
+		"nonceCount":  "nc",
+		"clientNonce": "cnonce",
+	}},
+}
+
+func (a *PostmanAuth) toAuth() (*Auth, error) {
+	for _, mapping := range postmanAuthMappings {
+		if mapping.postmanType != a.Type {
+			continue
+		}
+
+		auth := &Auth{Type: mapping.authType, Params: make(map[string]string, len(mapping.params))}
+		for postmanName, name := range mapping.params {
+			auth.Params[name] = a.Params[postmanName]
+		}
+		if auth.Type == AuthAPIKey && auth.Params["in"] == "" {
+			auth.Params["in"] = "header"
+		}
+		return auth, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported auth type %s, the request was imported without authentication", a.Type)
+}
+
+func (p *PostmanProtocolProfile) toSettings() *RequestSettings {
+	if p == nil {
+		return nil
+	}
+
+	settings := RequestSettings{
+		FollowRedirects: p.FollowRedirects,
+		MaxRedirects:    p.MaxRedirects,
+		SkipTLSVerify:   p.StrictSSL != nil && !*p.StrictSSL,
+	}
+	// Postman writes "auto" for the default version
+	if version := HTTPVersion(p.ProtocolVersion); version == HTTPVersion1 || version == HTTPVersion2 {
+		settings.HTTPVersion = version
+	}
+	if settings == (RequestSettings{}) {
+		return nil
+	}
+	return &settings
+}
+
+func parsePostmanQuery(rawQuery string) []PostmanKeyValue {
+	var query []PostmanKeyValue
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		query = append(query, PostmanKeyValue{Key: key, Value: PostmanValue(value)})
+	}
+	return query
+}
+
+// unescapeQuery decodes the query parameters of URLs written by hand, where a "+" is taken
+// literally as Postman does.
+func unescapeQuery(value string) string {
+	unescaped, err := url.PathUnescape(value)
+	if err != nil {
+		return value
+	}
+	return unescaped
+}
+
+// ToPostmanCollection exports the collection in the Postman v2.1 format, folders included.
+func (c *Collection) ToPostmanCollection() PostmanCollection {
+	postman := PostmanCollection{
+		Info: PostmanInfo{
+			PostmanID:   c.ID,
+			Name:        c.Name,
+			Description: PostmanDescription(c.Description),
+			Schema:      PostmanSchema,
+		},
+		Item: []PostmanItem{},
+	}
+
+	requests := make(map[string]Request, len(c.Requests))
+	for _, req := range c.Requests {
+		requests[req.ID] = req
+	}
+	postman.Item = newPostmanItems(c.tree(), requests)
+
+	return postman
+}
+
+func newPostmanItems(items []CollectionItem, requests map[string]Request) []PostmanItem {
+	postmanItems := []PostmanItem{}
+	for _, item := range items {
+		if item.Folder != nil {
+			postmanItems = append(postmanItems, PostmanItem{
+				ID:          item.Folder.ID,
+				Name:        item.Folder.Name,
+				Description: PostmanDescription(item.Folder.Description),
+				Item:        newPostmanItems(item.Folder.Items, requests),
+			})
+			continue
+		}
+
+		postmanItems = append(postmanItems, newPostmanItem(requests[item.RequestID]))
+	}
+	return postmanItems
+}
+
+func newPostmanItem(req Request) PostmanItem {
+	method := string(req.Method)
+	if method == "" {
+		method = string(MethodGet)
+	}
+
+	item := PostmanItem{
+		ID:   req.ID,
+		Name: req.Name,
+		Request: &PostmanRequest{
+			Method: method,
+			URL:    newPostmanURL(req.URL, req.QueryParams),
+			Body:   newPostmanBody(req),
+			Auth:   newPostmanAuth(req.Auth),
+		},
+		ProtocolProfileBehavior: newPostmanProtocolProfile(req.Settings),
+	}
+	for _, header := range req.Headers {
+		item.Request.Header = append(item.Request.Header, PostmanKeyValue{Key: header.Key, Value: PostmanValue(header.Value)})
+	}
+
+	item.Pumoide = newPostmanExtension(req)
+	return item
+}
+
+func newPostmanExtension(req Request) *PostmanExtension {
+	extension := PostmanExtension{Type: req.Type, GRPC: req.GRPC, Messages: req.Messages}
+	if req.Settings != nil {
+		extension.TimeoutMs = req.Settings.TimeoutMs
+	}
+
+	if extension.Type == "" && extension.GRPC == nil && extension.Messages == nil && extension.TimeoutMs == 0 {
+		return nil
+	}
+	return &extension
+}
+
+func newPostmanURL(rawURL string, params map[string]string) PostmanURL {
+	base, fragment, hasFragment := strings.Cut(rawURL, "#")
+	base, rawQuery, _ := strings.Cut(base, "?")
+	postman := PostmanURL{Raw: base, Query: parsePostmanQuery(rawQuery), Hash: fragment}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var query []string
+	if rawQuery != "" {
+		query = append(query, rawQuery)
+	}
+	for _, key := range keys {
+		param := PostmanKeyValue{Key: postmanQueryEscaper.Replace(key), Value: PostmanValue(postmanQueryEscaper.Replace(params[key]))}
+		postman.Query = append(postman.Query, param)
+		query = append(query, param.Key+"="+string(param.Value))
+	}
+	if len(query) > 0 {
+		postman.Raw += "?" + strings.Join(query, "&")
+	}
+	if hasFragment {
+		postman.Raw += "#" + fragment
+	}
+
+	if protocol, rest, ok := strings.Cut(base, "://"); ok {
+		postman.Protocol = protocol
+		base = rest
+	}
+	host, path, hasPath := strings.Cut(base, "/")
+	if index := strings.LastIndex(host, ":"); index >= 0 && isPort(host[index+1:]) {
+		host, postman.Port = host[:index], host[index+1:]
+	}
+	if host != "" {
+		postman.Host = strings.Split(host, ".")
+	}
+	if hasPath {
+		postman.Path = strings.Split(path, "/")
+	}
+
+	return postman
+}
+
+// postmanQueryEscaper escapes what would change the meaning of a query, leaving {{variables}} readable
+var postmanQueryEscaper = strings.NewReplacer("%", "%25", "+", "%2B", "&", "%26", "=", "%3D", "#", "%23")
+
+func isPort(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func newPostmanBody(req Request) *PostmanBody {
+	config := req.BodyConfig
+	if config == nil {
+		if req.Body == "" {
+			return nil
+		}
+		return &PostmanBody{Mode: "raw", Raw: req.Body}
+	}
+
+	switch config.Mode {
+	case BodyRaw, BodyJSON:
+		language := "text"
+		if config.Mode == BodyJSON {
+			language = "json"
+		}
+		for name, contentType := range postmanRawLanguages {
+			if config.ContentType == contentType {
+				language = name
+			}
+		}
+		return &PostmanBody{Mode: "raw", Raw: config.Raw, Options: &PostmanBodyOptions{Raw: &PostmanRawOptions{Language: language}}}
+
+	case BodyURLEncoded:
+		body := &PostmanBody{Mode: "urlencoded", URLEncoded: []PostmanFormField{}}
+		for _, field := range config.URLEncoded {
+			body.URLEncoded = append(body.URLEncoded, PostmanFormField{
+				Key:      field.Key,
+				Value:    PostmanValue(field.Value),
+				Type:     "text",
+				Disabled: field.Disabled,
+			})
+		}
+		return body
+
+	case BodyFormData:
+		body := &PostmanBody{Mode: "formdata", FormData: []PostmanFormField{}}
+		for _, field := range config.FormData {
+			formField := PostmanFormField{Key: field.Key, Type: "text", ContentType: field.ContentType, Disabled: field.Disabled}
+			if field.Type == FormFieldFile {
+				formField.Type = "file"
+				formField.Src = PostmanStrings{field.FilePath}
+			} else {
+				formField.Value = PostmanValue(field.Value)
+			}
+			body.FormData = append(body.FormData, formField)
+		}
+		return body
+
+	case BodyBinary:
+		return &PostmanBody{Mode: "file", File: &PostmanFile{Src: config.FilePath}}
+
+	case BodyGraphQL:
+		if config.GraphQL == nil {
+			return nil
+		}
+		return &PostmanBody{Mode: "graphql", GraphQL: &PostmanGraphQL{
+			Query:         config.GraphQL.Query,
+			Variables:     config.GraphQL.Variables,
+			OperationName: config.GraphQL.OperationName,
+		}}
+	}
+
+	return nil
+}
+
+func newPostmanAuth(auth *Auth) *PostmanAuth {
+	if auth == nil {
+		return nil
+	}
+
+	for _, mapping := range postmanAuthMappings {
+		if mapping.authType != auth.Type {
+			continue
+		}
+
+		postman := &PostmanAuth{Type: mapping.postmanType, Params: make(map[string]string, len(mapping.params))}
+		for postmanName, name := range mapping.params {
+			if value, ok := auth.Params[name]; ok {
+				postman.Params[postmanName] = value
+			}
+		}
+		return postman
+	}
+
+	return nil
+}
+
+func newPostmanProtocolProfile(settings *RequestSettings) *PostmanProtocolProfile {
+	if settings == nil {
+		return nil
+	}
+
+	profile := PostmanProtocolProfile{
+		FollowRedirects: settings.FollowRedirects,
+		MaxRedirects:    settings.MaxRedirects,
+		ProtocolVersion: string(settings.HTTPVersion),
+	}
+	if settings.SkipTLSVerify {
+		strictSSL := false
+		profile.StrictSSL = &strictSSL
+	}
+	if profile == (PostmanProtocolProfile{}) {
+		return nil
+	}
+	return &profile
+}
+
+// MarshalJSON writes the parameters in the v2.1 list form.
+func (a PostmanAuth) MarshalJSON() ([]byte, error) {
+	fields := map[string]interface{}{"type": a.Type}
+
+	if a.Type != "noauth" {
+		keys := make([]string, 0, len(a.Params))
+		for key := range a.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		params := make([]PostmanVariable, 0, len(keys))
+		for _, key := range keys {
+			params = append(params, PostmanVariable{Key: key, Value: PostmanValue(a.Params[key]), Type: "string"})
+		}
+		fields[a.Type] = params
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+)
+
+const proxySettingsFile = "proxy.json"
+
+// ProxySettings routes requests through an HTTP, HTTPS or SOCKS5 proxy.
+// An empty URL means requests go out directly.
+type ProxySettings struct {
+	URL      string   `json:"url"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	NoProxy  []string `json:"noProxy,omitempty"`
+}
+
+func (p *ProxySettings) Save(path string) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, proxySettingsFile), data, 0600)
+}
+
+// LoadProxySettings returns the global proxy settings, or nil when none were saved.
+func LoadProxySettings(path string) (*ProxySettings, error) {
+	data, err := os.ReadFile(filepath.Join(path, proxySettingsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var settings ProxySettings
+	err = json.Unmarshal(data, &settings)
+	return &settings, err
+}
+
+func DeleteProxySettings(path string) error {
+	err := os.Remove(filepath.Join(path, proxySettingsFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (p *ProxySettings) Validate() error {
+	if p.URL == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Invalid proxy URL: %s", p.URL), err)
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported proxy scheme: %s", proxyURL.Scheme), nil)
+	}
+
+	if proxyURL.Host == "" {
+		return apperrors.NewAppError(http.StatusBadRequest, "Proxy URL requires a host", nil)
+	}
+
+	return nil
+}
+
+// ProxyURL returns the proxy to use for target, or nil when it must be reached directly.
+func (p *ProxySettings) ProxyURL(target *url.URL) (*url.URL, error) {
+	if p == nil || p.URL == "" || p.Bypasses(target.Hostname()) {
+		return nil, nil
+	}
+
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Username != "" {
+		proxyURL.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	return proxyURL, nil
+}
+
+// Bypasses reports whether host matches the no-proxy list. Entries can be "*", a domain
+// (matching itself and its subdomains, with an optional leading "." or "*."), an IP or a CIDR range.
+func (p *ProxySettings) Bypasses(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+
+	for _, entry := range p.NoProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+
+		if entry == "*" {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		domain := strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
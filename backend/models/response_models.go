@@ -0,0 +1,164 @@
+package models
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type BodyEncoding string
+
+const (
+	EncodingText   BodyEncoding = "text"
+	EncodingBase64 BodyEncoding = "base64"
+)
+
+// ResponseTimings holds the duration of each phase of an executed request, in milliseconds.
+type ResponseTimings struct {
+	DNSLookup       float64 `json:"dnsLookup"`
+	TCPConnection   float64 `json:"tcpConnection"`
+	TLSHandshake    float64 `json:"tlsHandshake"`
+	TimeToFirstByte float64 `json:"timeToFirstByte"`
+	ContentTransfer float64 `json:"contentTransfer"`
+	Total           float64 `json:"total"`
+}
+
+type Cookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain,omitempty"`
+	Path     string     `json:"path,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	MaxAge   int        `json:"maxAge,omitempty"`
+	Secure   bool       `json:"secure"`
+	HttpOnly bool       `json:"httpOnly"`
+	SameSite string     `json:"sameSite,omitempty"`
+	HostOnly bool       `json:"hostOnly,omitempty"`
+}
+
+type Redirect struct {
+	StatusCode int    `json:"statusCode"`
+	URL        string `json:"url"`
+	Location   string `json:"location"`
+}
+
+type ExecutionResponse struct {
+	ExecutionID string          `json:"executionId"`
+	StatusCode  int             `json:"statusCode"`
+	Headers     []Header        `json:"headers"`
+	Cookies     []Cookie        `json:"cookies"`
+	Body        string          `json:"body"`
+	Encoding    BodyEncoding    `json:"encoding"`
+	Truncated   bool            `json:"truncated,omitempty"`
+	SavedTo     string          `json:"savedTo,omitempty"`
+	Redirects   []Redirect      `json:"redirects,omitempty"`
+	Timings     ResponseTimings `json:"timings"`
+	RemoteAddr  string          `json:"remoteAddr"`
+	Protocol    string          `json:"protocol"`
+	Size        int64           `json:"size"`
+}
+
+// NewResponseHeaders flattens a header map into a list sorted by key, keeping every value of repeated headers.
+func NewResponseHeaders(header http.Header) []Header {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	headers := make([]Header, 0, len(header))
+	for _, key := range keys {
+		for _, value := range header[key] {
+			headers = append(headers, Header{Key: key, Value: value})
+		}
+	}
+	return headers
+}
+
+func NewCookie(c *http.Cookie) Cookie {
+	cookie := Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		MaxAge:   c.MaxAge,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+
+	if !c.Expires.IsZero() {
+		expires := c.Expires
+		cookie.Expires = &expires
+	}
+
+	switch c.SameSite {
+	case http.SameSiteLaxMode:
+		cookie.SameSite = "Lax"
+	case http.SameSiteStrictMode:
+		cookie.SameSite = "Strict"
+	case http.SameSiteNoneMode:
+		cookie.SameSite = "None"
+	}
+
+	return cookie
+}
+
+func NewResponseCookies(cookies []*http.Cookie) []Cookie {
+	result := make([]Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		result = append(result, NewCookie(c))
+	}
+	return result
+}
+
+func IsTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/json",
+		"application/xml",
+		"application/javascript",
+		"application/ecmascript",
+		"application/x-www-form-urlencoded",
+		"application/graphql",
+		"application/yaml",
+		"application/x-yaml",
+		"application/x-ndjson":
+		return true
+	}
+
+	return false
+}
+
+// EncodeBody returns the body as plain text when it is textual and valid UTF-8, and base64-encoded otherwise.
+func EncodeBody(contentType string, body []byte) (string, BodyEncoding) {
+	if contentType == "" && len(body) > 0 {
+		contentType = http.DetectContentType(body)
+	}
+
+	if (len(body) == 0 || IsTextContentType(contentType)) && utf8.Valid(body) {
+		return string(body), EncodingText
+	}
+
+	return base64.StdEncoding.EncodeToString(body), EncodingBase64
+}
+
+func DecodeBody(body string, encoding BodyEncoding) ([]byte, error) {
+	if encoding == EncodingBase64 {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
@@ -0,0 +1,663 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+)
+
+type SnippetLanguage string
+
+const (
+	SnippetCurl       SnippetLanguage = "curl"
+	SnippetGo         SnippetLanguage = "go"
+	SnippetPython     SnippetLanguage = "python"
+	SnippetJavaScript SnippetLanguage = "javascript"
+	SnippetNode       SnippetLanguage = "node"
+	SnippetHTTPie     SnippetLanguage = "httpie"
+)
+
+func GetValidSnippetLanguages() []SnippetLanguage {
+	return []SnippetLanguage{SnippetCurl, SnippetGo, SnippetPython, SnippetJavaScript, SnippetNode, SnippetHTTPie}
+}
+
+func (l SnippetLanguage) IsValid() bool {
+	for _, validLanguage := range GetValidSnippetLanguages() {
+		if l == validLanguage {
+			return true
+		}
+	}
+	return false
+}
+
+// ToSnippet renders the request as client code, with the variables of the environment resolved
+// and the auth applied. JavaScript uses fetch and Node axios, both as ES modules.
+func (r *Request) ToSnippet(language SnippetLanguage, env *Environment) (string, error) {
+	if !r.Type.IsHTTP() {
+		return "", apperrors.NewAppError(http.StatusBadRequest, "Only HTTP requests can be rendered as code", fmt.Errorf("%s", r.Type))
+	}
+
+	switch language {
+	case SnippetCurl:
+		return r.ToCurlCommand(env) + "\n", nil
+	case SnippetGo:
+		return goSnippet(r.resolve(env)), nil
+	case SnippetPython:
+		return pythonSnippet(r.resolve(env)), nil
+	case SnippetJavaScript:
+		return fetchSnippet(r.resolve(env)), nil
+	case SnippetNode:
+		return axiosSnippet(r.resolve(env)), nil
+	case SnippetHTTPie:
+		return httpieSnippet(r.resolve(env)), nil
+	}
+	return "", apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported snippet language: %s", language), nil)
+}
+
+// resolvedRequest is a request with the environment applied, as exporters render it. Text bodies
+// are BodyRaw, with their Content-Type in the headers, and the auth only holds the types that
+// can't be sent as a header or a query parameter.
+type resolvedRequest struct {
+	Method   Method
+	URL      string
+	Headers  []Header
+	Body     resolvedBody
+	Auth     *Auth
+	Settings *RequestSettings
+}
+
+type resolvedBody struct {
+	Mode BodyMode
+	Text string
+	// Fields are the enabled fields of form bodies
+	Fields   []FormField
+	FilePath string
+}
+
+func (r *Request) resolve(env *Environment) resolvedRequest {
+	resolved := resolvedRequest{
+		Method:   r.Method,
+		Body:     resolvedBody{Mode: BodyNone},
+		Settings: r.Settings,
+	}
+	if resolved.Method == "" {
+		resolved.Method = MethodGet
+	}
+	if resolved.Settings == nil {
+		resolved.Settings = &RequestSettings{}
+	}
+
+	// The URL isn't parsed, unresolved variables would be escaped
+	target, rawQuery, _ := strings.Cut(env.Resolve(r.URL), "?")
+	target, _, _ = strings.Cut(target, "#")
+	query, _ := url.ParseQuery(rawQuery)
+	keys := make([]string, 0, len(r.QueryParams))
+	for key := range r.QueryParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		query.Add(key, env.Resolve(r.QueryParams[key]))
+	}
+
+	for _, header := range r.Headers {
+		resolved.Headers = append(resolved.Headers, Header{Key: header.Key, Value: env.Resolve(header.Value)})
+	}
+
+	contentType := ""
+	if config := r.BodyConfig; config == nil {
+		if r.Body != "" {
+			resolved.Body = resolvedBody{Mode: BodyRaw, Text: env.Resolve(r.Body)}
+		}
+	} else {
+		switch config.Mode {
+		case BodyRaw:
+			contentType = defaultString(config.ContentType, "text/plain")
+			resolved.Body = resolvedBody{Mode: BodyRaw, Text: env.Resolve(config.Raw)}
+		case BodyJSON:
+			contentType = defaultString(config.ContentType, "application/json")
+			resolved.Body = resolvedBody{Mode: BodyRaw, Text: env.Resolve(config.Raw)}
+		case BodyURLEncoded, BodyFormData:
+			fields := config.URLEncoded
+			if config.Mode == BodyFormData {
+				fields = config.FormData
+			}
+			body := resolvedBody{Mode: config.Mode}
+			for _, field := range fields {
+				if field.Disabled {
+					continue
+				}
+				field.Key, field.Value, field.FilePath = env.Resolve(field.Key), env.Resolve(field.Value), env.Resolve(field.FilePath)
+				body.Fields = append(body.Fields, field)
+			}
+			if len(body.Fields) > 0 {
+				resolved.Body = body
+			}
+		case BodyBinary:
+			contentType = config.ContentType
+			resolved.Body = resolvedBody{Mode: BodyBinary, FilePath: env.Resolve(config.FilePath)}
+		case BodyGraphQL:
+			if config.GraphQL != nil {
+				payload := GraphQLPayload{Query: env.Resolve(config.GraphQL.Query), OperationName: env.Resolve(config.GraphQL.OperationName)}
+				if variables := strings.TrimSpace(env.Resolve(config.GraphQL.Variables)); variables != "" && json.Valid([]byte(variables)) {
+					payload.Variables = json.RawMessage(variables)
+				}
+				// GraphQL over GET carries the operation in the query string
+				if resolved.Method == MethodGet {
+					query.Set("query", payload.Query)
+					if payload.Variables != nil {
+						query.Set("variables", string(payload.Variables))
+					}
+					if payload.OperationName != "" {
+						query.Set("operationName", payload.OperationName)
+					}
+				} else if data, err := json.Marshal(payload); err == nil {
+					contentType = "application/json"
+					resolved.Body = resolvedBody{Mode: BodyRaw, Text: string(data)}
+				}
+			}
+		}
+	}
+	if contentType != "" && headerValue(r.Headers, "Content-Type") == "" {
+		resolved.Headers = append(resolved.Headers, Header{Key: "Content-Type", Value: contentType})
+	}
+
+	if auth := r.Auth; auth != nil {
+		params := make(map[string]string, len(auth.Params))
+		for key, value := range auth.Params {
+			params[key] = env.Resolve(value)
+		}
+		switch auth.Type {
+		case AuthBasic, AuthDigest:
+			resolved.Auth = &Auth{Type: auth.Type, Params: params}
+		case AuthAWSSigV4:
+			resolved.Auth = &Auth{Type: auth.Type, Params: params}
+			if params["session_token"] != "" {
+				resolved.Headers = append(resolved.Headers, Header{Key: "X-Amz-Security-Token", Value: params["session_token"]})
+			}
+		case AuthBearer:
+			resolved.Headers = append(resolved.Headers, Header{Key: "Authorization", Value: "Bearer " + params["token"]})
+		case AuthOAuth2:
+			resolved.Headers = append(resolved.Headers, Header{Key: "Authorization", Value: "Bearer " + params["access_token"]})
+		case AuthAPIKey:
+			if params["in"] == "query" {
+				query.Add(params["key"], params["value"])
+			} else {
+				resolved.Headers = append(resolved.Headers, Header{Key: params["key"], Value: params["value"]})
+			}
+		}
+	}
+
+	resolved.URL = target
+	if len(query) > 0 {
+		resolved.URL += "?" + encodeQuery(query)
+	}
+	return resolved
+}
+
+// encodeQuery encodes the query like url.Values.Encode, leaving the variables that weren't
+// resolved readable.
+func encodeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			params = append(params, queryEscape(key)+"="+queryEscape(value))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func queryEscape(value string) string {
+	var escaped strings.Builder
+	last := 0
+	for _, match := range variablePattern.FindAllStringIndex(value, -1) {
+		escaped.WriteString(url.QueryEscape(value[last:match[0]]))
+		escaped.WriteString(value[match[0]:match[1]])
+		last = match[1]
+	}
+	escaped.WriteString(url.QueryEscape(value[last:]))
+	return escaped.String()
+}
+
+// basicAuthorization is the Authorization header of basic auth, for clients without support for it.
+func (r resolvedRequest) basicAuthorization() string {
+	credentials := r.Auth.Params["username"] + ":" + r.Auth.Params["password"]
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+// unsupportedAuth describes the auth a client can't apply, empty when there is none.
+func (r resolvedRequest) unsupportedAuth(digest bool) string {
+	if r.Auth == nil {
+		return ""
+	}
+	switch {
+	case r.Auth.Type == AuthDigest && !digest:
+		return "Digest auth is not applied, answer the server challenge as " + r.Auth.Params["username"]
+	case r.Auth.Type == AuthAWSSigV4:
+		return fmt.Sprintf("Sign the request with AWS Signature Version 4 for %s in %s", r.Auth.Params["service"], r.Auth.Params["region"])
+	}
+	return ""
+}
+
+func formatSeconds(milliseconds int) string {
+	return strconv.FormatFloat(float64(milliseconds)/1000, 'f', -1, 64)
+}
+
+// goString quotes a string literal, with backquotes when it reads better.
+func goString(value string) string {
+	if strings.ContainsAny(value, "\"\n") && !strings.ContainsAny(value, "`\r") {
+		return "`" + value + "`"
+	}
+	return strconv.Quote(value)
+}
+
+func goSnippet(r resolvedRequest) string {
+	imports := map[string]bool{"fmt": true, "io": true, "net/http": true}
+	var body, request, client strings.Builder
+
+	reader := "nil"
+	switch r.Body.Mode {
+	case BodyRaw:
+		imports["strings"] = true
+		fmt.Fprintf(&body, "\tbody := strings.NewReader(%s)\n\n", goString(r.Body.Text))
+		reader = "body"
+	case BodyURLEncoded:
+		imports["net/url"], imports["strings"] = true, true
+		body.WriteString("\tform := url.Values{}\n")
+		for _, field := range r.Body.Fields {
+			fmt.Fprintf(&body, "\tform.Add(%s, %s)\n", strconv.Quote(field.Key), strconv.Quote(field.Value))
+		}
+		body.WriteString("\tbody := strings.NewReader(form.Encode())\n\n")
+		reader = "body"
+	case BodyFormData:
+		imports["bytes"], imports["mime/multipart"] = true, true
+		body.WriteString("\tvar body bytes.Buffer\n\twriter := multipart.NewWriter(&body)\n")
+		assign := ":="
+		for _, field := range r.Body.Fields {
+			if field.Type != FormFieldFile {
+				fmt.Fprintf(&body, "\tif err := writer.WriteField(%s, %s); err != nil {\n\t\tpanic(err)\n\t}\n", strconv.Quote(field.Key), strconv.Quote(field.Value))
+				continue
+			}
+			imports["os"] = true
+			fmt.Fprintf(&body, "\tfile, err %s os.ReadFile(%s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n", assign, strconv.Quote(field.FilePath))
+			fmt.Fprintf(&body, "\tpart, err %s writer.CreateFormFile(%s, %s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n", assign, strconv.Quote(field.Key), strconv.Quote(filepath.Base(field.FilePath)))
+			body.WriteString("\tif _, err := part.Write(file); err != nil {\n\t\tpanic(err)\n\t}\n")
+			assign = "="
+		}
+		body.WriteString("\tif err := writer.Close(); err != nil {\n\t\tpanic(err)\n\t}\n\n")
+		reader = "&body"
+	case BodyBinary:
+		imports["os"] = true
+		fmt.Fprintf(&body, "\tbody, err := os.Open(%s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tdefer body.Close()\n\n", strconv.Quote(r.Body.FilePath))
+		reader = "body"
+	}
+
+	fmt.Fprintf(&request, "\treq, err := http.NewRequest(%s, %s, %s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n", strconv.Quote(string(r.Method)), strconv.Quote(r.URL), reader)
+	for _, header := range r.Headers {
+		fmt.Fprintf(&request, "\treq.Header.Add(%s, %s)\n", strconv.Quote(header.Key), strconv.Quote(header.Value))
+	}
+	switch r.Body.Mode {
+	case BodyURLEncoded:
+		request.WriteString("\treq.Header.Set(\"Content-Type\", \"application/x-www-form-urlencoded\")\n")
+	case BodyFormData:
+		request.WriteString("\treq.Header.Set(\"Content-Type\", writer.FormDataContentType())\n")
+	}
+	if r.Auth != nil && r.Auth.Type == AuthBasic {
+		fmt.Fprintf(&request, "\treq.SetBasicAuth(%s, %s)\n", strconv.Quote(r.Auth.Params["username"]), strconv.Quote(r.Auth.Params["password"]))
+	}
+	if note := r.unsupportedAuth(false); note != "" {
+		fmt.Fprintf(&request, "\t// %s\n", note)
+	}
+
+	settings := r.Settings
+	client.WriteString("\tclient := &http.Client{")
+	var fields []string
+	if settings.TimeoutMs > 0 {
+		imports["time"] = true
+		fields = append(fields, fmt.Sprintf("\t\tTimeout: %d * time.Millisecond,\n", settings.TimeoutMs))
+	}
+	if settings.SkipTLSVerify {
+		imports["crypto/tls"] = true
+		fields = append(fields, "\t\tTransport: &http.Transport{\n\t\t\tTLSClientConfig: &tls.Config{InsecureSkipVerify: true},\n\t\t},\n")
+	}
+	if !settings.ShouldFollowRedirects() {
+		fields = append(fields, "\t\tCheckRedirect: func(req *http.Request, via []*http.Request) error {\n\t\t\treturn http.ErrUseLastResponse\n\t\t},\n")
+	}
+	if len(fields) > 0 {
+		client.WriteString("\n" + strings.Join(fields, "") + "\t")
+	}
+	client.WriteString("}\n")
+
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var snippet strings.Builder
+	snippet.WriteString("package main\n\nimport (\n")
+	for _, name := range names {
+		fmt.Fprintf(&snippet, "\t%q\n", name)
+	}
+	snippet.WriteString(")\n\nfunc main() {\n")
+	snippet.WriteString(body.String())
+	snippet.WriteString(request.String())
+	snippet.WriteString("\n")
+	snippet.WriteString(client.String())
+	snippet.WriteString("\tresp, err := client.Do(req)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tdefer resp.Body.Close()\n\n")
+	snippet.WriteString("\tdata, err := io.ReadAll(resp.Body)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	snippet.WriteString("\tfmt.Println(resp.Status)\n\tfmt.Println(string(data))\n}\n")
+	return snippet.String()
+}
+
+// jsString quotes a string literal for Python and JavaScript, which both read JSON strings.
+func jsString(value string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func pythonSnippet(r resolvedRequest) string {
+	var snippet strings.Builder
+	snippet.WriteString("import requests\n")
+	if r.Auth != nil && r.Auth.Type == AuthDigest {
+		snippet.WriteString("from requests.auth import HTTPDigestAuth\n")
+	}
+	fmt.Fprintf(&snippet, "\nurl = %s\n", jsString(r.URL))
+
+	arguments := []string{jsString(string(r.Method)), "url"}
+	if len(r.Headers) > 0 {
+		snippet.WriteString("headers = {\n")
+		for _, header := range r.Headers {
+			fmt.Fprintf(&snippet, "    %s: %s,\n", jsString(header.Key), jsString(header.Value))
+		}
+		snippet.WriteString("}\n")
+		arguments = append(arguments, "headers=headers")
+	}
+
+	switch r.Body.Mode {
+	case BodyRaw:
+		fmt.Fprintf(&snippet, "data = %s\n", jsString(r.Body.Text))
+		arguments = append(arguments, "data=data")
+	case BodyURLEncoded, BodyFormData:
+		var data, files []string
+		for _, field := range r.Body.Fields {
+			if field.Type == FormFieldFile {
+				file := fmt.Sprintf("(%s, open(%s, \"rb\")", jsString(filepath.Base(field.FilePath)), jsString(field.FilePath))
+				if field.ContentType != "" {
+					file += ", " + jsString(field.ContentType)
+				}
+				files = append(files, fmt.Sprintf("    (%s, %s)),\n", jsString(field.Key), file))
+			} else {
+				data = append(data, fmt.Sprintf("    (%s, %s),\n", jsString(field.Key), jsString(field.Value)))
+			}
+		}
+		if len(data) > 0 {
+			snippet.WriteString("data = [\n" + strings.Join(data, "") + "]\n")
+			arguments = append(arguments, "data=data")
+		}
+		if len(files) > 0 {
+			snippet.WriteString("files = [\n" + strings.Join(files, "") + "]\n")
+			arguments = append(arguments, "files=files")
+		}
+	case BodyBinary:
+		fmt.Fprintf(&snippet, "data = open(%s, \"rb\")\n", jsString(r.Body.FilePath))
+		arguments = append(arguments, "data=data")
+	}
+
+	if r.Auth != nil {
+		credentials := jsString(r.Auth.Params["username"]) + ", " + jsString(r.Auth.Params["password"])
+		switch r.Auth.Type {
+		case AuthBasic:
+			arguments = append(arguments, "auth=("+credentials+")")
+		case AuthDigest:
+			arguments = append(arguments, "auth=HTTPDigestAuth("+credentials+")")
+		}
+	}
+	if note := r.unsupportedAuth(true); note != "" {
+		fmt.Fprintf(&snippet, "# %s\n", note)
+	}
+
+	settings := r.Settings
+	if settings.TimeoutMs > 0 {
+		arguments = append(arguments, "timeout="+formatSeconds(settings.TimeoutMs))
+	}
+	if settings.SkipTLSVerify {
+		arguments = append(arguments, "verify=False")
+	}
+	if !settings.ShouldFollowRedirects() {
+		arguments = append(arguments, "allow_redirects=False")
+	}
+
+	fmt.Fprintf(&snippet, "\nresponse = requests.request(%s)\n", strings.Join(arguments, ", "))
+	snippet.WriteString("print(response.status_code)\nprint(response.text)\n")
+	return snippet.String()
+}
+
+// jsBody declares the body of fetch and axios snippets, which both accept the same types.
+func jsBody(snippet *strings.Builder, r resolvedRequest) string {
+	switch r.Body.Mode {
+	case BodyRaw:
+		return jsString(r.Body.Text)
+	case BodyURLEncoded:
+		snippet.WriteString("const body = new URLSearchParams([\n")
+		for _, field := range r.Body.Fields {
+			fmt.Fprintf(snippet, "  [%s, %s],\n", jsString(field.Key), jsString(field.Value))
+		}
+		snippet.WriteString("]);\n\n")
+		return "body"
+	case BodyFormData:
+		snippet.WriteString("const body = new FormData();\n")
+		for _, field := range r.Body.Fields {
+			if field.Type != FormFieldFile {
+				fmt.Fprintf(snippet, "body.append(%s, %s);\n", jsString(field.Key), jsString(field.Value))
+				continue
+			}
+			blobOptions := ""
+			if field.ContentType != "" {
+				blobOptions = fmt.Sprintf(", { type: %s }", jsString(field.ContentType))
+			}
+			fmt.Fprintf(snippet, "body.append(%s, new Blob([await readFile(%s)]%s), %s);\n",
+				jsString(field.Key), jsString(field.FilePath), blobOptions, jsString(filepath.Base(field.FilePath)))
+		}
+		snippet.WriteString("\n")
+		return "body"
+	case BodyBinary:
+		return fmt.Sprintf("await readFile(%s)", jsString(r.Body.FilePath))
+	}
+	return ""
+}
+
+// jsImports lists the imports needed by the body, they must come first in ES modules.
+func jsImports(r resolvedRequest) []string {
+	usesFiles := r.Body.Mode == BodyBinary
+	for _, field := range r.Body.Fields {
+		usesFiles = usesFiles || field.Type == FormFieldFile
+	}
+	if usesFiles {
+		return []string{`import { readFile } from "node:fs/promises";`}
+	}
+	return nil
+}
+
+func fetchSnippet(r resolvedRequest) string {
+	var snippet, body strings.Builder
+	imports := jsImports(r)
+	value := jsBody(&body, r)
+
+	headers := r.Headers
+	if r.Auth != nil && r.Auth.Type == AuthBasic {
+		headers = append(headers, Header{Key: "Authorization", Value: r.basicAuthorization()})
+	}
+
+	if len(imports) > 0 {
+		snippet.WriteString(strings.Join(imports, "\n") + "\n\n")
+	}
+	snippet.WriteString(body.String())
+	if note := r.unsupportedAuth(false); note != "" {
+		fmt.Fprintf(&snippet, "// %s\n", note)
+	}
+	if r.Settings.SkipTLSVerify {
+		snippet.WriteString("// fetch can't skip the TLS certificate verification\n")
+	}
+
+	fmt.Fprintf(&snippet, "const response = await fetch(%s, {\n", jsString(r.URL))
+	fmt.Fprintf(&snippet, "  method: %s,\n", jsString(string(r.Method)))
+	if len(headers) > 0 {
+		snippet.WriteString("  headers: {\n")
+		for _, header := range headers {
+			fmt.Fprintf(&snippet, "    %s: %s,\n", jsString(header.Key), jsString(header.Value))
+		}
+		snippet.WriteString("  },\n")
+	}
+	if value != "" {
+		fmt.Fprintf(&snippet, "  body: %s,\n", value)
+	}
+	if r.Settings.TimeoutMs > 0 {
+		fmt.Fprintf(&snippet, "  signal: AbortSignal.timeout(%d),\n", r.Settings.TimeoutMs)
+	}
+	if !r.Settings.ShouldFollowRedirects() {
+		snippet.WriteString("  redirect: \"manual\",\n")
+	}
+	snippet.WriteString("});\n\nconsole.log(response.status);\nconsole.log(await response.text());\n")
+	return snippet.String()
+}
+
+func axiosSnippet(r resolvedRequest) string {
+	var snippet, body strings.Builder
+	imports := append([]string{`import axios from "axios";`}, jsImports(r)...)
+	if r.Settings.SkipTLSVerify {
+		imports = append(imports, `import https from "node:https";`)
+	}
+	value := jsBody(&body, r)
+
+	snippet.WriteString(strings.Join(imports, "\n") + "\n\n")
+	snippet.WriteString(body.String())
+	if note := r.unsupportedAuth(false); note != "" {
+		fmt.Fprintf(&snippet, "// %s\n", note)
+	}
+
+	snippet.WriteString("const response = await axios.request({\n")
+	fmt.Fprintf(&snippet, "  method: %s,\n", jsString(strings.ToLower(string(r.Method))))
+	fmt.Fprintf(&snippet, "  url: %s,\n", jsString(r.URL))
+	if len(r.Headers) > 0 {
+		snippet.WriteString("  headers: {\n")
+		for _, header := range r.Headers {
+			fmt.Fprintf(&snippet, "    %s: %s,\n", jsString(header.Key), jsString(header.Value))
+		}
+		snippet.WriteString("  },\n")
+	}
+	if value != "" {
+		fmt.Fprintf(&snippet, "  data: %s,\n", value)
+	}
+	if r.Auth != nil && r.Auth.Type == AuthBasic {
+		fmt.Fprintf(&snippet, "  auth: { username: %s, password: %s },\n", jsString(r.Auth.Params["username"]), jsString(r.Auth.Params["password"]))
+	}
+	if r.Settings.TimeoutMs > 0 {
+		fmt.Fprintf(&snippet, "  timeout: %d,\n", r.Settings.TimeoutMs)
+	}
+	if !r.Settings.ShouldFollowRedirects() {
+		snippet.WriteString("  maxRedirects: 0,\n")
+	} else if r.Settings.MaxRedirects != nil {
+		fmt.Fprintf(&snippet, "  maxRedirects: %d,\n", *r.Settings.MaxRedirects)
+	}
+	if r.Settings.SkipTLSVerify {
+		snippet.WriteString("  httpsAgent: new https.Agent({ rejectUnauthorized: false }),\n")
+	}
+	// Like the other clients, error statuses are printed instead of thrown
+	snippet.WriteString("  validateStatus: () => true,\n")
+	snippet.WriteString("});\n\nconsole.log(response.status);\nconsole.log(response.data);\n")
+	return snippet.String()
+}
+
+func httpieSnippet(r resolvedRequest) string {
+	lines := []string{"http"}
+
+	settings := r.Settings
+	if settings.ShouldFollowRedirects() {
+		lines[0] += " --follow"
+		if settings.MaxRedirects != nil {
+			lines[0] += " --max-redirects=" + strconv.Itoa(*settings.MaxRedirects)
+		}
+	}
+	if settings.TimeoutMs > 0 {
+		lines[0] += " --timeout=" + formatSeconds(settings.TimeoutMs)
+	}
+	if settings.SkipTLSVerify {
+		lines[0] += " --verify=no"
+	}
+	switch r.Body.Mode {
+	case BodyURLEncoded:
+		lines[0] += " --form"
+	case BodyFormData:
+		lines[0] += " --multipart"
+	}
+
+	var note string
+	if r.Auth != nil {
+		credentials := shellQuote(r.Auth.Params["username"] + ":" + r.Auth.Params["password"])
+		switch r.Auth.Type {
+		case AuthBasic:
+			lines[0] += " --auth " + credentials
+		case AuthDigest:
+			lines[0] += " --auth-type digest --auth " + credentials
+		}
+		note = r.unsupportedAuth(true)
+	}
+
+	lines[0] += " " + string(r.Method) + " " + shellQuote(r.URL)
+
+	// Separators in the names of request items are escaped with a backslash
+	escapeItem := strings.NewReplacer(`\`, `\\`, ":", `\:`, "=", `\=`, "@", `\@`).Replace
+	for _, header := range r.Headers {
+		if header.Value == "" {
+			lines = append(lines, shellQuote(escapeItem(header.Key)+";"))
+		} else {
+			lines = append(lines, shellQuote(escapeItem(header.Key)+":"+header.Value))
+		}
+	}
+
+	switch r.Body.Mode {
+	case BodyRaw:
+		lines = append(lines, "--raw "+shellQuote(r.Body.Text))
+	case BodyURLEncoded, BodyFormData:
+		for _, field := range r.Body.Fields {
+			if field.Type == FormFieldFile {
+				item := escapeItem(field.Key) + "@" + field.FilePath
+				if field.ContentType != "" {
+					item += ";type=" + field.ContentType
+				}
+				lines = append(lines, shellQuote(item))
+			} else {
+				lines = append(lines, shellQuote(escapeItem(field.Key)+"="+field.Value))
+			}
+		}
+	case BodyBinary:
+		lines = append(lines, "< "+shellQuote(r.Body.FilePath))
+	}
+
+	snippet := strings.Join(lines, " \\\n  ") + "\n"
+	if note != "" {
+		snippet = "# " + note + "\n" + snippet
+	}
+	return snippet
+}
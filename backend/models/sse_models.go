@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ServerSentEvent is an event received from a text/event-stream response.
+type ServerSentEvent struct {
+	ID    string `json:"id,omitempty"`
+	Event string `json:"event"`
+	Data  string `json:"data"`
+	// Retry is the reconnection delay requested by the server, in milliseconds
+	Retry     int       `json:"retry,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestRequestBodyValidate(t *testing.T) {
+	testCases := []struct {
+		name  string
+		body  models.RequestBody
+		valid bool
+	}{
+		{"raw", models.RequestBody{Mode: models.BodyRaw, Raw: "hello"}, true},
+		{"form data with file", models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+			{Key: "name", Value: "value"},
+			{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/file.txt"},
+		}}, true},
+		{"unknown mode", models.RequestBody{Mode: "xml"}, false},
+		{"file without path", models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+			{Key: "file", Type: models.FormFieldFile},
+		}}, false},
+		{"url-encoded file", models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{
+			{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/file.txt"},
+		}}, false},
+		{"empty key", models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{{Value: "x"}}}, false},
+		{"binary without path", models.RequestBody{Mode: models.BodyBinary}, false},
+	}
+
+	for _, tc := range testCases {
+		err := tc.body.Validate()
+		if tc.valid && err != nil {
+			t.Errorf("%s: expected valid body, got %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected validation error", tc.name)
+		}
+	}
+}
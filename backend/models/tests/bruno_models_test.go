@@ -0,0 +1,288 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func brunoArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportBrunoCollection(t *testing.T) {
+	data := brunoArchive(t, map[string]string{
+		"pets/bruno.json": `{"version": "1", "name": "Pet Store", "type": "collection"}`,
+		"pets/collection.bru": `auth {
+  mode: bearer
+}
+
+auth:bearer {
+  token: {{token}}
+}
+`,
+		"pets/environments/Prod.bru": `vars {
+  baseUrl: https://prod.example.com
+  ~debug: true
+}
+vars:secret [
+  token
+]
+`,
+		"pets/Pets/folder.bru": `meta {
+  name: Pet operations
+  seq: 1
+}
+`,
+		"pets/Pets/Create pet.bru": `meta {
+  name: Create pet
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/pets
+  body: json
+  auth: inherit
+}
+
+headers {
+  Content-Type: application/json
+  ~X-Debug: 1
+}
+
+body:json {
+  {
+    "name": "Rex"
+  }
+}
+
+script:pre-request {
+  req.setHeader("X-Trace", "1");
+}
+`,
+		"pets/Pets/List pets.bru": `meta {
+  name: List pets
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/pets/:kind?sort=name
+  body: none
+  auth: apikey
+}
+
+params:query {
+  sort: name
+  limit: 10
+  ~offset: 0
+}
+
+params:path {
+  kind: dog
+}
+
+auth:apikey {
+  key: X-API-Key
+  value: secret
+  placement: queryparams
+}
+`,
+		"pets/Upload.bru": `meta {
+  name: Upload
+  seq: 3
+}
+
+put {
+  url: https://files.example.com/upload
+  body: multipartForm
+  auth: none
+}
+
+body:multipart-form {
+  caption: Me
+  photo: @file(/tmp/me.png|/tmp/you.png)
+}
+`,
+		"pets/Live.bru": `meta {
+  name: Live
+  type: grpc
+}
+`,
+	})
+
+	result, err := models.ImportBrunoCollection(data)
+	if err != nil {
+		t.Fatalf("Failed to import collection: %v", err)
+	}
+
+	if result.Name != "Pet Store" || result.Report.Imported != 3 || result.Report.Skipped != 1 {
+		t.Errorf("Unexpected result: %s, %+v", result.Name, result.Report)
+	}
+
+	expectedTree := []interface{}{"Pet operations", []interface{}{"List pets", "Create pet"}, "Upload"}
+	if tree := treeNames(&result.Collection, result.Items); !reflect.DeepEqual(tree, expectedTree) {
+		t.Fatalf("Unexpected folder tree: %v", tree)
+	}
+
+	list := result.Requests[0]
+	if list.Method != models.MethodGet || list.URL != "{{baseUrl}}/pets/dog" {
+		t.Errorf("Unexpected request: %s %s", list.Method, list.URL)
+	}
+	if !reflect.DeepEqual(list.QueryParams, map[string]string{"sort": "name", "limit": "10"}) {
+		t.Errorf("Unexpected query params: %v", list.QueryParams)
+	}
+	if !reflect.DeepEqual(list.Auth, &models.Auth{Type: models.AuthAPIKey, Params: map[string]string{"key": "X-API-Key", "value": "secret", "in": "query"}}) {
+		t.Errorf("Unexpected auth: %+v", list.Auth)
+	}
+
+	create := result.Requests[1]
+	expectedBody := &models.RequestBody{Mode: models.BodyJSON, Raw: "{\n  \"name\": \"Rex\"\n}"}
+	if !reflect.DeepEqual(create.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", create.BodyConfig)
+	}
+	if !reflect.DeepEqual(create.Headers, []models.Header{{Key: "Content-Type", Value: "application/json"}}) {
+		t.Errorf("Unexpected headers: %+v", create.Headers)
+	}
+	if !reflect.DeepEqual(create.Auth, &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "{{token}}"}}) {
+		t.Errorf("Inherited auth was not applied: %+v", create.Auth)
+	}
+
+	upload := result.Requests[2]
+	expectedBody = &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+		{Key: "caption", Value: "Me"},
+		{Key: "photo", Type: models.FormFieldFile, FilePath: "/tmp/me.png"},
+	}}
+	if !reflect.DeepEqual(upload.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", upload.BodyConfig)
+	}
+	if upload.Auth != nil {
+		t.Errorf("Unexpected auth: %+v", upload.Auth)
+	}
+
+	if len(result.Environments) != 1 {
+		t.Fatalf("Unexpected environments: %+v", result.Environments)
+	}
+	expectedVariables := map[string]string{"baseUrl": "https://prod.example.com", "token": ""}
+	if env := result.Environments[0]; env.Name != "Prod" || !reflect.DeepEqual(env.Variables, expectedVariables) {
+		t.Errorf("Unexpected environment: %s %v", env.Name, env.Variables)
+	}
+
+	for _, warning := range []models.ImportWarning{
+		{Item: "Pet operations / Create pet", Message: "Disabled header X-Debug was not imported"},
+		{Item: "Pet operations / Create pet", Message: "Bruno script:pre-request blocks are not supported and were not imported"},
+		{Item: "Upload", Message: "File field photo has 2 files, only the first one was imported"},
+		{Item: "Prod", Message: "Disabled variable debug was not imported"},
+		{Item: "Prod", Message: "Secret variable token is not exported by Bruno, set its value"},
+		{Item: "Live", Message: "Bruno grpc requests are not supported"},
+	} {
+		if !containsWarning(result.Report.Warnings, warning) {
+			t.Errorf("Missing warning %+v in %+v", warning, result.Report.Warnings)
+		}
+	}
+}
+
+func TestImportBrunoRequestFile(t *testing.T) {
+	file := `meta {
+  name: Login
+  type: http
+  seq: 1
+}
+
+post {
+  url: https://example.com/login
+  body: formUrlEncoded
+  auth: basic
+}
+
+body:form-urlencoded {
+  user: ana
+  ~remember: yes
+}
+
+auth:basic {
+  username: ana
+  password: secret
+}
+`
+
+	result, err := models.ImportBrunoCollection([]byte(file))
+	if err != nil {
+		t.Fatalf("Failed to import request: %v", err)
+	}
+	if len(result.Requests) != 1 {
+		t.Fatalf("Unexpected requests: %+v", result.Requests)
+	}
+
+	login := result.Requests[0]
+	if login.Name != "Login" || login.Method != models.MethodPost || login.URL != "https://example.com/login" {
+		t.Errorf("Unexpected request: %+v", login)
+	}
+	expectedBody := &models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{
+		{Key: "user", Value: "ana"},
+		{Key: "remember", Value: "yes", Disabled: true},
+	}}
+	if !reflect.DeepEqual(login.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", login.BodyConfig)
+	}
+	if !reflect.DeepEqual(login.Auth, &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "ana", "password": "secret"}}) {
+		t.Errorf("Unexpected auth: %+v", login.Auth)
+	}
+}
+
+func TestImportBrunoSkipsOtherFiles(t *testing.T) {
+	// Files other than .bru ones and bruno.json are never read, whatever their size
+	data := brunoArchive(t, map[string]string{
+		"bruno.json":       `{"name": "Assets"}`,
+		"List pets.bru":    "get {\n  url: https://example.com/pets\n}\n",
+		"assets/video.mp4": strings.Repeat("x", 2<<20),
+	})
+
+	result, err := models.ImportBrunoCollection(data)
+	if err != nil {
+		t.Fatalf("Failed to import collection: %v", err)
+	}
+	if len(result.Requests) != 1 || result.Requests[0].Name != "List pets" {
+		t.Errorf("Unexpected requests: %+v", result.Requests)
+	}
+}
+
+func TestImportBrunoCollectionErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"no bruno.json", brunoArchive(t, map[string]string{"pets/List pets.bru": "get {\n  url: https://example.com\n}\n"})},
+		{"unclosed block", []byte("get {\n  url: https://example.com\n")},
+		{"unexpected line", []byte("url: https://example.com\n")},
+		{"no requests", brunoArchive(t, map[string]string{"bruno.json": `{"name": "Empty"}`})},
+		{"invalid bruno.json", brunoArchive(t, map[string]string{"bruno.json": `{"name"`, "a.bru": "get {\n}\n"})},
+		{"oversized file", brunoArchive(t, map[string]string{"bruno.json": `{"name": "Big"}`, "a.bru": strings.Repeat("#", 2<<20)})},
+	}
+
+	for _, tc := range testCases {
+		if _, err := models.ImportBrunoCollection(tc.data); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
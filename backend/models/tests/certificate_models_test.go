@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestCertificateMatchesHost(t *testing.T) {
+	cases := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"api.example.com", "api.example.com:443", true},
+		{"API.example.com", "api.example.com:443", true},
+		{"api.example.com", "other.example.com:443", false},
+		{"*.internal", "billing.internal:443", true},
+		{"*.internal:8443", "billing.internal:8443", true},
+		{"*.internal:8443", "billing.internal:443", false},
+		{"*", "anything.example.com:443", true},
+	}
+
+	for _, c := range cases {
+		certificate := models.Certificate{Kind: models.CertificateClient, HostPattern: c.pattern}
+		if got := certificate.MatchesHost(c.host); got != c.want {
+			t.Errorf("MatchesHost(%q, %q) = %v, want %v", c.pattern, c.host, got, c.want)
+		}
+	}
+
+	ca := models.Certificate{Kind: models.CertificateCA, HostPattern: "*"}
+	if ca.MatchesHost("api.example.com:443") {
+		t.Errorf("CA certificates should never be presented as client certificates")
+	}
+}
+
+func TestCertificateValidate(t *testing.T) {
+	valid := models.Certificate{Name: "Client", Kind: models.CertificateClient, HostPattern: "*", Format: models.CertificatePKCS12, PFXPath: "client.p12"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Valid certificate was rejected: %v", err)
+	}
+
+	invalid := []models.Certificate{
+		{Kind: models.CertificateClient, HostPattern: "*", Format: models.CertificatePKCS12, PFXPath: "client.p12"},
+		{Name: "CA", Kind: models.CertificateCA, Format: models.CertificatePKCS12},
+		{Name: "Client", Kind: models.CertificateClient, HostPattern: "[", Format: models.CertificatePEM, CertPath: "c", KeyPath: "k"},
+		{Name: "Client", Kind: models.CertificateClient, HostPattern: "*", Format: "der"},
+		{Name: "Unknown", Kind: "server"},
+	}
+	for _, certificate := range invalid {
+		if err := certificate.Validate(); err == nil {
+			t.Errorf("Invalid certificate was accepted: %+v", certificate)
+		}
+	}
+}
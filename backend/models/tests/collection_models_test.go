@@ -176,3 +176,38 @@ func TestMethodIsValid(t *testing.T) {
 		t.Errorf("Method INVALID should not be valid")
 	}
 }
+
+func TestRequestSettingsValidate(t *testing.T) {
+	maxRedirects := 5
+	request := models.Request{
+		Name:     "Test Request",
+		Method:   models.MethodGet,
+		URL:      "http://example.com",
+		Settings: &models.RequestSettings{MaxRedirects: &maxRedirects, TimeoutMs: 1000, HTTPVersion: models.HTTPVersion1},
+	}
+	if err := request.Validate(); err != nil {
+		t.Errorf("Valid settings were rejected: %v", err)
+	}
+
+	request.Settings.HTTPVersion = "http3"
+	if err := request.Validate(); err == nil {
+		t.Errorf("Unsupported HTTP version should be rejected")
+	}
+
+	maxRedirects = -1
+	request.Settings = &models.RequestSettings{MaxRedirects: &maxRedirects}
+	if err := request.Validate(); err == nil {
+		t.Errorf("Negative max redirects should be rejected")
+	}
+
+	var settings *models.RequestSettings
+	if !settings.ShouldFollowRedirects() || settings.RedirectLimit() != models.DefaultMaxRedirects {
+		t.Errorf("Nil settings should follow redirects with the default limit")
+	}
+
+	maxRedirects = 0
+	settings = &models.RequestSettings{MaxRedirects: &maxRedirects}
+	if settings.RedirectLimit() != 0 {
+		t.Errorf("An explicit limit of 0 should not follow redirects, got %d", settings.RedirectLimit())
+	}
+}
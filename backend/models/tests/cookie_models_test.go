@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func cookieNames(cookies []*http.Cookie) []string {
+	names := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	return names
+}
+
+func TestCookieJarMatching(t *testing.T) {
+	jar := models.NewCookieJar("")
+	origin, _ := url.Parse("https://api.example.com/v1/login")
+
+	jar.SetCookies(origin, []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/"},
+		{Name: "secure", Value: "3", Path: "/", Secure: true},
+		{Name: "admin", Value: "4", Path: "/admin"},
+		{Name: "foreign", Value: "5", Domain: "other.com"},
+	})
+
+	cases := []struct {
+		url  string
+		want []string
+	}{
+		{"https://api.example.com/v1/users", []string{"host", "domain", "secure"}},
+		{"http://api.example.com/v1/users", []string{"host", "domain"}},
+		{"https://www.example.com/", []string{"domain"}},
+		{"https://api.example.com/admin/users", []string{"admin", "domain", "secure"}},
+		{"https://api.example.com/administrator", []string{"domain", "secure"}},
+		{"https://other.com/", []string{}},
+	}
+
+	for _, c := range cases {
+		target, _ := url.Parse(c.url)
+		got := cookieNames(jar.Cookies(target))
+		if len(got) != len(c.want) {
+			t.Errorf("Cookies(%s) = %v, want %v", c.url, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("Cookies(%s) = %v, want %v", c.url, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCookieJarExpiryAndRemoval(t *testing.T) {
+	jar := models.NewCookieJar("env")
+	origin, _ := url.Parse("https://example.com/")
+
+	jar.SetCookies(origin, []*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}})
+	jar.SetCookies(origin, []*http.Cookie{{Name: "a", Value: "", MaxAge: -1}})
+
+	if got := cookieNames(jar.Cookies(origin)); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Expired cookie was not removed: got %v", got)
+	}
+
+	if removed := jar.RemoveDomain("example.com", "b"); removed != 1 {
+		t.Errorf("RemoveDomain removed %d cookies, want 1", removed)
+	}
+	if got := jar.List(""); len(got) != 0 {
+		t.Errorf("Jar should be empty, got %v", got)
+	}
+}
+
+func TestCookieJarSaveAndLoad(t *testing.T) {
+	tempDir := t.TempDir()
+	jar := models.NewCookieJar("env")
+	origin, _ := url.Parse("https://example.com/")
+	jar.SetCookies(origin, []*http.Cookie{{Name: "session", Value: "abc", MaxAge: 3600}})
+
+	if err := jar.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save jar: %v", err)
+	}
+
+	loaded, err := models.LoadCookieJar(tempDir, "env")
+	if err != nil {
+		t.Fatalf("Failed to load jar: %v", err)
+	}
+	if got := cookieNames(loaded.Cookies(origin)); len(got) != 1 || got[0] != "session" {
+		t.Errorf("Loaded jar does not contain the session cookie: %v", got)
+	}
+
+	empty, err := models.LoadCookieJar(tempDir, "missing")
+	if err != nil || len(empty.List("")) != 0 {
+		t.Errorf("Missing jar should load empty, got %v, %v", empty, err)
+	}
+}
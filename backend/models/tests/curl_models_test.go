@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestParseCurlCommand(t *testing.T) {
+	// As copied from a browser, with a $'...' body and line continuations
+	command := `curl 'https://api.example.com/users?page=2&sort=name' \
+  -X 'PUT' \
+  -H 'accept: application/json' \
+  -H 'Content-Type: application/json' \
+  -H 'Authorization: Bearer abc123' \
+  --data-raw $'{"name":"O\'Brien","bio":"line\\nbreak"}' \
+  --compressed -sSk`
+
+	result, err := models.ParseCurlCommand(command)
+	if err != nil {
+		t.Fatalf("Failed to parse command: %v", err)
+	}
+	req := result.Request
+
+	if req.Method != models.MethodPut || req.URL != "https://api.example.com/users" {
+		t.Errorf("Unexpected request: %s %s", req.Method, req.URL)
+	}
+	if !reflect.DeepEqual(req.QueryParams, map[string]string{"page": "2", "sort": "name"}) {
+		t.Errorf("Unexpected query params: %v", req.QueryParams)
+	}
+	if !reflect.DeepEqual(req.Headers, []models.Header{{Key: "accept", Value: "application/json"}}) {
+		t.Errorf("Unexpected headers: %v", req.Headers)
+	}
+	if req.Auth == nil || req.Auth.Type != models.AuthBearer || req.Auth.Params["token"] != "abc123" {
+		t.Errorf("Unexpected auth: %+v", req.Auth)
+	}
+	expectedBody := &models.RequestBody{Mode: models.BodyJSON, Raw: `{"name":"O'Brien","bio":"line\nbreak"}`}
+	if !reflect.DeepEqual(req.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", req.BodyConfig)
+	}
+	if req.Settings == nil || !req.Settings.SkipTLSVerify {
+		t.Errorf("Expected TLS verification to be skipped: %+v", req.Settings)
+	}
+	if len(result.Report.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %+v", result.Report.Warnings)
+	}
+}
+
+func TestParseCurlCommandBodies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		command  string
+		method   models.Method
+		body     *models.RequestBody
+		auth     *models.Auth
+		warnings []models.ImportWarning
+	}{
+		{
+			name:    "form data",
+			command: `curl -d name=Rex --data-urlencode "bio=good boy & more" example.com/pets`,
+			method:  models.MethodPost,
+			body: &models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{
+				{Key: "name", Value: "Rex"},
+				{Key: "bio", Value: "good boy & more"},
+			}},
+		},
+		{
+			name:    "data without content type",
+			command: `curl https://example.com -d '{"a":1}'`,
+			method:  models.MethodPost,
+			body:    &models.RequestBody{Mode: models.BodyRaw, Raw: `{"a":1}`, ContentType: "application/x-www-form-urlencoded"},
+		},
+		{
+			name:    "multipart",
+			command: `curl -F 'caption=Me' -F 'file=@/tmp/me.png;type=image/png' -u user:pass --digest https://example.com/upload`,
+			method:  models.MethodPost,
+			body: &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+				{Key: "caption", Value: "Me"},
+				{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/me.png", ContentType: "image/png"},
+			}},
+			warnings: []models.ImportWarning{
+				{Message: "Digest credentials were not imported, set them along with the server realm and nonce"},
+			},
+		},
+		{
+			name:    "binary file",
+			command: `curl -XPATCH https://example.com/blob -H "Content-Type: application/octet-stream" --data-binary @data.bin`,
+			method:  models.MethodPatch,
+			body:    &models.RequestBody{Mode: models.BodyBinary, FilePath: "data.bin", ContentType: "application/octet-stream"},
+		},
+		{
+			name:    "get with data",
+			command: `curl -G https://example.com/search -d q=go --proxy http://proxy:3128`,
+			method:  models.MethodGet,
+			warnings: []models.ImportWarning{
+				{Message: "Option --proxy is not supported and was ignored"},
+			},
+		},
+		{
+			name:    "basic auth",
+			command: `curl -I -u 'admin:s3cr:et' https://example.com`,
+			method:  models.MethodHead,
+			auth:    &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "admin", "password": "s3cr:et"}},
+		},
+		{
+			name:    "aws",
+			command: `curl --aws-sigv4 aws:amz:eu-west-1:s3 --user AKIA:secret https://bucket.s3.amazonaws.com`,
+			method:  models.MethodGet,
+			auth: &models.Auth{Type: models.AuthAWSSigV4, Params: map[string]string{
+				"access_key": "AKIA", "secret_key": "secret", "region": "eu-west-1", "service": "s3",
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		result, err := models.ParseCurlCommand(tc.command)
+		if err != nil {
+			t.Errorf("%s: failed to parse command: %v", tc.name, err)
+			continue
+		}
+		req := result.Request
+		if req.Method != tc.method {
+			t.Errorf("%s: unexpected method %s", tc.name, req.Method)
+		}
+		if !reflect.DeepEqual(req.BodyConfig, tc.body) {
+			t.Errorf("%s: unexpected body %+v", tc.name, req.BodyConfig)
+		}
+		if !reflect.DeepEqual(req.Auth, tc.auth) {
+			t.Errorf("%s: unexpected auth %+v", tc.name, req.Auth)
+		}
+		for _, warning := range tc.warnings {
+			if !containsWarning(result.Report.Warnings, warning) {
+				t.Errorf("%s: missing warning %q in %+v", tc.name, warning.Message, result.Report.Warnings)
+			}
+		}
+	}
+
+	result, err := models.ParseCurlCommand(`curl -G https://example.com/search -d q=go`)
+	if err != nil {
+		t.Fatalf("Failed to parse command: %v", err)
+	}
+	if !reflect.DeepEqual(result.Request.QueryParams, map[string]string{"q": "go"}) {
+		t.Errorf("Data sent with -G should be in the query: %v", result.Request.QueryParams)
+	}
+}
+
+func TestParseCurlCommandErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+	}{
+		{"not curl", "wget https://example.com"},
+		{"no URL", "curl -H 'Accept: */*'"},
+		{"unterminated quote", "curl 'https://example.com"},
+		{"missing argument", "curl https://example.com -H"},
+		{"empty", ""},
+	}
+
+	for _, tc := range testCases {
+		if _, err := models.ParseCurlCommand(tc.command); err == nil {
+			t.Errorf("%s: expected a parse error", tc.name)
+		}
+	}
+}
+
+func TestToCurlCommand(t *testing.T) {
+	env := &models.Environment{Variables: map[string]string{"baseUrl": "https://api.example.com", "token": "abc123"}}
+	req := models.Request{
+		Name:        "Create a pet",
+		Method:      models.MethodPost,
+		URL:         "{{baseUrl}}/pets?source=cli",
+		QueryParams: map[string]string{"dry run": "true"},
+		Headers:     []models.Header{{Key: "X-Trace", Value: "{{trace}}"}},
+		BodyConfig:  &models.RequestBody{Mode: models.BodyJSON, Raw: `{"name": "O'Brien"}`},
+		Auth:        &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "{{token}}"}},
+		Settings:    &models.RequestSettings{SkipTLSVerify: true, TimeoutMs: 1500},
+	}
+
+	command := req.ToCurlCommand(env)
+	expected := strings.Join([]string{
+		`curl 'https://api.example.com/pets?dry+run=true&source=cli'`,
+		`--location`,
+		`--insecure`,
+		`--max-time 1.5`,
+		`--compressed`,
+		`--header 'X-Trace: {{trace}}'`,
+		`--header 'Content-Type: application/json'`,
+		`--header 'Authorization: Bearer abc123'`,
+		`--data-raw '{"name": "O'\''Brien"}'`,
+	}, " \\\n  ")
+	if command != expected {
+		t.Errorf("Unexpected command:\n%s\nwant:\n%s", command, expected)
+	}
+}
+
+func TestCurlRoundTrip(t *testing.T) {
+	maxRedirects := 3
+	requests := []models.Request{
+		{
+			Method:      models.MethodDelete,
+			URL:         "https://example.com/pets/7",
+			QueryParams: map[string]string{"force": "yes & no"},
+			Headers:     []models.Header{{Key: "X-Empty"}, {Key: "Accept-Encoding", Value: "identity"}},
+			Settings:    &models.RequestSettings{MaxRedirects: &maxRedirects, HTTPVersion: models.HTTPVersion2},
+		},
+		{
+			Method: models.MethodPost,
+			URL:    "https://example.com/login",
+			BodyConfig: &models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{
+				{Key: "user", Value: "ana"},
+				{Key: "pass word", Value: "a=b&c"},
+			}},
+			Auth: &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "ana", "password": "x"}},
+		},
+		{
+			Method: models.MethodPut,
+			URL:    "https://example.com/upload",
+			BodyConfig: &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+				{Key: "note", Value: "@not a file"},
+				{Key: "meta", Value: "{}", ContentType: "application/json"},
+				{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/a file.png"},
+			}},
+		},
+		{
+			Method:     models.MethodPost,
+			URL:        "https://example.com/notes",
+			BodyConfig: &models.RequestBody{Mode: models.BodyRaw, Raw: "line one\nline 'two'", ContentType: "text/markdown"},
+		},
+	}
+
+	for _, req := range requests {
+		command := req.ToCurlCommand(nil)
+		result, err := models.ParseCurlCommand(command)
+		if err != nil {
+			t.Errorf("Failed to parse exported command: %v\n%s", err, command)
+			continue
+		}
+
+		imported := result.Request
+		imported.ID, imported.Name = "", ""
+		if !reflect.DeepEqual(imported, req) {
+			t.Errorf("Request changed in the round trip:\n%+v\nwant:\n%+v\n%s", imported, req, command)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/FedeBP/pumoide/backend/models"
@@ -40,3 +41,29 @@ func TestEnvironmentSaveAndLoad(t *testing.T) {
 		t.Errorf("Loaded environment variables do not match")
 	}
 }
+
+func TestEnvironmentSaveProxyCredentials(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "env_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	env := &models.Environment{ID: "proxied", Name: "Proxied"}
+	if err := env.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save environment: %v", err)
+	}
+
+	env.Proxy = &models.ProxySettings{URL: "http://proxy.example.com:8080", Username: "ana", Password: "secret"}
+	if err := env.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save environment: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(tempDir, "proxied.json"))
+	if err != nil {
+		t.Fatalf("Failed to stat environment: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Environment with proxy credentials saved with mode %o", mode)
+	}
+}
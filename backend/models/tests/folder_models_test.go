@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+// treeIDs lists the IDs of a tree level, folders with their items in brackets.
+func treeIDs(items []models.CollectionItem) []interface{} {
+	ids := []interface{}{}
+	for _, item := range items {
+		if item.Folder != nil {
+			ids = append(ids, item.Folder.Name, treeIDs(item.Folder.Items))
+		} else {
+			ids = append(ids, item.RequestID)
+		}
+	}
+	return ids
+}
+
+// treeNames lists the names of a tree level, folders with their items in brackets.
+func treeNames(collection *models.Collection, items []models.CollectionItem) []interface{} {
+	names := []interface{}{}
+	for _, item := range items {
+		if item.Folder != nil {
+			names = append(names, item.Folder.Name, treeNames(collection, item.Folder.Items))
+		} else if req, ok := collection.FindRequest(item.RequestID); ok {
+			names = append(names, req.Name)
+		}
+	}
+	return names
+}
+
+func folderCollection(t *testing.T) *models.Collection {
+	t.Helper()
+	collection := &models.Collection{Name: "Pets"}
+	for _, id := range []string{"list", "create", "delete"} {
+		if err := collection.AddRequest(models.Request{ID: id, Name: id, Method: models.MethodGet, URL: "https://example.com"}); err != nil {
+			t.Fatalf("Failed to add request: %v", err)
+		}
+	}
+	return collection
+}
+
+func TestLoadFlatCollection(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "folder_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Saved before collections had folders
+	data := `{"id": "flat", "name": "Flat", "requests": [
+		{"id": "a", "name": "A", "method": "GET", "url": "https://example.com/a"},
+		{"id": "b", "name": "B", "method": "GET", "url": "https://example.com/b"}
+	]}`
+	if err := os.WriteFile(filepath.Join(tempDir, "flat.json"), []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to write collection: %v", err)
+	}
+
+	collection, err := models.LoadCollection(tempDir, "flat")
+	if err != nil {
+		t.Fatalf("Failed to load collection: %v", err)
+	}
+	expected := []models.CollectionItem{{RequestID: "a"}, {RequestID: "b"}}
+	if !reflect.DeepEqual(collection.Items, expected) {
+		t.Errorf("Unexpected items: %+v", collection.Items)
+	}
+}
+
+func TestFolderTree(t *testing.T) {
+	collection := folderCollection(t)
+
+	pets := &models.Folder{Name: "Pets", Description: "Pet endpoints"}
+	if err := collection.AddFolder(pets, ""); err != nil {
+		t.Fatalf("Failed to add folder: %v", err)
+	}
+	admin := &models.Folder{Name: "Admin"}
+	if err := collection.AddFolder(admin, pets.ID); err != nil {
+		t.Fatalf("Failed to add subfolder: %v", err)
+	}
+
+	for _, move := range []struct {
+		item     string
+		parent   string
+		position int
+	}{
+		{"list", pets.ID, -1},
+		{"create", pets.ID, 0},
+		{"delete", admin.ID, 5},
+	} {
+		if err := collection.MoveItem(move.item, move.parent, move.position); err != nil {
+			t.Fatalf("Failed to move %s: %v", move.item, err)
+		}
+	}
+
+	expected := []interface{}{"Pets", []interface{}{"create", "Admin", []interface{}{"delete"}, "list"}}
+	if ids := treeIDs(collection.Items); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Unexpected tree: %v", ids)
+	}
+
+	if err := collection.ReorderItems(pets.ID, []string{"list", admin.ID, "create"}); err != nil {
+		t.Fatalf("Failed to reorder items: %v", err)
+	}
+	expected = []interface{}{"Pets", []interface{}{"list", "Admin", []interface{}{"delete"}, "create"}}
+	if ids := treeIDs(collection.Items); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Unexpected tree: %v", ids)
+	}
+
+	if !collection.RemoveRequest("list") {
+		t.Fatal("Failed to remove request")
+	}
+	expected = []interface{}{"Pets", []interface{}{"Admin", []interface{}{"delete"}, "create"}}
+	if ids := treeIDs(collection.Items); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Unexpected tree after removing a request: %v", ids)
+	}
+
+	if !collection.RemoveFolder(admin.ID) {
+		t.Fatal("Failed to remove folder")
+	}
+	if _, ok := collection.FindRequest("delete"); ok {
+		t.Error("Requests of a removed folder should be removed")
+	}
+	if collection.RemoveFolder("create") || collection.RemoveFolder("missing") {
+		t.Error("Only folders should be removed")
+	}
+}
+
+func TestFolderTreeErrors(t *testing.T) {
+	collection := folderCollection(t)
+	pets := &models.Folder{Name: "Pets"}
+	if err := collection.AddFolder(pets, ""); err != nil {
+		t.Fatalf("Failed to add folder: %v", err)
+	}
+	admin := &models.Folder{Name: "Admin"}
+	if err := collection.AddFolder(admin, pets.ID); err != nil {
+		t.Fatalf("Failed to add subfolder: %v", err)
+	}
+
+	if err := collection.AddFolder(&models.Folder{}, ""); err == nil {
+		t.Error("Expected an error adding a folder without name")
+	}
+	if err := collection.AddFolder(&models.Folder{Name: "Orphan"}, "missing"); err == nil {
+		t.Error("Expected an error adding a folder to a missing parent")
+	}
+	if err := collection.MoveItem(pets.ID, admin.ID, -1); err == nil {
+		t.Error("Expected an error moving a folder inside its subfolder")
+	}
+	if err := collection.MoveItem(pets.ID, pets.ID, -1); err == nil {
+		t.Error("Expected an error moving a folder inside itself")
+	}
+	if err := collection.MoveItem("missing", "", -1); err == nil {
+		t.Error("Expected an error moving a missing item")
+	}
+	if err := collection.ReorderItems("", []string{"list", "create"}); err == nil {
+		t.Error("Expected an error leaving items out of the order")
+	}
+	if err := collection.ReorderItems("", []string{"list", "list", "create", "delete"}); err == nil {
+		t.Error("Expected an error listing an item twice")
+	}
+
+	expected := []interface{}{"list", "create", "delete", "Pets", []interface{}{"Admin", []interface{}{}}}
+	if ids := treeIDs(collection.Items); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Failed operations should leave the tree unchanged: %v", ids)
+	}
+}
+
+func TestPostmanExportFolders(t *testing.T) {
+	collection := folderCollection(t)
+	pets := &models.Folder{Name: "Pets", Description: "Pet endpoints"}
+	if err := collection.AddFolder(pets, ""); err != nil {
+		t.Fatalf("Failed to add folder: %v", err)
+	}
+	if err := collection.MoveItem("create", pets.ID, -1); err != nil {
+		t.Fatalf("Failed to move request: %v", err)
+	}
+
+	postman := collection.ToPostmanCollection()
+	if len(postman.Item) != 3 {
+		t.Fatalf("Unexpected items: %+v", postman.Item)
+	}
+	folder := postman.Item[2]
+	if folder.Name != "Pets" || folder.Description != "Pet endpoints" || len(folder.Item) != 1 || folder.Item[0].Name != "create" {
+		t.Errorf("Unexpected folder: %+v", folder)
+	}
+}
@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+const harArchive = `{
+	"log": {
+		"version": "1.2",
+		"creator": {"name": "WebInspector", "version": "537.36"},
+		"pages": [{"id": "page_1", "startedDateTime": "2024-05-01T10:00:00.000Z", "title": "https://app.example.com/"}],
+		"entries": [
+			{
+				"startedDateTime": "2024-05-01T10:00:00.100Z",
+				"time": 120.5,
+				"request": {
+					"method": "POST",
+					"url": "https://api.example.com/v1/login?next=%2Fhome&next=ignored",
+					"httpVersion": "http/2.0",
+					"headers": [
+						{"name": ":authority", "value": "api.example.com"},
+						{"name": "accept-encoding", "value": "gzip, deflate, br"},
+						{"name": "content-type", "value": "application/json"},
+						{"name": "authorization", "value": "Bearer abc123"},
+						{"name": "x-client", "value": "web"}
+					],
+					"cookies": [{"name": "session", "value": "s1", "expires": "Thu, 01 Jan 2026 00:00:00 GMT"}],
+					"queryString": [],
+					"postData": {"mimeType": "application/json", "text": "{\"user\":\"ana\"}"},
+					"headersSize": -1,
+					"bodySize": 14
+				},
+				"response": {"status": 200, "content": {"size": 2, "mimeType": "application/json; charset=utf-8", "text": "{}"}},
+				"timings": {"send": 0.1, "wait": 100, "receive": 20}
+			},
+			{
+				"startedDateTime": "2024-05-01T10:00:01.000Z",
+				"request": {
+					"method": "POST",
+					"url": "https://api.example.com/v1/avatar",
+					"headers": [{"name": "Content-Type", "value": "multipart/form-data; boundary=----x"}],
+					"postData": {"mimeType": "multipart/form-data; boundary=----x", "params": [
+						{"name": "caption", "value": "Me"},
+						{"name": "file", "fileName": "me.png", "contentType": "image/png"}
+					]}
+				},
+				"response": {"status": 204, "content": {"mimeType": "x-unknown"}}
+			},
+			{
+				"startedDateTime": "2024-05-01T10:00:02.000Z",
+				"request": {
+					"method": "POST",
+					"url": "https://auth.example.com/token",
+					"headers": [],
+					"postData": {"mimeType": "application/x-www-form-urlencoded", "params": [
+						{"name": "grant_type", "value": "client_credentials"},
+						{"name": "scope", "value": "read%20write"}
+					]}
+				},
+				"response": {"status": 200, "content": {"mimeType": "application/json"}}
+			},
+			{
+				"startedDateTime": "2024-05-01T10:00:03.000Z",
+				"request": {"method": "GET", "url": "https://cdn.other.com/app.js", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "application/javascript"}}
+			},
+			{
+				"startedDateTime": "2024-05-01T10:00:04.000Z",
+				"request": {"method": "GET", "url": "data:image/png;base64,AAAA", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "image/png"}}
+			}
+		]
+	}
+}`
+
+func TestImportHAR(t *testing.T) {
+	result, err := models.ImportHAR([]byte(harArchive), models.HARFilter{})
+	if err != nil {
+		t.Fatalf("Failed to import archive: %v", err)
+	}
+
+	if result.Name != "https://app.example.com/" || result.Description != "Captured with WebInspector 537.36" {
+		t.Errorf("Unexpected collection info: %q, %q", result.Name, result.Description)
+	}
+	if result.Report.Imported != 4 || result.Report.Skipped != 1 {
+		t.Errorf("Unexpected report: %+v", result.Report)
+	}
+
+	login := result.Requests[0]
+	if login.Name != "POST /v1/login" || login.URL != "https://api.example.com/v1/login" {
+		t.Errorf("Unexpected request: %s, %s", login.Name, login.URL)
+	}
+	expectedTree := []interface{}{
+		"api.example.com", []interface{}{"POST /v1/login", "POST /v1/avatar"},
+		"auth.example.com", []interface{}{"POST /token"},
+		"cdn.other.com", []interface{}{"GET /app.js"},
+	}
+	if tree := treeNames(&result.Collection, result.Items); !reflect.DeepEqual(tree, expectedTree) {
+		t.Errorf("Requests were not grouped in a folder per host: %v", tree)
+	}
+	if !reflect.DeepEqual(login.QueryParams, map[string]string{"next": "/home"}) {
+		t.Errorf("Unexpected query params: %v", login.QueryParams)
+	}
+	if !reflect.DeepEqual(login.Headers, []models.Header{{Key: "x-client", Value: "web"}}) {
+		t.Errorf("Unexpected headers: %v", login.Headers)
+	}
+	if login.Auth == nil || login.Auth.Type != models.AuthBearer || login.Auth.Params["token"] != "abc123" {
+		t.Errorf("Unexpected auth: %+v", login.Auth)
+	}
+	if !reflect.DeepEqual(login.BodyConfig, &models.RequestBody{Mode: models.BodyJSON, Raw: `{"user":"ana"}`}) {
+		t.Errorf("Unexpected body: %+v", login.BodyConfig)
+	}
+
+	avatar := result.Requests[1]
+	if avatar.Headers != nil || avatar.BodyConfig == nil || !reflect.DeepEqual(avatar.BodyConfig.FormData, []models.FormField{{Key: "caption", Value: "Me"}}) {
+		t.Errorf("Unexpected multipart request: %+v, %+v", avatar.Headers, avatar.BodyConfig)
+	}
+	if !containsWarning(result.Report.Warnings, models.ImportWarning{Item: "POST https://api.example.com/v1/avatar", Message: "File field file needs a file, it was not imported"}) {
+		t.Errorf("Missing warning about the file field: %+v", result.Report.Warnings)
+	}
+
+	token := result.Requests[2]
+	expectedFields := []models.FormField{{Key: "grant_type", Value: "client_credentials"}, {Key: "scope", Value: "read write"}}
+	if token.BodyConfig == nil || token.BodyConfig.Mode != models.BodyURLEncoded || !reflect.DeepEqual(token.BodyConfig.URLEncoded, expectedFields) {
+		t.Errorf("Unexpected form body: %+v", token.BodyConfig)
+	}
+
+	if !containsWarning(result.Report.Warnings, models.ImportWarning{Item: "GET data:image/png;base64,AAAA", Message: "Only HTTP requests can be imported"}) {
+		t.Errorf("Missing warning about the data URL: %+v", result.Report.Warnings)
+	}
+}
+
+func TestImportHARFilter(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter models.HARFilter
+		urls   []string
+	}{
+		{
+			name:   "domain with subdomains",
+			filter: models.HARFilter{Domains: []string{"example.com"}},
+			urls:   []string{"https://api.example.com/v1/login", "https://api.example.com/v1/avatar", "https://auth.example.com/token"},
+		},
+		{
+			name:   "exact host",
+			filter: models.HARFilter{Domains: []string{"auth.example.com", "cdn.other.com"}},
+			urls:   []string{"https://auth.example.com/token", "https://cdn.other.com/app.js"},
+		},
+		{
+			name:   "content type",
+			filter: models.HARFilter{Domains: []string{"api.example.com"}, ContentTypes: []string{"application/json"}},
+			urls:   []string{"https://api.example.com/v1/login"},
+		},
+		{
+			name:   "wildcard",
+			filter: models.HARFilter{ContentTypes: []string{"application/*"}},
+			urls:   []string{"https://api.example.com/v1/login", "https://auth.example.com/token", "https://cdn.other.com/app.js"},
+		},
+	}
+
+	for _, tc := range testCases {
+		result, err := models.ImportHAR([]byte(harArchive), tc.filter)
+		if err != nil {
+			t.Fatalf("%s: failed to import archive: %v", tc.name, err)
+		}
+		var urls []string
+		for _, req := range result.Requests {
+			urls = append(urls, req.URL)
+		}
+		if !reflect.DeepEqual(urls, tc.urls) {
+			t.Errorf("%s: unexpected requests %v", tc.name, urls)
+		}
+	}
+}
+
+func TestImportHARErrors(t *testing.T) {
+	for _, data := range []string{"", "[]", `{"info": {"name": "Postman"}}`} {
+		if _, err := models.ImportHAR([]byte(data), models.HARFilter{}); err == nil {
+			t.Errorf("Expected an import error for %q", data)
+		}
+	}
+}
+
+func TestNewHAR(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := models.HistoryEntry{
+		Timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Request:   models.Request{Method: models.MethodPost, URL: "{{baseUrl}}/upload"},
+		ResolvedRequest: models.ResolvedRequest{
+			Method:   "POST",
+			URL:      "https://example.com/upload?kind=avatar",
+			Headers:  []models.Header{{Key: "Content-Type", Value: "image/png"}},
+			Body:     "iVBORw0KGgo=",
+			Encoding: models.EncodingBase64,
+		},
+		Response: &models.ExecutionResponse{
+			StatusCode: 302,
+			Headers:    []models.Header{{Key: "Location", Value: "/done"}},
+			Cookies:    []models.Cookie{{Name: "session", Value: "s1", Expires: &expires, HttpOnly: true}},
+			Timings:    models.ResponseTimings{TCPConnection: 2, TLSHandshake: 5, TimeToFirstByte: 30, ContentTransfer: 1, Total: 40},
+			RemoteAddr: "[::1]:8443",
+			Protocol:   "HTTP/2.0",
+		},
+	}
+	failed := models.HistoryEntry{
+		Request: models.Request{Method: models.MethodGet, URL: "https://down.example.com"},
+		Error:   "Failed to execute request: connection refused",
+	}
+
+	har := models.NewHAR([]models.HistoryEntry{entry, failed})
+	if len(har.Log.Entries) != 2 || har.Log.Creator.Name != "Pumoide" {
+		t.Fatalf("Unexpected archive: %+v", har.Log)
+	}
+
+	upload := har.Log.Entries[0]
+	if upload.Request.HTTPVersion != "HTTP/2.0" || upload.Request.PostData == nil || upload.Request.PostData.Text != "" || upload.Request.PostData.MimeType != "image/png" {
+		t.Errorf("Binary request bodies should be left out: %+v", upload.Request)
+	}
+	if upload.Response.RedirectURL != "/done" || upload.Response.StatusText != "Found" {
+		t.Errorf("Unexpected response: %+v", upload.Response)
+	}
+	if len(upload.Response.Cookies) != 1 || upload.Response.Cookies[0].Expires != "2030-01-01T00:00:00Z" {
+		t.Errorf("Unexpected cookies: %+v", upload.Response.Cookies)
+	}
+	expectedTimings := models.HARTimings{Blocked: -1, DNS: -1, Connect: 7, SSL: 5, Wait: 30, Receive: 1}
+	if upload.Timings != expectedTimings || upload.Time != 40 || upload.ServerIPAddress != "::1" {
+		t.Errorf("Unexpected timings: %+v, %v, %s", upload.Timings, upload.Time, upload.ServerIPAddress)
+	}
+
+	down := har.Log.Entries[1]
+	if down.Request.URL != "https://down.example.com" || down.Response.Status != 0 || down.Comment != failed.Error {
+		t.Errorf("Unexpected failed entry: %+v", down)
+	}
+}
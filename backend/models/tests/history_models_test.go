@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestNewResolvedRequestBodySize(t *testing.T) {
+	small := "payload"
+	large := strings.Repeat("a", 2<<20)
+
+	for _, test := range []struct {
+		name          string
+		body          string
+		contentLength int64
+		expected      string
+	}{
+		{"known length", small, int64(len(small)), small},
+		{"streamed", small, -1, small},
+		{"large streamed", large, -1, ""},
+	} {
+		req, _ := http.NewRequest(http.MethodPost, "http://example.com", nil)
+		req.ContentLength = test.contentLength
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(test.body)), nil
+		}
+
+		if resolved := models.NewResolvedRequest(req); resolved.Body != test.expected {
+			t.Errorf("%s: unexpected body of %d bytes", test.name, len(resolved.Body))
+		}
+	}
+}
+
+func TestHistoryEntrySaveCredentials(t *testing.T) {
+	tempDir := t.TempDir()
+
+	env := &models.Environment{ID: "proxied", Name: "Proxied", Proxy: &models.ProxySettings{
+		URL: "http://proxy.example.com:8080", Username: "ana", Password: "secret",
+	}}
+	entry := models.NewHistoryEntry(models.Request{Name: "Me", Method: models.MethodGet, URL: "https://example.com"}, env)
+	entry.ResolvedRequest.Headers = []models.Header{{Key: "Authorization", Value: "Bearer secret"}}
+	if err := entry.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save history entry: %v", err)
+	}
+
+	for _, name := range []string{entry.ID + ".json", "index.json"} {
+		info, err := os.Stat(filepath.Join(tempDir, name))
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", name, err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s saved with mode %o", name, mode)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, entry.ID+".json"))
+	if err != nil {
+		t.Fatalf("Failed to read history entry: %v", err)
+	}
+	if strings.Contains(string(data), "ana") || env.Proxy.Password != "secret" {
+		t.Errorf("Proxy credentials should be left out of the snapshot only: %s", data)
+	}
+}
+
+func TestHistoryIndex(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Recorded before the history had an index
+	start := time.Now()
+	for i, name := range []string{"Old", "Older"} {
+		entry := models.NewHistoryEntry(models.Request{Name: name, Method: models.MethodGet, URL: "https://example.com"}, nil)
+		entry.Timestamp = start.Add(-time.Duration(i+1) * time.Minute)
+		data, _ := json.Marshal(entry)
+		if err := os.WriteFile(filepath.Join(tempDir, entry.ID+".json"), data, 0644); err != nil {
+			t.Fatalf("Failed to write history entry: %v", err)
+		}
+	}
+
+	recent := models.NewHistoryEntry(models.Request{ID: "req1", Name: "Recent", Method: models.MethodPost, URL: "https://example.com"}, nil)
+	recent.Response = &models.ExecutionResponse{StatusCode: http.StatusCreated}
+	if err := recent.Save(tempDir); err != nil {
+		t.Fatalf("Failed to save history entry: %v", err)
+	}
+
+	entries, total, err := models.ListHistory(tempDir, models.HistoryFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("Failed to list history: %v", err)
+	}
+	if total != 3 || len(entries) != 2 || entries[0].Request.Name != "Recent" || entries[1].Request.Name != "Old" {
+		t.Errorf("Unexpected history: %d entries, %+v", total, entries)
+	}
+
+	if entries, _, _ := models.ListHistory(tempDir, models.HistoryFilter{RequestIDs: []string{"req1"}, Status: http.StatusCreated}); len(entries) != 1 {
+		t.Errorf("Unexpected filtered history: %+v", entries)
+	}
+	if entries, _, _ := models.ListHistory(tempDir, models.HistoryFilter{RequestIDs: []string{}}); len(entries) != 0 {
+		t.Errorf("An empty request list should match no entry: %+v", entries)
+	}
+
+	if err := models.PruneHistory(tempDir, 1); err != nil {
+		t.Fatalf("Failed to prune history: %v", err)
+	}
+	entries, total, _ = models.ListHistory(tempDir, models.HistoryFilter{})
+	if total != 1 || entries[0].ID != recent.ID {
+		t.Errorf("Pruning should keep the newest entries: %+v", entries)
+	}
+	if files, _ := filepath.Glob(filepath.Join(tempDir, "*.json")); len(files) != 2 {
+		t.Errorf("Pruned entries should be removed, got %d files", len(files))
+	}
+
+	if err := models.DeleteHistoryEntry(tempDir, recent.ID); err != nil {
+		t.Fatalf("Failed to delete history entry: %v", err)
+	}
+	if _, total, _ := models.ListHistory(tempDir, models.HistoryFilter{}); total != 0 {
+		t.Errorf("Deleted entry is still listed")
+	}
+}
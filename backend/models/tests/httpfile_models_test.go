@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestImportHTTPFile(t *testing.T) {
+	file := `@baseUrl = https://api.example.com
+@token = abc123
+
+### List pets
+GET {{baseUrl}}/pets
+    ?limit=10
+    &sort=name
+Accept: application/json
+
+### Create a pet
+# @no-redirect
+POST {{baseUrl}}/pets HTTP/2
+Content-Type: application/json
+Authorization: Bearer {{token}}
+
+{
+  "name": "Rex"
+}
+
+> {%
+  client.global.set("id", response.body.id);
+%}
+
+###
+# @name login
+POST /login HTTP/1.1
+Host: auth.example.com
+Authorization: Basic ana secret
+Content-Type: application/x-www-form-urlencoded
+
+user=ana&id={{$uuid}}
+
+### Upload
+POST https://api.example.com/upload
+Content-Type: multipart/form-data; boundary=xyz
+
+--xyz
+Content-Disposition: form-data; name="caption"
+
+Me
+--xyz
+Content-Disposition: form-data; name="file"; filename="me.png"
+Content-Type: image/png
+
+< ./me.png
+--xyz--
+
+### Socket
+WEBSOCKET wss://example.com/socket
+`
+
+	result, err := models.ImportHTTPFile([]byte(file), "")
+	if err != nil {
+		t.Fatalf("Failed to import file: %v", err)
+	}
+
+	if result.Name != "HTTP file import" || result.Report.Imported != 4 || result.Report.Skipped != 1 {
+		t.Errorf("Unexpected result: %s, %+v", result.Name, result.Report)
+	}
+	if len(result.Environments) != 1 || !reflect.DeepEqual(result.Environments[0].Variables, map[string]string{"baseUrl": "https://api.example.com", "token": "abc123"}) {
+		t.Errorf("Unexpected environments: %+v", result.Environments)
+	}
+	if len(result.Requests) != 4 {
+		t.Fatalf("Unexpected requests: %+v", result.Requests)
+	}
+
+	list := result.Requests[0]
+	if list.Name != "List pets" || list.Method != models.MethodGet || list.URL != "{{baseUrl}}/pets" {
+		t.Errorf("Unexpected request: %+v", list)
+	}
+	if !reflect.DeepEqual(list.QueryParams, map[string]string{"limit": "10", "sort": "name"}) {
+		t.Errorf("Unexpected query params: %v", list.QueryParams)
+	}
+	if !reflect.DeepEqual(list.Headers, []models.Header{{Key: "Accept", Value: "application/json"}}) {
+		t.Errorf("Unexpected headers: %v", list.Headers)
+	}
+
+	create := result.Requests[1]
+	expectedBody := &models.RequestBody{Mode: models.BodyJSON, Raw: "{\n  \"name\": \"Rex\"\n}"}
+	if !reflect.DeepEqual(create.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", create.BodyConfig)
+	}
+	if create.Auth == nil || create.Auth.Type != models.AuthBearer || create.Auth.Params["token"] != "{{token}}" {
+		t.Errorf("Unexpected auth: %+v", create.Auth)
+	}
+	if create.Settings == nil || create.Settings.ShouldFollowRedirects() || create.Settings.HTTPVersion != models.HTTPVersion2 {
+		t.Errorf("Unexpected settings: %+v", create.Settings)
+	}
+
+	login := result.Requests[2]
+	if login.Name != "login" || login.URL != "http://auth.example.com/login" || login.Headers != nil {
+		t.Errorf("Unexpected request: %+v", login)
+	}
+	if !reflect.DeepEqual(login.Auth, &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "ana", "password": "secret"}}) {
+		t.Errorf("Unexpected auth: %+v", login.Auth)
+	}
+	if login.BodyConfig == nil || login.BodyConfig.Mode != models.BodyURLEncoded || len(login.BodyConfig.URLEncoded) != 2 {
+		t.Errorf("Unexpected body: %+v", login.BodyConfig)
+	}
+
+	upload := result.Requests[3]
+	expectedBody = &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+		{Key: "caption", Value: "Me"},
+		{Key: "file", Type: models.FormFieldFile, FilePath: "./me.png", ContentType: "image/png"},
+	}}
+	if !reflect.DeepEqual(upload.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", upload.BodyConfig)
+	}
+
+	for _, warning := range []models.ImportWarning{
+		{Item: "Create a pet", Message: "Response handler scripts are not supported and were ignored"},
+		{Item: "login", Message: "Dynamic variable {{$uuid}} is not supported, set its value in the environment"},
+		{Item: "Socket", Message: "Method WEBSOCKET is not supported"},
+	} {
+		if !containsWarning(result.Report.Warnings, warning) {
+			t.Errorf("Missing warning %+v in %+v", warning, result.Report.Warnings)
+		}
+	}
+}
+
+func TestImportHTTPFileErrors(t *testing.T) {
+	for _, file := range []string{"", "@baseUrl = https://example.com\n# nothing else"} {
+		if _, err := models.ImportHTTPFile([]byte(file), ""); err == nil {
+			t.Errorf("Expected an error importing %q", file)
+		}
+	}
+}
+
+func TestToHTTPFile(t *testing.T) {
+	env := &models.Environment{Variables: map[string]string{"baseUrl": "https://api.example.com", "user": "ana"}}
+	collection := models.Collection{Name: "Pets", Requests: []models.Request{
+		{
+			Name:        "Search",
+			Method:      models.MethodGet,
+			URL:         "{{baseUrl}}/pets",
+			QueryParams: map[string]string{"q": "{{query}}", "kind": "good dog"},
+			Auth:        &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "{{user}}", "password": "x"}},
+			Settings:    &models.RequestSettings{FollowRedirects: new(bool)},
+		},
+		{Name: "Socket", Type: models.RequestWebSocket, URL: "wss://example.com"},
+		{
+			Name:   "Upload",
+			Method: models.MethodPost,
+			URL:    "{{baseUrl}}/upload",
+			BodyConfig: &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+				{Key: "caption", Value: "Me"},
+				{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/me.png", ContentType: "image/png"},
+			}},
+		},
+	}}
+
+	expected := `@baseUrl = https://api.example.com
+@user = ana
+
+### Search
+# @no-redirect
+GET {{baseUrl}}/pets?kind=good+dog&q={{query}}
+Authorization: Basic {{user}} x
+
+### Upload
+POST {{baseUrl}}/upload
+Content-Type: multipart/form-data; boundary=PumoideFormBoundary
+
+--PumoideFormBoundary
+Content-Disposition: form-data; name="caption"
+
+Me
+--PumoideFormBoundary
+Content-Disposition: form-data; name="file"; filename="me.png"
+Content-Type: image/png
+
+< /tmp/me.png
+--PumoideFormBoundary--
+`
+	if file := collection.ToHTTPFile(env); file != expected {
+		t.Errorf("Unexpected file:\n%s\nwant:\n%s", file, expected)
+	}
+}
+
+func TestHTTPFileRoundTrip(t *testing.T) {
+	collection := models.Collection{Name: "Round trip", Requests: []models.Request{
+		{
+			Name:        "Delete",
+			Method:      models.MethodDelete,
+			URL:         "{{baseUrl}}/pets/7",
+			QueryParams: map[string]string{"force": "yes & no"},
+			Headers:     []models.Header{{Key: "X-Trace", Value: "{{trace}}"}},
+			Settings:    &models.RequestSettings{HTTPVersion: models.HTTPVersion1},
+		},
+		{
+			Name:   "Login",
+			Method: models.MethodPost,
+			URL:    "https://example.com/login",
+			BodyConfig: &models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{
+				{Key: "user", Value: "ana"},
+				{Key: "pass word", Value: "a=b&c"},
+			}},
+			Auth: &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "ana", "password": "x:y"}},
+		},
+		{
+			Name:       "Notes",
+			Method:     models.MethodPut,
+			URL:        "https://example.com/notes",
+			BodyConfig: &models.RequestBody{Mode: models.BodyRaw, Raw: "line one\nline two", ContentType: "text/markdown"},
+			Auth: &models.Auth{Type: models.AuthAWSSigV4, Params: map[string]string{
+				"access_key": "AKIA", "secret_key": "secret", "session_token": "tok", "region": "eu-west-1", "service": "s3",
+			}},
+		},
+		{
+			Name:       "Blob",
+			Method:     models.MethodPatch,
+			URL:        "https://example.com/blob",
+			BodyConfig: &models.RequestBody{Mode: models.BodyBinary, FilePath: "data.bin", ContentType: "application/octet-stream"},
+		},
+	}}
+
+	file := collection.ToHTTPFile(nil)
+	result, err := models.ImportHTTPFile([]byte(file), "")
+	if err != nil {
+		t.Fatalf("Failed to import exported file: %v\n%s", err, file)
+	}
+	if len(result.Requests) != len(collection.Requests) || len(result.Report.Warnings) != 0 {
+		t.Fatalf("Unexpected import: %+v\n%s", result.Report, file)
+	}
+
+	for i, req := range collection.Requests {
+		imported := result.Requests[i]
+		imported.ID = ""
+		if !reflect.DeepEqual(imported, req) {
+			t.Errorf("Request changed in the round trip:\n%+v\nwant:\n%+v\n%s", imported, req, file)
+		}
+	}
+	if !strings.Contains(file, "Authorization: AWS AKIA secret token:tok region:eu-west-1 service:s3") {
+		t.Errorf("Unexpected AWS credentials in:\n%s", file)
+	}
+}
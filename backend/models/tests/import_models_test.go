@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestDetectImportFormat(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected models.ImportFormat
+	}{
+		{`{"info": {"name": "API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"}, "item": []}`, models.ImportFormatPostman},
+		{`{"_type": "export", "__export_format": 4, "resources": []}`, models.ImportFormatInsomnia},
+		{`{"openapi": "3.0.3", "info": {"title": "API"}}`, models.ImportFormatOpenAPI},
+		{"# Pets API\nopenapi: 3.1.0\ninfo:\n  title: API\n", models.ImportFormatOpenAPI},
+		{`{"log": {"version": "1.2", "entries": []}}`, models.ImportFormatHAR},
+		{"meta {\n  name: List\n}\n", models.ImportFormatBruno},
+		{"PK\x03\x04rest of the archive", models.ImportFormatBruno},
+		{"# Requests\n@baseUrl = https://example.com\n", models.ImportFormatHTTPFile},
+		{"GET https://example.com\n", models.ImportFormatHTTPFile},
+		{"### First\nGET https://example.com\n", models.ImportFormatHTTPFile},
+		{"something else", models.ImportFormatPostman},
+	}
+
+	for _, tc := range testCases {
+		if format := models.DetectImportFormat([]byte(tc.data)); format != tc.expected {
+			t.Errorf("Detected %s instead of %s for %q", format, tc.expected, tc.data)
+		}
+	}
+}
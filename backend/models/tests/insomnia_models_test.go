@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestImportInsomniaExport(t *testing.T) {
+	export := `{
+		"_type": "export",
+		"__export_format": 4,
+		"__export_source": "insomnia.desktop.app:v2023.5.8",
+		"resources": [
+			{"_id": "wrk_1", "_type": "workspace", "parentId": null, "name": "Pet Store", "description": "Pets API"},
+			{"_id": "env_base", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment", "data": {"baseUrl": "https://api.example.com", "auth": {"token": "base"}}},
+			{"_id": "env_prod", "_type": "environment", "parentId": "env_base", "name": "Production", "data": {"baseUrl": "https://prod.example.com", "retries": 3}},
+			{"_id": "fld_1", "_type": "request_group", "parentId": "wrk_1", "name": "Pets", "metaSortKey": -2, "environment": {"kind": "dog"}},
+			{"_id": "req_2", "_type": "request", "parentId": "fld_1", "name": "Create pet", "metaSortKey": 2,
+				"method": "POST", "url": "{{ _.baseUrl }}/pets",
+				"body": {"mimeType": "application/json", "text": "{\"kind\": \"{{ _.kind }}\", \"id\": \"{% uuid 'v4' %}\"}"},
+				"headers": [{"name": "Content-Type", "value": "application/json"}, {"name": "X-Debug", "value": "1", "disabled": true}],
+				"authentication": {"type": "bearer", "token": "{{ _.auth.token }}", "prefix": ""},
+				"settingFollowRedirects": "off"},
+			{"_id": "req_1", "_type": "request", "parentId": "fld_1", "name": "List pets", "metaSortKey": 1,
+				"method": "GET", "url": "{{ baseUrl }}/pets?sort=name",
+				"parameters": [{"name": "limit", "value": "10"}, {"name": "offset", "value": "0", "disabled": true}],
+				"authentication": {"type": "apikey", "key": "X-API-Key", "value": "secret", "addTo": "header"}},
+			{"_id": "req_3", "_type": "request", "parentId": "wrk_1", "name": "Upload", "metaSortKey": 5,
+				"method": "PUT", "url": "https://files.example.com/upload",
+				"body": {"mimeType": "multipart/form-data", "params": [{"name": "caption", "value": "Me"}, {"name": "photo", "type": "file", "fileName": "/tmp/me.png"}]},
+				"authentication": {"type": "iam", "accessKeyId": "AKIA", "secretAccessKey": "secret", "region": "eu-west-1", "service": "s3"}},
+			{"_id": "ws_1", "_type": "websocket_request", "parentId": "wrk_1", "name": "Live", "metaSortKey": 6, "url": "wss://example.com"},
+			{"_id": "jar_1", "_type": "cookie_jar", "parentId": "wrk_1", "name": "Default Jar"}
+		]
+	}`
+
+	result, err := models.ImportInsomniaExport([]byte(export))
+	if err != nil {
+		t.Fatalf("Failed to import export: %v", err)
+	}
+
+	if result.Name != "Pet Store" || result.Description != "Pets API" {
+		t.Errorf("Unexpected collection: %s, %s", result.Name, result.Description)
+	}
+	if result.Report.Imported != 3 || result.Report.Skipped != 1 {
+		t.Errorf("Unexpected report: %+v", result.Report)
+	}
+
+	expectedTree := []interface{}{"Pets", []interface{}{"List pets", "Create pet"}, "Upload"}
+	if tree := treeNames(&result.Collection, result.Items); !reflect.DeepEqual(tree, expectedTree) {
+		t.Fatalf("Unexpected folder tree: %v", tree)
+	}
+
+	list := result.Requests[0]
+	if list.URL != "{{baseUrl}}/pets" || !reflect.DeepEqual(list.QueryParams, map[string]string{"sort": "name", "limit": "10"}) {
+		t.Errorf("Unexpected URL: %s %v", list.URL, list.QueryParams)
+	}
+	if !reflect.DeepEqual(list.Auth, &models.Auth{Type: models.AuthAPIKey, Params: map[string]string{"key": "X-API-Key", "value": "secret", "in": "header"}}) {
+		t.Errorf("Unexpected auth: %+v", list.Auth)
+	}
+
+	create := result.Requests[1]
+	expectedBody := &models.RequestBody{Mode: models.BodyJSON, Raw: `{"kind": "{{kind}}", "id": "{% uuid 'v4' %}"}`}
+	if !reflect.DeepEqual(create.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", create.BodyConfig)
+	}
+	if create.Headers != nil {
+		t.Errorf("Unexpected headers: %+v", create.Headers)
+	}
+	if create.Auth == nil || create.Auth.Params["token"] != "{{auth.token}}" {
+		t.Errorf("Unexpected auth: %+v", create.Auth)
+	}
+	if create.Settings == nil || create.Settings.ShouldFollowRedirects() {
+		t.Errorf("Redirects should not be followed: %+v", create.Settings)
+	}
+
+	upload := result.Requests[2]
+	expectedBody = &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+		{Key: "caption", Value: "Me"},
+		{Key: "photo", Type: models.FormFieldFile, FilePath: "/tmp/me.png"},
+	}}
+	if !reflect.DeepEqual(upload.BodyConfig, expectedBody) {
+		t.Errorf("Unexpected body: %+v", upload.BodyConfig)
+	}
+	if upload.Auth == nil || upload.Auth.Type != models.AuthAWSSigV4 || upload.Auth.Params["region"] != "eu-west-1" {
+		t.Errorf("Unexpected auth: %+v", upload.Auth)
+	}
+
+	if len(result.Environments) != 1 {
+		t.Fatalf("Unexpected environments: %+v", result.Environments)
+	}
+	expectedVariables := map[string]string{"baseUrl": "https://prod.example.com", "auth.token": "base", "retries": "3", "kind": "dog"}
+	if env := result.Environments[0]; env.Name != "Production" || !reflect.DeepEqual(env.Variables, expectedVariables) {
+		t.Errorf("Unexpected environment: %s %v", env.Name, env.Variables)
+	}
+
+	for _, warning := range []models.ImportWarning{
+		{Item: "Pets / Create pet", Message: "Template tag uuid is not supported and was left as is"},
+		{Item: "Pets / Create pet", Message: "Disabled header X-Debug was not imported"},
+		{Item: "Pets / List pets", Message: "Disabled query parameter offset was not imported"},
+		{Item: "Live", Message: "Insomnia websocket request resources are not supported"},
+	} {
+		if !containsWarning(result.Report.Warnings, warning) {
+			t.Errorf("Missing warning %+v in %+v", warning, result.Report.Warnings)
+		}
+	}
+}
+
+func TestImportInsomniaWorkspaces(t *testing.T) {
+	export := `{"_type": "export", "__export_format": 4, "resources": [
+		{"_id": "wrk_1", "_type": "workspace", "parentId": null, "name": "Pets"},
+		{"_id": "wrk_2", "_type": "workspace", "parentId": null, "name": "Users"},
+		{"_id": "fld_1", "_type": "request_group", "parentId": "wrk_2", "name": "Admin"},
+		{"_id": "req_1", "_type": "request", "parentId": "wrk_1", "name": "List pets", "method": "GET", "url": "https://example.com/pets"},
+		{"_id": "req_2", "_type": "request", "parentId": "fld_1", "name": "Create user", "method": "POST", "url": "https://example.com/users"}
+	]}`
+
+	result, err := models.ImportInsomniaExport([]byte(export))
+	if err != nil {
+		t.Fatalf("Failed to import export: %v", err)
+	}
+
+	expectedTree := []interface{}{"Pets", []interface{}{"List pets"}, "Users", []interface{}{"Admin", []interface{}{"Create user"}}}
+	if tree := treeNames(&result.Collection, result.Items); !reflect.DeepEqual(tree, expectedTree) {
+		t.Errorf("Each workspace should be imported as a folder: %v", tree)
+	}
+}
+
+func TestImportInsomniaExportErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		export string
+	}{
+		{"invalid JSON", `{"_type": "export"`},
+		{"not an export", `{"info": {"name": "Postman"}}`},
+		{"old format", `{"_type": "export", "__export_format": 3, "resources": []}`},
+		{"no workspace", `{"_type": "export", "__export_format": 4, "resources": []}`},
+	}
+
+	for _, tc := range testCases {
+		if _, err := models.ImportInsomniaExport([]byte(tc.export)); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+const openAPIDocument = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+  description: Pets and their owners
+  version: 1.0.0
+servers:
+  - url: https://{region}.example.com/v1
+    description: Production
+    variables:
+      region:
+        default: eu
+  - url: http://localhost:8080/v1/
+    description: Local
+security:
+  - bearerAuth: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      summary: Get a pet
+      tags: [pets]
+      parameters:
+        - name: fields
+          in: query
+          required: true
+          schema:
+            type: string
+            example: name,tag
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: X-Request-ID
+          in: header
+          required: true
+          schema:
+            type: string
+            format: uuid
+    put:
+      operationId: updatePet
+      tags: [pets]
+      security:
+        - apiKey: []
+      requestBody:
+        $ref: '#/components/requestBodies/Pet'
+  /pets:
+    post:
+      tags: [pets]
+      security: []
+      requestBody:
+        content:
+          application/x-www-form-urlencoded:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+                age:
+                  type: integer
+                  default: 3
+  /owners/{ownerId}/avatar:
+    post:
+      summary: Upload an avatar
+      security:
+        - basicAuth: []
+      parameters:
+        - name: ownerId
+          in: path
+          required: true
+          schema:
+            type: integer
+      requestBody:
+        content:
+          multipart/form-data:
+            schema:
+              type: object
+              properties:
+                caption:
+                  type: string
+                file:
+                  type: string
+                  format: binary
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      required: true
+      schema:
+        type: integer
+      example: 7
+  requestBodies:
+    Pet:
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          properties:
+            id:
+              type: integer
+              format: int64
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          example: Rex
+        tags:
+          type: array
+          items:
+            type: string
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+      properties:
+        email:
+          type: string
+          format: email
+        pets:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+  securitySchemes:
+    bearerAuth:
+      type: http
+      scheme: bearer
+    apiKey:
+      type: apiKey
+      name: X-API-Key
+      in: header
+    basicAuth:
+      type: http
+      scheme: basic
+tags:
+  - name: pets
+    description: Everything about pets
+`
+
+func TestImportOpenAPIDocument(t *testing.T) {
+	result, err := models.ImportOpenAPIDocument([]byte(openAPIDocument))
+	if err != nil {
+		t.Fatalf("Failed to import document: %v", err)
+	}
+
+	if result.Name != "Pet Store" || result.Description != "Pets and their owners" {
+		t.Errorf("Unexpected collection info: %q, %q", result.Name, result.Description)
+	}
+
+	names := make([]string, 0, len(result.Requests))
+	for _, request := range result.Requests {
+		names = append(names, request.Name)
+	}
+	expectedNames := []string{"Get a pet", "updatePet", "POST /pets", "Upload an avatar"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Fatalf("Unexpected requests: %v", names)
+	}
+	expectedTree := []interface{}{"pets", []interface{}{"Get a pet", "updatePet", "POST /pets"}, "Upload an avatar"}
+	if tree := treeNames(&result.Collection, result.Items); !reflect.DeepEqual(tree, expectedTree) {
+		t.Errorf("Unexpected folder tree: %v", tree)
+	}
+	if folder := result.Items[0].Folder; folder.Description != "Everything about pets" {
+		t.Errorf("Tag description was not imported: %q", folder.Description)
+	}
+
+	get := result.Requests[0]
+	if get.Method != models.MethodGet || get.URL != "{{baseUrl}}/pets/{{petId}}" {
+		t.Errorf("Unexpected request: %s %s", get.Method, get.URL)
+	}
+	if !reflect.DeepEqual(get.QueryParams, map[string]string{"fields": "name,tag"}) {
+		t.Errorf("Unexpected query params: %v", get.QueryParams)
+	}
+	expectedHeaders := []models.Header{{Key: "X-Request-ID", Value: "00000000-0000-0000-0000-000000000000"}}
+	if !reflect.DeepEqual(get.Headers, expectedHeaders) {
+		t.Errorf("Unexpected headers: %v", get.Headers)
+	}
+	if get.Auth == nil || get.Auth.Type != models.AuthBearer || get.Auth.Params["token"] != "{{bearerAuth}}" {
+		t.Errorf("Document security was not applied: %+v", get.Auth)
+	}
+
+	update := result.Requests[1]
+	if update.Auth == nil || update.Auth.Type != models.AuthAPIKey || update.Auth.Params["key"] != "X-API-Key" || update.Auth.Params["in"] != "header" {
+		t.Errorf("Operation security was not applied: %+v", update.Auth)
+	}
+	if update.BodyConfig == nil || update.BodyConfig.Mode != models.BodyJSON {
+		t.Fatalf("Unexpected body: %+v", update.BodyConfig)
+	}
+	var pet map[string]interface{}
+	if err := json.Unmarshal([]byte(update.BodyConfig.Raw), &pet); err != nil {
+		t.Fatalf("Body example is not valid JSON: %v", err)
+	}
+	if pet["name"] != "Rex" || pet["id"] != float64(0) || !reflect.DeepEqual(pet["tags"], []interface{}{"string"}) {
+		t.Errorf("Unexpected body example: %s", update.BodyConfig.Raw)
+	}
+	if owner, ok := pet["owner"].(map[string]interface{}); !ok || owner["email"] != "user@example.com" {
+		t.Errorf("Referenced schema was not used in the example: %s", update.BodyConfig.Raw)
+	}
+
+	create := result.Requests[2]
+	if create.Auth != nil {
+		t.Errorf("Empty operation security should disable auth, got %+v", create.Auth)
+	}
+	expectedFields := []models.FormField{{Key: "age", Value: "3"}, {Key: "name", Value: "string"}}
+	if create.BodyConfig == nil || create.BodyConfig.Mode != models.BodyURLEncoded || !reflect.DeepEqual(create.BodyConfig.URLEncoded, expectedFields) {
+		t.Errorf("Unexpected form body: %+v", create.BodyConfig)
+	}
+
+	upload := result.Requests[3]
+	if upload.Auth == nil || upload.Auth.Type != models.AuthBasic || upload.Auth.Params["username"] != "{{username}}" {
+		t.Errorf("Unexpected auth: %+v", upload.Auth)
+	}
+	if upload.BodyConfig == nil || !reflect.DeepEqual(upload.BodyConfig.FormData, []models.FormField{{Key: "caption", Value: "string"}}) {
+		t.Errorf("Unexpected multipart body: %+v", upload.BodyConfig)
+	}
+	if !containsWarning(result.Report.Warnings, models.ImportWarning{Item: "Upload an avatar", Message: "File field file needs a file, it was not imported"}) {
+		t.Errorf("Missing warning about the file field: %+v", result.Report.Warnings)
+	}
+
+	if len(result.Environments) != 2 {
+		t.Fatalf("Expected an environment per server, got %d", len(result.Environments))
+	}
+	production := result.Environments[0]
+	if production.Name != "Pet Store (Production)" {
+		t.Errorf("Unexpected environment name: %s", production.Name)
+	}
+	expectedVariables := map[string]string{
+		"baseUrl":    "https://eu.example.com/v1",
+		"petId":      "7",
+		"ownerId":    "0",
+		"bearerAuth": "",
+		"apiKey":     "",
+		"username":   "",
+		"password":   "",
+	}
+	if !reflect.DeepEqual(production.Variables, expectedVariables) {
+		t.Errorf("Unexpected variables: %v", production.Variables)
+	}
+	if local := result.Environments[1]; local.Variables["baseUrl"] != "http://localhost:8080/v1" {
+		t.Errorf("Unexpected local base URL: %s", local.Variables["baseUrl"])
+	}
+}
+
+func TestImportOpenAPI31Document(t *testing.T) {
+	document := `{
+		"openapi": "3.1.0",
+		"info": {"title": "Notes", "version": "1"},
+		"paths": {
+			"/notes": {
+				"post": {
+					"operationId": "createNote",
+					"requestBody": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"properties": {
+							"text": {"type": "string", "examples": ["Buy milk"]},
+							"archived": {"type": ["boolean", "null"]}
+						}
+					}}}}
+				}
+			}
+		}
+	}`
+
+	result, err := models.ImportOpenAPIDocument([]byte(document))
+	if err != nil {
+		t.Fatalf("Failed to import document: %v", err)
+	}
+	if len(result.Requests) != 1 {
+		t.Fatalf("Expected a request, got %d", len(result.Requests))
+	}
+
+	var note map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Requests[0].BodyConfig.Raw), &note); err != nil {
+		t.Fatalf("Body example is not valid JSON: %v", err)
+	}
+	if note["text"] != "Buy milk" || note["archived"] != true {
+		t.Errorf("Unexpected body example: %v", note)
+	}
+
+	if len(result.Environments) != 1 || result.Environments[0].Variables["baseUrl"] != "" {
+		t.Errorf("Expected an environment with an empty base URL: %+v", result.Environments)
+	}
+}
+
+func TestImportOpenAPIDocumentErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{"invalid YAML", "openapi: [3.0"},
+		{"swagger 2", `{"swagger": "2.0", "info": {"title": "Old"}, "paths": {}}`},
+		{"not OpenAPI", `{"info": {"name": "Postman"}, "item": []}`},
+		{"empty", ""},
+	}
+
+	for _, tc := range testCases {
+		if _, err := models.ImportOpenAPIDocument([]byte(tc.data)); err == nil {
+			t.Errorf("%s: expected an import error", tc.name)
+		}
+	}
+}
+
+func TestToOpenAPIDocument(t *testing.T) {
+	collection := models.Collection{
+		Name:        "Pet Store",
+		Description: "Pets and their owners",
+		Requests: []models.Request{
+			{
+				ID:          "get",
+				Name:        "Get a pet",
+				Method:      models.MethodGet,
+				URL:         "{{baseUrl}}/pets/{{petId}}?fields=name",
+				QueryParams: map[string]string{"limit": "10"},
+				Headers: []models.Header{
+					{Key: "X-Request-ID", Value: "42"},
+					{Key: "Accept", Value: "application/json"},
+				},
+				Auth: &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "{{token}}"}},
+			},
+			{
+				ID:         "create",
+				Name:       "Create a pet",
+				Method:     models.MethodPost,
+				URL:        "{{baseUrl}}/pets",
+				BodyConfig: &models.RequestBody{Mode: models.BodyJSON, Raw: `{"name": "Rex", "age": 3, "tags": ["good"]}`},
+				Auth:       &models.Auth{Type: models.AuthNone},
+			},
+			{
+				ID:     "upload",
+				Name:   "Upload an avatar",
+				Method: models.MethodPost,
+				URL:    "https://files.example.com/owners/{{ownerId}}/avatar",
+				BodyConfig: &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+					{Key: "caption", Value: "Me"},
+					{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/me.png"},
+				}},
+				Auth: &models.Auth{Type: models.AuthAPIKey, Params: map[string]string{"key": "X-API-Key", "value": "secret", "in": "header"}},
+			},
+			{ID: "duplicate", Name: "Get a pet again", Method: models.MethodGet, URL: "{{baseUrl}}/pets/{{petId}}"},
+			{ID: "socket", Name: "Live", Type: models.RequestWebSocket, URL: "wss://example.com/live"},
+		},
+		// Tags come from the top-level folders, names are never split
+		Items: []models.CollectionItem{
+			{Folder: &models.Folder{ID: "pets", Name: "pets", Description: "Pet endpoints", Items: []models.CollectionItem{
+				{RequestID: "get"},
+				{Folder: &models.Folder{ID: "admin", Name: "admin", Items: []models.CollectionItem{{RequestID: "create"}}}},
+			}}},
+			{RequestID: "upload"},
+			{RequestID: "duplicate"},
+			{RequestID: "socket"},
+		},
+	}
+	env := &models.Environment{Variables: map[string]string{"baseUrl": "https://api.example.com", "petId": "7"}}
+	responses := map[string][]models.ExecutionResponse{
+		"get": {
+			{StatusCode: 200, Headers: []models.Header{{Key: "Content-Type", Value: "application/json; charset=utf-8"}}, Body: `{"id": 7, "name": "Rex"}`, Encoding: models.EncodingText},
+			{StatusCode: 200, Headers: []models.Header{{Key: "Content-Type", Value: "application/json"}}, Body: `{"id": 1}`, Encoding: models.EncodingText},
+			{StatusCode: 404, Headers: []models.Header{{Key: "Content-Type", Value: "text/plain"}}, Body: "Not found", Encoding: models.EncodingText},
+		},
+	}
+
+	document := collection.ToOpenAPIDocument(env, responses)
+
+	if document.OpenAPI != models.OpenAPIVersion || document.Info.Title != "Pet Store" {
+		t.Errorf("Unexpected document info: %s %+v", document.OpenAPI, document.Info)
+	}
+	expectedServers := []models.OpenAPIServer{{URL: "{baseUrl}", Variables: map[string]models.OpenAPIServerVariable{"baseUrl": {Default: "https://api.example.com"}}}}
+	if !reflect.DeepEqual(document.Servers, expectedServers) {
+		t.Errorf("Unexpected servers: %+v", document.Servers)
+	}
+	expectedPaths := []string{"/pets/{petId}", "/pets", "/owners/{ownerId}/avatar"}
+	if !reflect.DeepEqual(document.Paths.Keys, expectedPaths) {
+		t.Fatalf("Unexpected paths: %v", document.Paths.Keys)
+	}
+
+	get := document.Paths.Items["/pets/{petId}"].Get
+	if get == nil || get.OperationID != "petsGetAPet" || get.Summary != "Get a pet" || !reflect.DeepEqual(get.Tags, []string{"pets"}) {
+		t.Fatalf("Unexpected operation: %+v", get)
+	}
+	if create := document.Paths.Items["/pets"].Post; create == nil || !reflect.DeepEqual(create.Tags, []string{"pets"}) {
+		t.Errorf("Requests of subfolders should be tagged with their top-level folder: %+v", create)
+	}
+	if upload := document.Paths.Items["/owners/{ownerId}/avatar"].Post; upload == nil || upload.Tags != nil {
+		t.Errorf("Requests at the root should have no tag: %+v", upload)
+	}
+	if !reflect.DeepEqual(document.Tags, []models.OpenAPITag{{Name: "pets", Description: "Pet endpoints"}}) {
+		t.Errorf("Unexpected tags: %+v", document.Tags)
+	}
+	var parameters []string
+	for _, parameter := range get.Parameters {
+		parameters = append(parameters, parameter.In+":"+parameter.Name+"="+fmt.Sprint(parameter.Example))
+	}
+	expectedParameters := []string{"path:petId=7", "query:fields=name", "query:limit=10", "header:X-Request-ID=42"}
+	if !reflect.DeepEqual(parameters, expectedParameters) {
+		t.Errorf("Unexpected parameters: %v", parameters)
+	}
+	if get.Security == nil || !reflect.DeepEqual(*get.Security, []map[string][]string{{"bearerAuth": {}}}) {
+		t.Errorf("Unexpected security: %v", get.Security)
+	}
+	ok := get.Responses["200"]
+	if ok.Description != "OK" || string(ok.Content["application/json"].Example.(json.RawMessage)) != `{"id": 7, "name": "Rex"}` {
+		t.Errorf("The newest response should be used: %+v", ok)
+	}
+	if schema := ok.Content["application/json"].Schema; schema == nil || schema.Properties["id"].Type[0] != "integer" {
+		t.Errorf("Unexpected response schema: %+v", schema)
+	}
+	if notFound := get.Responses["404"]; notFound.Content["text/plain"].Example != "Not found" {
+		t.Errorf("Unexpected not found response: %+v", notFound)
+	}
+
+	create := document.Paths.Items["/pets"].Post
+	if create.Security == nil || len(*create.Security) != 0 {
+		t.Errorf("Requests without auth should have an empty security: %v", create.Security)
+	}
+	if _, ok := create.Responses["default"]; !ok {
+		t.Errorf("Expected a default response: %+v", create.Responses)
+	}
+	body := create.RequestBody.Content["application/json"]
+	if body.Schema.Properties["tags"].Items.Type[0] != "string" || body.Schema.Properties["age"].Type[0] != "integer" {
+		t.Errorf("Unexpected body schema: %+v", body.Schema)
+	}
+
+	upload := document.Paths.Items["/owners/{ownerId}/avatar"].Post
+	if !reflect.DeepEqual(upload.Servers, []models.OpenAPIServer{{URL: "https://files.example.com"}}) {
+		t.Errorf("Requests to other servers should declare them: %+v", upload.Servers)
+	}
+	form := upload.RequestBody.Content["multipart/form-data"]
+	if form.Schema.Properties["file"].Format != "binary" || !reflect.DeepEqual(form.Example, map[string]interface{}{"caption": "Me"}) {
+		t.Errorf("Unexpected form body: %+v", form)
+	}
+	apiKey := document.Components.SecuritySchemes["apiKeyAuth"]
+	if apiKey.Type != "apiKey" || apiKey.Name != "X-API-Key" || apiKey.In != "header" {
+		t.Errorf("Unexpected security scheme: %+v", apiKey)
+	}
+
+	// The document can be imported back
+	data, err := document.ToYAML()
+	if err != nil {
+		t.Fatalf("Failed to write YAML: %v", err)
+	}
+	result, err := models.ImportOpenAPIDocument(data)
+	if err != nil {
+		t.Fatalf("Failed to import exported document: %v\n%s", err, data)
+	}
+	if len(result.Requests) != 3 || result.Requests[1].URL != "{{baseUrl}}/pets" {
+		t.Errorf("Unexpected imported requests: %+v", result.Requests)
+	}
+	if result.Environments[0].Variables["baseUrl"] != "https://api.example.com" {
+		t.Errorf("Unexpected imported base URL: %v", result.Environments[0].Variables)
+	}
+}
@@ -0,0 +1,490 @@
+package tests
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+const postmanCollection = `{
+	"info": {
+		"name": "Users API",
+		"description": {"content": "Manage users", "type": "text/markdown"},
+		"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	},
+	"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+	"variable": [
+		{"key": "baseUrl", "value": "https://api.example.com"},
+		{"key": "retries", "value": 3},
+		{"key": "old", "value": "x", "disabled": true}
+	],
+	"item": [
+		{
+			"name": "Users",
+			"item": [
+				{
+					"name": "Get user",
+					"request": {
+						"method": "GET",
+						"header": [
+							{"key": "Accept", "value": "application/json"},
+							{"key": "X-Debug", "value": "1", "disabled": true}
+						],
+						"url": {
+							"raw": "{{baseUrl}}/users/:id?expand=roles&q=a%20b+c",
+							"host": ["{{baseUrl}}"],
+							"path": ["users", ":id"],
+							"query": [
+								{"key": "expand", "value": "roles"},
+								{"key": "q", "value": "a%20b+c"},
+								{"key": "page", "value": "2", "disabled": true}
+							],
+							"variable": [{"key": "id", "value": "42"}]
+						}
+					},
+					"response": [{"name": "Example"}]
+				},
+				{
+					"name": "Admin",
+					"auth": {"type": "basic", "basic": {"username": "admin", "password": "secret"}},
+					"item": [
+						{
+							"name": "Create user",
+							"request": {
+								"method": "POST",
+								"url": "{{baseUrl}}/users?notify=true",
+								"body": {"mode": "raw", "raw": "{\"name\": \"{{name}}\"}", "options": {"raw": {"language": "json"}}}
+							}
+						}
+					]
+				}
+			]
+		},
+		{
+			"name": "Login",
+			"request": {
+				"method": "POST",
+				"auth": {"type": "noauth"},
+				"url": {"protocol": "https", "host": ["auth", "example", "com"], "path": ["login"]},
+				"body": {"mode": "urlencoded", "urlencoded": [
+					{"key": "user", "value": "me"},
+					{"key": "remember", "value": "true", "disabled": true}
+				]}
+			},
+			"event": [{"listen": "test", "script": {"exec": ["pm.test()"]}}]
+		},
+		{
+			"name": "Upload",
+			"request": {
+				"method": "PUT",
+				"auth": {"type": "hawk", "hawk": []},
+				"url": "{{baseUrl}}/files",
+				"body": {"mode": "formdata", "formdata": [
+					{"key": "title", "value": "report", "type": "text"},
+					{"key": "file", "type": "file", "src": ["/tmp/a.pdf", "/tmp/b.pdf"]},
+					{"key": "empty", "type": "file", "src": null}
+				]}
+			}
+		},
+		{
+			"name": "Bind",
+			"request": {"method": "LINK", "url": "{{baseUrl}}/bind"}
+		},
+		{
+			"name": "Ping",
+			"request": "https://example.com/ping"
+		}
+	]
+}`
+
+func TestImportPostmanCollection(t *testing.T) {
+	result, err := models.ImportPostmanCollection([]byte(postmanCollection))
+	if err != nil {
+		t.Fatalf("Failed to import collection: %v", err)
+	}
+
+	if result.Name != "Users API" || result.Description != "Manage users" {
+		t.Errorf("Unexpected collection info: %q, %q", result.Name, result.Description)
+	}
+
+	if result.Report.Imported != 5 || result.Report.Skipped != 1 {
+		t.Errorf("Unexpected report counts: imported %d, skipped %d", result.Report.Imported, result.Report.Skipped)
+	}
+
+	requests := make(map[string]models.Request)
+	for _, request := range result.Requests {
+		requests[request.Name] = request
+	}
+	if len(requests) != 5 {
+		t.Fatalf("Expected 5 requests, got %d", len(result.Requests))
+	}
+
+	expectedTree := []interface{}{"Users", []interface{}{"Get user", "Admin", []interface{}{"Create user"}}, "Login", "Upload", "Ping"}
+	if tree := treeNames(&result.Collection, result.Items); !reflect.DeepEqual(tree, expectedTree) {
+		t.Errorf("Unexpected folder tree: %v", tree)
+	}
+
+	getUser := requests["Get user"]
+	if getUser.URL != "{{baseUrl}}/users/42" {
+		t.Errorf("Unexpected URL: %s", getUser.URL)
+	}
+	if !reflect.DeepEqual(getUser.QueryParams, map[string]string{"expand": "roles", "q": "a b+c"}) {
+		t.Errorf("Unexpected query params, a + should be kept: %v", getUser.QueryParams)
+	}
+	if !reflect.DeepEqual(getUser.Headers, []models.Header{{Key: "Accept", Value: "application/json"}}) {
+		t.Errorf("Unexpected headers: %v", getUser.Headers)
+	}
+	if getUser.Auth == nil || getUser.Auth.Type != models.AuthBearer || getUser.Auth.Params["token"] != "{{token}}" {
+		t.Errorf("Collection auth was not inherited: %+v", getUser.Auth)
+	}
+
+	create := requests["Create user"]
+	if create.Auth == nil || create.Auth.Type != models.AuthBasic || create.Auth.Params["password"] != "secret" {
+		t.Errorf("Folder auth was not inherited: %+v", create.Auth)
+	}
+	if create.URL != "{{baseUrl}}/users" || create.QueryParams["notify"] != "true" {
+		t.Errorf("Query was not split from a string URL: %s %v", create.URL, create.QueryParams)
+	}
+	if create.BodyConfig == nil || create.BodyConfig.Mode != models.BodyJSON || create.BodyConfig.Raw != `{"name": "{{name}}"}` {
+		t.Errorf("Unexpected JSON body: %+v", create.BodyConfig)
+	}
+
+	login := requests["Login"]
+	if login.URL != "https://auth.example.com/login" {
+		t.Errorf("URL was not built from its parts: %s", login.URL)
+	}
+	if login.Auth == nil || login.Auth.Type != models.AuthNone {
+		t.Errorf("Expected noauth to override the collection auth: %+v", login.Auth)
+	}
+	expectedFields := []models.FormField{{Key: "user", Value: "me"}, {Key: "remember", Value: "true", Disabled: true}}
+	if login.BodyConfig == nil || !reflect.DeepEqual(login.BodyConfig.URLEncoded, expectedFields) {
+		t.Errorf("Unexpected URL-encoded body: %+v", login.BodyConfig)
+	}
+
+	upload := requests["Upload"]
+	if upload.Auth != nil {
+		t.Errorf("Unsupported auth should be dropped, got %+v", upload.Auth)
+	}
+	expectedFields = []models.FormField{
+		{Key: "title", Value: "report"},
+		{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/a.pdf"},
+	}
+	if upload.BodyConfig == nil || !reflect.DeepEqual(upload.BodyConfig.FormData, expectedFields) {
+		t.Errorf("Unexpected form data body: %+v", upload.BodyConfig)
+	}
+
+	if ping := requests["Ping"]; ping.Method != models.MethodGet || ping.URL != "https://example.com/ping" {
+		t.Errorf("Unexpected request from a string: %s %s", ping.Method, ping.URL)
+	}
+
+	if len(result.Environments) != 1 {
+		t.Fatalf("Collection variables were not imported")
+	}
+	expectedVariables := map[string]string{"baseUrl": "https://api.example.com", "retries": "3"}
+	if !reflect.DeepEqual(result.Environments[0].Variables, expectedVariables) {
+		t.Errorf("Unexpected variables: %v", result.Environments[0].Variables)
+	}
+
+	for _, expected := range []models.ImportWarning{
+		{Item: "Users / Get user", Message: "Disabled header X-Debug was not imported"},
+		{Item: "Users / Get user", Message: "Disabled query parameter page was not imported"},
+		{Item: "Users / Get user", Message: "1 saved example responses were not imported"},
+		{Item: "Login", Message: "Request scripts are not supported and were not imported"},
+		{Item: "Upload", Message: "File field file has 2 files, only the first one was imported"},
+		{Item: "Upload", Message: "File field empty has no file and was not imported"},
+		{Item: "Bind", Message: "Unsupported HTTP method LINK"},
+		{Message: "Disabled collection variable old was not imported"},
+	} {
+		if !containsWarning(result.Report.Warnings, expected) {
+			t.Errorf("Missing warning %+v in %+v", expected, result.Report.Warnings)
+		}
+	}
+}
+
+func TestImportPostmanCollectionErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{"invalid JSON", `{"info": `},
+		{"v1 collection", `{"id": "1", "name": "Old", "requests": []}`},
+		{"unsupported schema", `{"info": {"name": "C", "schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}, "item": []}`},
+		{"missing name", `{"info": {}, "item": []}`},
+	}
+
+	for _, tc := range testCases {
+		if _, err := models.ImportPostmanCollection([]byte(tc.data)); err == nil {
+			t.Errorf("%s: expected an import error", tc.name)
+		}
+	}
+}
+
+func containsWarning(warnings []models.ImportWarning, expected models.ImportWarning) bool {
+	for _, warning := range warnings {
+		if warning == expected {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPostmanRoundTrip(t *testing.T) {
+	followRedirects, maxRedirects := false, 3
+	collection := models.Collection{
+		ID:          "collection",
+		Name:        "Round trip",
+		Description: "Every kind of request",
+		Requests: []models.Request{
+			{
+				ID: "get", Name: "Users / Get user", Method: models.MethodGet,
+				URL:         "{{baseUrl}}/users/42",
+				Headers:     []models.Header{{Key: "Accept", Value: "application/json"}},
+				QueryParams: map[string]string{"expand": "roles", "filter": "a+b=c&d", "token": "{{token}}"},
+				Auth:        &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "{{token}}"}},
+			},
+			{
+				ID: "create", Name: "Create user", Method: models.MethodPost,
+				URL:        "https://api.example.com:8443/users",
+				BodyConfig: &models.RequestBody{Mode: models.BodyJSON, Raw: `{"name": "{{name}}"}`},
+				Auth:       &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "admin", "password": "secret"}},
+				Settings:   &models.RequestSettings{FollowRedirects: &followRedirects, MaxRedirects: &maxRedirects, SkipTLSVerify: true},
+			},
+			{
+				ID: "xml", Name: "Export", Method: models.MethodPut,
+				URL:        "https://api.example.com/users/export",
+				BodyConfig: &models.RequestBody{Mode: models.BodyRaw, Raw: "<users/>", ContentType: "application/xml"},
+				Auth: &models.Auth{Type: models.AuthAPIKey, Params: map[string]string{
+					"key": "X-API-Key", "value": "{{apiKey}}", "in": "query",
+				}},
+				Settings: &models.RequestSettings{TimeoutMs: 5000, HTTPVersion: models.HTTPVersion2},
+			},
+			{
+				ID: "binary", Name: "Avatar", Method: models.MethodPut,
+				URL:        "https://api.example.com/avatar",
+				BodyConfig: &models.RequestBody{Mode: models.BodyBinary, FilePath: "/tmp/avatar.png"},
+				Auth: &models.Auth{Type: models.AuthDigest, Params: map[string]string{
+					"username": "u", "password": "p", "realm": "r", "nonce": "n", "qop": "auth", "nc": "00000001", "cnonce": "c",
+				}},
+			},
+			{
+				ID: "socket", Name: "Live", Type: models.RequestWebSocket,
+				URL: "wss://api.example.com/live",
+				Messages: []models.WebSocketMessage{
+					{Direction: models.MessageSent, Type: models.WebSocketText, Data: "hello", Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+				},
+			},
+			{
+				ID: "login", Name: "Login", Method: models.MethodPost,
+				URL:         "https://auth.example.com/login",
+				QueryParams: map[string]string{"client": "web"},
+				BodyConfig: &models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{
+					{Key: "user", Value: "me"},
+					{Key: "remember", Value: "true", Disabled: true},
+				}},
+				Auth: &models.Auth{Type: models.AuthNone, Params: map[string]string{}},
+			},
+			{
+				ID: "upload", Name: "Upload", Method: models.MethodPost,
+				URL:         "https://api.example.com/files#top",
+				QueryParams: map[string]string{"folder": "reports"},
+				BodyConfig: &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+					{Key: "title", Value: "report"},
+					{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/report.pdf", ContentType: "application/pdf"},
+				}},
+				Auth: &models.Auth{Type: models.AuthAWSSigV4, Params: map[string]string{
+					"access_key": "AK", "secret_key": "SK", "session_token": "", "region": "us-east-1", "service": "s3",
+				}},
+			},
+			{
+				ID: "graphql", Name: "Search", Method: models.MethodPost,
+				URL: "https://api.example.com/graphql",
+				BodyConfig: &models.RequestBody{Mode: models.BodyGraphQL, GraphQL: &models.GraphQLBody{
+					Query: "query Users { users { id } }", Variables: `{"first": 10}`, OperationName: "Users",
+				}},
+				Auth: &models.Auth{Type: models.AuthOAuth2, Params: map[string]string{"access_token": "{{accessToken}}", "token_type": "Bearer"}},
+			},
+			{
+				ID: "legacy", Name: "Legacy", Method: models.MethodPost,
+				URL:  "https://api.example.com/legacy",
+				Body: "plain body",
+			},
+			{
+				ID: "grpc", Name: "Greet", Type: models.RequestGRPC,
+				URL:  "grpc://localhost:50051",
+				GRPC: &models.GRPCRequest{Source: models.GRPCSourceReflection, Service: "helloworld.Greeter", Method: "SayHello", Message: `{"name": "me"}`},
+			},
+		},
+		// Names holding " / " are kept as they are inside their folder
+		Items: []models.CollectionItem{
+			{Folder: &models.Folder{ID: "users", Name: "Users", Description: "User endpoints", Items: []models.CollectionItem{
+				{RequestID: "get"},
+				{Folder: &models.Folder{ID: "admin", Name: "Admin", Items: []models.CollectionItem{{RequestID: "create"}}}},
+				{RequestID: "xml"},
+				{RequestID: "binary"},
+				{RequestID: "socket"},
+			}}},
+			{RequestID: "login"},
+			{RequestID: "upload"},
+			{RequestID: "graphql"},
+			{Folder: &models.Folder{ID: "empty", Name: "Empty", Items: []models.CollectionItem{}}},
+			{RequestID: "legacy"},
+			{RequestID: "grpc"},
+		},
+	}
+
+	data, err := json.Marshal(collection.ToPostmanCollection())
+	if err != nil {
+		t.Fatalf("Failed to marshal exported collection: %v", err)
+	}
+
+	// A legacy body string is exported as a raw body
+	expected := collection
+	expected.Requests = append([]models.Request(nil), collection.Requests...)
+	legacy := &expected.Requests[8]
+	legacy.Body, legacy.BodyConfig = "", &models.RequestBody{Mode: models.BodyRaw, Raw: "plain body"}
+	assertPostmanRoundTrip(t, data, expected)
+
+	// Without the extension only the other request types and the timeout are lost
+	expected.Requests = append([]models.Request(nil), expected.Requests...)
+	expected.Requests[2].Settings = &models.RequestSettings{HTTPVersion: models.HTTPVersion2}
+	for _, req := range []*models.Request{&expected.Requests[4], &expected.Requests[9]} {
+		req.Type, req.Method, req.GRPC, req.Messages = "", models.MethodGet, nil, nil
+	}
+	assertPostmanRoundTrip(t, stripPostmanExtension(t, data), expected)
+}
+
+func assertPostmanRoundTrip(t *testing.T, data []byte, expected models.Collection) {
+	t.Helper()
+	result, err := models.ImportPostmanCollection(data)
+	if err != nil {
+		t.Fatalf("Failed to import exported collection: %v", err)
+	}
+
+	imported := result.Collection
+	imported.ID = expected.ID
+	if !reflect.DeepEqual(imported, expected) {
+		want, _ := json.MarshalIndent(expected, "", "  ")
+		got, _ := json.MarshalIndent(imported, "", "  ")
+		t.Errorf("Round trip changed the collection:\nexpected %s\ngot %s", want, got)
+	}
+	if result.Report.Skipped != 0 {
+		t.Errorf("Round trip skipped requests: %+v", result.Report.Warnings)
+	}
+}
+
+func TestToPostmanCollection(t *testing.T) {
+	collection := models.Collection{
+		Name: "Export",
+		Requests: []models.Request{
+			{ID: "1", Name: "Users / List", Method: models.MethodGet, URL: "https://api.example.com/users",
+				QueryParams: map[string]string{"page": "2"},
+				Auth:        &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "secret"}}},
+			{ID: "2", Name: "Create", Method: models.MethodPost, URL: "https://api.example.com/users",
+				BodyConfig: &models.RequestBody{Mode: models.BodyJSON, Raw: `{"name": "me"}`}},
+			{ID: "3", Name: "Admin / Health", Method: models.MethodGet, URL: "https://api.example.com/health"},
+		},
+		Items: []models.CollectionItem{
+			{Folder: &models.Folder{ID: "users", Name: "Users", Items: []models.CollectionItem{{RequestID: "1"}, {RequestID: "2"}}}},
+		},
+	}
+
+	data, err := json.Marshal(collection.ToPostmanCollection())
+	if err != nil {
+		t.Fatalf("Failed to marshal exported collection: %v", err)
+	}
+
+	var exported struct {
+		Info struct {
+			Schema string `json:"schema"`
+		} `json:"info"`
+		Item []struct {
+			Name string `json:"name"`
+			Item []struct {
+				Name    string `json:"name"`
+				Request struct {
+					URL struct {
+						Raw   string              `json:"raw"`
+						Host  []string            `json:"host"`
+						Path  []string            `json:"path"`
+						Query []map[string]string `json:"query"`
+					} `json:"url"`
+					Auth map[string]json.RawMessage `json:"auth"`
+					Body struct {
+						Mode    string `json:"mode"`
+						Options struct {
+							Raw struct {
+								Language string `json:"language"`
+							} `json:"raw"`
+						} `json:"options"`
+					} `json:"body"`
+				} `json:"request"`
+				Pumoide json.RawMessage `json:"pumoide"`
+			} `json:"item"`
+		} `json:"item"`
+	}
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("Failed to unmarshal exported collection: %v", err)
+	}
+
+	if exported.Info.Schema != models.PostmanSchema {
+		t.Errorf("Unexpected schema: %s", exported.Info.Schema)
+	}
+	// Request names are never split into folders
+	if len(exported.Item) != 2 || exported.Item[0].Name != "Users" || len(exported.Item[0].Item) != 2 || exported.Item[1].Name != "Admin / Health" {
+		t.Fatalf("Unexpected exported tree: %s", data)
+	}
+
+	list := exported.Item[0].Item[0]
+	if list.Name != "Users / List" || list.Request.URL.Raw != "https://api.example.com/users?page=2" {
+		t.Errorf("Unexpected exported request: %s %s", list.Name, list.Request.URL.Raw)
+	}
+	if !reflect.DeepEqual(list.Request.URL.Host, []string{"api", "example", "com"}) || !reflect.DeepEqual(list.Request.URL.Path, []string{"users"}) {
+		t.Errorf("Unexpected URL parts: %v %v", list.Request.URL.Host, list.Request.URL.Path)
+	}
+	if len(list.Request.URL.Query) != 1 || list.Request.URL.Query[0]["key"] != "page" || list.Request.URL.Query[0]["value"] != "2" {
+		t.Errorf("Unexpected query: %v", list.Request.URL.Query)
+	}
+	if string(list.Request.Auth["type"]) != `"bearer"` || string(list.Request.Auth["bearer"]) != `[{"key":"token","value":"secret","type":"string"}]` {
+		t.Errorf("Unexpected auth: %v", list.Request.Auth)
+	}
+	if list.Pumoide != nil {
+		t.Errorf("Requests Postman can represent shouldn't need an extension: %s", list.Pumoide)
+	}
+
+	create := exported.Item[0].Item[1]
+	if create.Request.Body.Mode != "raw" || create.Request.Body.Options.Raw.Language != "json" {
+		t.Errorf("Unexpected body: %+v", create.Request.Body)
+	}
+}
+
+// stripPostmanExtension removes the pumoide keys from an exported collection, leaving what Postman reads.
+func stripPostmanExtension(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("Failed to unmarshal exported collection: %v", err)
+	}
+	var strip func(value interface{})
+	strip = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			delete(value, "pumoide")
+			for _, child := range value {
+				strip(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				strip(child)
+			}
+		}
+	}
+	strip(document)
+	stripped, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("Failed to marshal stripped collection: %v", err)
+	}
+	return stripped
+}
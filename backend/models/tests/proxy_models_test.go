@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestProxyBypasses(t *testing.T) {
+	proxy := models.ProxySettings{
+		URL:     "http://proxy.example:3128",
+		NoProxy: []string{"localhost", ".internal", "*.corp.example", "10.0.0.0/8"},
+	}
+
+	cases := map[string]bool{
+		"localhost":           true,
+		"billing.internal":    true,
+		"internal":            true,
+		"api.corp.example":    true,
+		"10.1.2.3":            true,
+		"192.168.1.1":         false,
+		"api.example.com":     false,
+		"notinternal.example": false,
+	}
+
+	for host, want := range cases {
+		if got := proxy.Bypasses(host); got != want {
+			t.Errorf("Bypasses(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+	proxy := &models.ProxySettings{URL: "socks5://proxy.example:1080", Username: "user", Password: "secret", NoProxy: []string{"localhost"}}
+
+	target, _ := url.Parse("https://api.example.com/users")
+	proxyURL, err := proxy.ProxyURL(target)
+	if err != nil {
+		t.Fatalf("ProxyURL failed: %v", err)
+	}
+	if proxyURL == nil || proxyURL.Scheme != "socks5" || proxyURL.User.Username() != "user" {
+		t.Errorf("Unexpected proxy URL: %v", proxyURL)
+	}
+
+	local, _ := url.Parse("http://localhost:8080")
+	if proxyURL, _ := proxy.ProxyURL(local); proxyURL != nil {
+		t.Errorf("No-proxy host should connect directly, got %v", proxyURL)
+	}
+
+	var none *models.ProxySettings
+	if proxyURL, _ := none.ProxyURL(target); proxyURL != nil {
+		t.Errorf("Nil settings should connect directly, got %v", proxyURL)
+	}
+}
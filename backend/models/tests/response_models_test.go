@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestIsTextContentType(t *testing.T) {
+	textTypes := []string{"text/plain", "text/html; charset=utf-8", "application/json", "application/problem+json", "application/atom+xml"}
+	for _, contentType := range textTypes {
+		if !models.IsTextContentType(contentType) {
+			t.Errorf("Content type %s should be text", contentType)
+		}
+	}
+
+	binaryTypes := []string{"image/png", "application/pdf", "application/octet-stream", "application/grpc", ""}
+	for _, contentType := range binaryTypes {
+		if models.IsTextContentType(contentType) {
+			t.Errorf("Content type %s should not be text", contentType)
+		}
+	}
+}
+
+func TestEncodeBody(t *testing.T) {
+	body, encoding := models.EncodeBody("application/json", []byte(`{"a":1}`))
+	if encoding != models.EncodingText || body != `{"a":1}` {
+		t.Errorf("JSON body should be kept as text: got %s (%s)", body, encoding)
+	}
+
+	body, encoding = models.EncodeBody("", []byte("plain text"))
+	if encoding != models.EncodingText {
+		t.Errorf("Sniffed text body should be kept as text: got %s", encoding)
+	}
+
+	binary := []byte{0x00, 0x01, 0xff}
+	body, encoding = models.EncodeBody("text/plain", binary)
+	if encoding != models.EncodingBase64 {
+		t.Errorf("Invalid UTF-8 body should be base64 encoded: got %s", encoding)
+	}
+
+	decoded, err := models.DecodeBody(body, encoding)
+	if err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if !bytes.Equal(decoded, binary) {
+		t.Errorf("Decoded body does not match: got %v want %v", decoded, binary)
+	}
+}
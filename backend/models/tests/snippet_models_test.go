@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"errors"
+	"go/format"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+	"github.com/FedeBP/pumoide/backend/models"
+)
+
+func TestToSnippet(t *testing.T) {
+	env := &models.Environment{Variables: map[string]string{"baseUrl": "https://api.example.com", "token": "abc123"}}
+	req := models.Request{
+		Method:      models.MethodPost,
+		URL:         "{{baseUrl}}/pets",
+		QueryParams: map[string]string{"dry run": "true"},
+		Headers:     []models.Header{{Key: "X-Trace", Value: "{{trace}}"}},
+		BodyConfig:  &models.RequestBody{Mode: models.BodyJSON, Raw: `{"name": "O'Brien"}`},
+		Auth:        &models.Auth{Type: models.AuthBearer, Params: map[string]string{"token": "{{token}}"}},
+		Settings:    &models.RequestSettings{TimeoutMs: 1500},
+	}
+
+	expected := map[models.SnippetLanguage][]string{
+		models.SnippetCurl: {
+			`curl 'https://api.example.com/pets?dry+run=true'`,
+			`--header 'Authorization: Bearer abc123'`,
+		},
+		models.SnippetGo: {
+			`http.NewRequest("POST", "https://api.example.com/pets?dry+run=true", body)`,
+			"strings.NewReader(`{\"name\": \"O'Brien\"}`)",
+			`req.Header.Add("X-Trace", "{{trace}}")`,
+			`req.Header.Add("Content-Type", "application/json")`,
+			`req.Header.Add("Authorization", "Bearer abc123")`,
+			`Timeout: 1500 * time.Millisecond`,
+		},
+		models.SnippetPython: {
+			`url = "https://api.example.com/pets?dry+run=true"`,
+			`"Authorization": "Bearer abc123",`,
+			`data = "{\"name\": \"O'Brien\"}"`,
+			`response = requests.request("POST", url, headers=headers, data=data, timeout=1.5)`,
+		},
+		models.SnippetJavaScript: {
+			`await fetch("https://api.example.com/pets?dry+run=true", {`,
+			`method: "POST",`,
+			`"Content-Type": "application/json",`,
+			`body: "{\"name\": \"O'Brien\"}",`,
+			`signal: AbortSignal.timeout(1500),`,
+		},
+		models.SnippetNode: {
+			`import axios from "axios";`,
+			`method: "post",`,
+			`url: "https://api.example.com/pets?dry+run=true",`,
+			`data: "{\"name\": \"O'Brien\"}",`,
+			`timeout: 1500,`,
+		},
+		models.SnippetHTTPie: {
+			`http --follow --timeout=1.5 POST 'https://api.example.com/pets?dry+run=true'`,
+			`'X-Trace:{{trace}}'`,
+			`'Authorization:Bearer abc123'`,
+			`--raw '{"name": "O'\''Brien"}'`,
+		},
+	}
+
+	for _, language := range models.GetValidSnippetLanguages() {
+		snippet, err := req.ToSnippet(language, env)
+		if err != nil {
+			t.Errorf("%s: failed to render snippet: %v", language, err)
+			continue
+		}
+		for _, fragment := range expected[language] {
+			if !strings.Contains(snippet, fragment) {
+				t.Errorf("%s: missing %q in:\n%s", language, fragment, snippet)
+			}
+		}
+	}
+}
+
+func TestToSnippetBodies(t *testing.T) {
+	requests := []models.Request{
+		{
+			Method: models.MethodPost,
+			URL:    "https://example.com/login",
+			BodyConfig: &models.RequestBody{Mode: models.BodyURLEncoded, URLEncoded: []models.FormField{
+				{Key: "user", Value: "ana"},
+				{Key: "skip", Value: "me", Disabled: true},
+			}},
+			Auth:     &models.Auth{Type: models.AuthBasic, Params: map[string]string{"username": "ana", "password": "x"}},
+			Settings: &models.RequestSettings{SkipTLSVerify: true, FollowRedirects: new(bool)},
+		},
+		{
+			Method: models.MethodPut,
+			URL:    "https://example.com/upload",
+			BodyConfig: &models.RequestBody{Mode: models.BodyFormData, FormData: []models.FormField{
+				{Key: "note", Value: "hi"},
+				{Key: "file", Type: models.FormFieldFile, FilePath: "/tmp/me.png", ContentType: "image/png"},
+			}},
+			Auth: &models.Auth{Type: models.AuthDigest, Params: map[string]string{"username": "ana", "password": "x"}},
+		},
+		{
+			Method:     models.MethodPatch,
+			URL:        "https://example.com/blob",
+			BodyConfig: &models.RequestBody{Mode: models.BodyBinary, FilePath: "data.bin", ContentType: "application/octet-stream"},
+			Auth:       &models.Auth{Type: models.AuthAPIKey, Params: map[string]string{"key": "api_key", "value": "k", "in": "query"}},
+		},
+		{
+			Method: models.MethodGet,
+			URL:    "https://example.com/graphql",
+			BodyConfig: &models.RequestBody{Mode: models.BodyGraphQL, GraphQL: &models.GraphQLBody{
+				Query: "{ pets { name } }", Variables: `{"first": 2}`,
+			}},
+		},
+	}
+
+	for _, req := range requests {
+		// The Go snippet is a complete program
+		snippet, err := req.ToSnippet(models.SnippetGo, nil)
+		if err != nil {
+			t.Fatalf("Failed to render snippet: %v", err)
+		}
+		if _, err := format.Source([]byte(snippet)); err != nil {
+			t.Errorf("Invalid Go snippet: %v\n%s", err, snippet)
+		}
+		if strings.Contains(snippet, "skip") {
+			t.Errorf("Disabled fields should be left out:\n%s", snippet)
+		}
+	}
+
+	checks := []struct {
+		req      models.Request
+		language models.SnippetLanguage
+		fragment string
+	}{
+		{requests[0], models.SnippetGo, `req.SetBasicAuth("ana", "x")`},
+		{requests[0], models.SnippetGo, `return http.ErrUseLastResponse`},
+		{requests[0], models.SnippetPython, `data = [
+    ("user", "ana"),
+]`},
+		{requests[0], models.SnippetPython, `auth=("ana", "x"), verify=False, allow_redirects=False`},
+		{requests[0], models.SnippetJavaScript, `"Authorization": "Basic YW5hOng=",`},
+		{requests[0], models.SnippetJavaScript, `redirect: "manual",`},
+		{requests[0], models.SnippetNode, `httpsAgent: new https.Agent({ rejectUnauthorized: false }),`},
+		{requests[0], models.SnippetHTTPie, `http --verify=no --form --auth ana:x POST https://example.com/login`},
+		{requests[1], models.SnippetPython, `("file", ("me.png", open("/tmp/me.png", "rb"), "image/png")),`},
+		{requests[1], models.SnippetPython, `auth=HTTPDigestAuth("ana", "x")`},
+		{requests[1], models.SnippetJavaScript, `body.append("file", new Blob([await readFile("/tmp/me.png")], { type: "image/png" }), "me.png");`},
+		{requests[1], models.SnippetGo, `// Digest auth is not applied`},
+		{requests[1], models.SnippetHTTPie, `http --follow --multipart --auth-type digest --auth ana:x PUT https://example.com/upload`},
+		{requests[1], models.SnippetHTTPie, `'file@/tmp/me.png;type=image/png'`},
+		{requests[2], models.SnippetNode, `data: await readFile("data.bin"),`},
+		{requests[2], models.SnippetHTTPie, `PATCH 'https://example.com/blob?api_key=k'`},
+		{requests[2], models.SnippetHTTPie, `< data.bin`},
+		{requests[3], models.SnippetPython, `url = "https://example.com/graphql?query=%7B+pets+%7B+name+%7D+%7D&variables=%7B%22first%22%3A+2%7D"`},
+	}
+	for _, check := range checks {
+		snippet, err := check.req.ToSnippet(check.language, nil)
+		if err != nil {
+			t.Errorf("%s: failed to render snippet: %v", check.language, err)
+			continue
+		}
+		if !strings.Contains(snippet, check.fragment) {
+			t.Errorf("%s: missing %q in:\n%s", check.language, check.fragment, snippet)
+		}
+	}
+}
+
+func TestToSnippetErrors(t *testing.T) {
+	req := models.Request{Method: models.MethodGet, URL: "https://example.com"}
+	if _, err := req.ToSnippet("ruby", nil); err == nil {
+		t.Error("Expected an error for an unsupported language")
+	}
+
+	req.Type = models.RequestWebSocket
+	_, err := req.ToSnippet(models.SnippetGo, nil)
+	var appErr apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusBadRequest {
+		t.Errorf("Expected a bad request error for a WebSocket request, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FedeBP/pumoide/backend/apperrors"
+)
+
+type MessageDirection string
+
+const (
+	MessageSent     MessageDirection = "sent"
+	MessageReceived MessageDirection = "received"
+)
+
+type WebSocketMessageType string
+
+const (
+	WebSocketText   WebSocketMessageType = "text"
+	WebSocketBinary WebSocketMessageType = "binary"
+)
+
+type WebSocketSessionStatus string
+
+const (
+	WebSocketOpen   WebSocketSessionStatus = "open"
+	WebSocketClosed WebSocketSessionStatus = "closed"
+)
+
+// MaxWebSocketLogSize bounds the message log of a session, older messages are dropped first.
+const MaxWebSocketLogSize = 1000
+
+type WebSocketMessage struct {
+	Direction MessageDirection     `json:"direction"`
+	Type      WebSocketMessageType `json:"type"`
+	Data      string               `json:"data"`
+	Encoding  BodyEncoding         `json:"encoding"`
+	Timestamp time.Time            `json:"timestamp"`
+}
+
+type WebSocketSession struct {
+	ID          string                 `json:"id"`
+	URL         string                 `json:"url"`
+	Request     Request                `json:"request"`
+	Status      WebSocketSessionStatus `json:"status"`
+	OpenedAt    time.Time              `json:"openedAt"`
+	ClosedAt    *time.Time             `json:"closedAt,omitempty"`
+	CloseCode   int                    `json:"closeCode,omitempty"`
+	CloseReason string                 `json:"closeReason,omitempty"`
+	Messages    []WebSocketMessage     `json:"messages"`
+}
+
+// NewWebSocketMessage logs a frame, keeping text frames readable and base64-encoding anything else.
+func NewWebSocketMessage(direction MessageDirection, messageType WebSocketMessageType, data []byte) WebSocketMessage {
+	message := WebSocketMessage{
+		Direction: direction,
+		Type:      messageType,
+		Timestamp: time.Now(),
+	}
+
+	if messageType == WebSocketText && utf8.Valid(data) {
+		message.Data, message.Encoding = string(data), EncodingText
+	} else {
+		message.Data, message.Encoding = base64.StdEncoding.EncodeToString(data), EncodingBase64
+	}
+	return message
+}
+
+func (m *WebSocketMessage) Validate() error {
+	switch m.Type {
+	case WebSocketText, WebSocketBinary:
+	default:
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported message type: %s", m.Type), nil)
+	}
+
+	switch m.Encoding {
+	case "", EncodingText, EncodingBase64:
+	default:
+		return apperrors.NewAppError(http.StatusBadRequest, fmt.Sprintf("Unsupported message encoding: %s", m.Encoding), nil)
+	}
+
+	return nil
+}
+
+func (s *WebSocketSession) AddMessage(message WebSocketMessage) {
+	s.Messages = append(s.Messages, message)
+	if len(s.Messages) > MaxWebSocketLogSize {
+		s.Messages = s.Messages[len(s.Messages)-MaxWebSocketLogSize:]
+	}
+}
@@ -24,24 +24,103 @@ func (a *Pumoide) InitRoutes() {
 	limiter := rate.NewLimiter(a.config.RateLimit, a.config.RateLimitBurst)
 
 	a.router.Handle("/pumoide-api/collections", &RateLimitedHandler{
-		handler: &api.CollectionHandler{DefaultPath: a.config.DefaultCollectionsPath, Logger: a.logger},
+		handler: &api.CollectionHandler{
+			DefaultPath:      a.config.DefaultCollectionsPath,
+			EnvironmentsPath: a.config.DefaultEnvironmentsPath,
+			HistoryPath:      a.config.DefaultHistoryPath,
+			Logger:           a.logger,
+		},
 		limiter: limiter,
 	})
 
+	executions := api.NewExecutionRegistry()
+
+	requestHandler := &api.RequestHandler{
+		Client:           &http.Client{Timeout: a.config.ClientTimeout},
+		EnvironmentPath:  a.config.DefaultEnvironmentsPath,
+		HistoryPath:      a.config.DefaultHistoryPath,
+		HistoryRetention: a.config.HistoryRetention,
+		CertificatesPath: a.config.DefaultCertificatesPath,
+		SettingsPath:     a.config.DefaultSettingsPath,
+		CookiesPath:      a.config.DefaultCookiesPath,
+		Executions:       executions,
+		MaxPreviewSize:   a.config.StreamPreviewSize,
+		Logger:           a.logger,
+	}
+
 	a.router.Handle("/pumoide-api/execute", &RateLimitedHandler{
-		handler: &api.RequestHandler{
-			Client:          &http.Client{Timeout: a.config.ClientTimeout},
-			EnvironmentPath: a.config.DefaultEnvironmentsPath,
+		handler: requestHandler,
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/executions", &RateLimitedHandler{
+		handler: &api.ExecutionHandler{Registry: executions, Logger: a.logger},
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/history", &RateLimitedHandler{
+		handler: &api.HistoryHandler{DefaultPath: a.config.DefaultHistoryPath, Executor: requestHandler, Logger: a.logger},
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/websocket", &RateLimitedHandler{
+		handler: &api.WebSocketHandler{
+			Sessions:        api.NewWebSocketRegistry(),
+			Executor:        requestHandler,
+			CollectionsPath: a.config.DefaultCollectionsPath,
 			Logger:          a.logger,
 		},
 		limiter: limiter,
 	})
 
+	a.router.Handle("/pumoide-api/grpc", &RateLimitedHandler{
+		handler: &api.GRPCHandler{Executor: requestHandler, Logger: a.logger},
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/graphql", &RateLimitedHandler{
+		handler: &api.GraphQLHandler{DefaultPath: a.config.DefaultGraphQLPath, Executor: requestHandler, Logger: a.logger},
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/curl", &RateLimitedHandler{
+		handler: &api.CurlHandler{
+			CollectionsPath:  a.config.DefaultCollectionsPath,
+			EnvironmentsPath: a.config.DefaultEnvironmentsPath,
+			Logger:           a.logger,
+		},
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/snippets", &RateLimitedHandler{
+		handler: &api.SnippetHandler{
+			CollectionsPath:  a.config.DefaultCollectionsPath,
+			EnvironmentsPath: a.config.DefaultEnvironmentsPath,
+			Logger:           a.logger,
+		},
+		limiter: limiter,
+	})
+
 	a.router.Handle("/pumoide-api/environments", &RateLimitedHandler{
 		handler: &api.EnvironmentHandler{DefaultPath: a.config.DefaultEnvironmentsPath, Logger: a.logger},
 		limiter: limiter,
 	})
 
+	a.router.Handle("/pumoide-api/certificates", &RateLimitedHandler{
+		handler: &api.CertificateHandler{DefaultPath: a.config.DefaultCertificatesPath, Logger: a.logger},
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/proxy", &RateLimitedHandler{
+		handler: &api.ProxyHandler{DefaultPath: a.config.DefaultSettingsPath, Logger: a.logger},
+		limiter: limiter,
+	})
+
+	a.router.Handle("/pumoide-api/cookies", &RateLimitedHandler{
+		handler: &api.CookieHandler{DefaultPath: a.config.DefaultCookiesPath, Logger: a.logger},
+		limiter: limiter,
+	})
+
 	a.router.Handle("/pumoide-api/methods", &RateLimitedHandler{
 		handler: &api.MethodHandler{Logger: a.logger},
 		limiter: limiter,
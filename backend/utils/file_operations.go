@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 )
@@ -29,6 +30,26 @@ func GetDefaultEnvironmentsPath() string {
 	return filepath.Join(BaseDir, "environments")
 }
 
+func GetDefaultHistoryPath() string {
+	return filepath.Join(BaseDir, "history")
+}
+
+func GetDefaultCertificatesPath() string {
+	return filepath.Join(BaseDir, "certificates")
+}
+
+func GetDefaultCookiesPath() string {
+	return filepath.Join(BaseDir, "cookies")
+}
+
+func GetDefaultGraphQLSchemasPath() string {
+	return filepath.Join(BaseDir, "graphql")
+}
+
+func GetDefaultSettingsPath() string {
+	return filepath.Join(BaseDir, "settings")
+}
+
 func GetDefaultLogsPath() string {
 	return filepath.Join(BaseDir, "logs")
 }
@@ -40,3 +61,23 @@ func EnsureDir(path string) error {
 func GetCurrentStorageLocation() string {
 	return BaseDir
 }
+
+func CreateFile(path string) (*os.File, error) {
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+func SaveToFile(path string, r io.Reader) (int64, error) {
+	file, err := CreateFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return written, err
+}
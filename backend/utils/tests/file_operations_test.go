@@ -3,6 +3,7 @@ package tests
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/FedeBP/pumoide/backend/utils"
@@ -57,3 +58,24 @@ func TestGetCurrentStorageLocation(t *testing.T) {
 		t.Errorf("GetCurrentStorageLocation should end with 'pumoide'")
 	}
 }
+
+func TestSaveToFile(t *testing.T) {
+	tempDir := t.TempDir()
+	target := filepath.Join(tempDir, "nested", "body.bin")
+
+	written, err := utils.SaveToFile(target, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+	if written != int64(len("payload")) {
+		t.Errorf("SaveToFile reported %d bytes, want %d", written, len("payload"))
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read saved file: %v", err)
+	}
+	if string(data) != "payload" {
+		t.Errorf("Saved content does not match: got %s", data)
+	}
+}